@@ -0,0 +1,100 @@
+// Package main GDStudio/UNM上游API离线替身服务器
+//
+// 用于在无法访问外网时本地运行与调试，例如：
+//
+//	go run ./cmd/fake-upstream -addr :8090
+//	GDSTUDIO_BASE_URL=http://localhost:8090/api.php UNM_SERVER_BASE_URL=http://localhost:8090 go run ./cmd/music-api-proxy
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/fakeupstream"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+func main() {
+	var (
+		addr          = flag.String("addr", ":8090", "监听地址")
+		catalogPath   = flag.String("catalog", "", "JSON曲库文件，为空时使用内置曲库")
+		publicURL     = flag.String("public-url", "", "生成音频、图片链接使用的对外地址，默认取请求的Host")
+		types         = flag.String("types", "", "故障注入作用的接口类型（search/url/pic/lyric/health/asset），为空时作用于所有接口")
+		latency       = flag.Duration("latency", 0, "固定延迟")
+		jitter        = flag.Duration("jitter", 0, "附加随机延迟上限")
+		errorRate     = flag.Float64("error-rate", 0, "返回HTTP错误的概率（0-1）")
+		errorStatus   = flag.Int("error-status", http.StatusInternalServerError, "注入的HTTP状态码")
+		malformedRate = flag.Float64("malformed-rate", 0, "返回截断JSON的概率（0-1）")
+		gbk           = flag.Bool("gbk", false, "以GBK编码响应体")
+		omitCharset   = flag.Bool("omit-charset", false, "GBK响应不声明charset")
+		seed          = flag.Int64("seed", 0, "随机种子，非0时故障注入可复现")
+	)
+	flag.Parse()
+
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化日志系统失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	catalog := fakeupstream.DefaultCatalog()
+	if *catalogPath != "" {
+		if catalog, err = fakeupstream.LoadCatalog(*catalogPath); err != nil {
+			log.Fatalf("加载曲库失败: %v", err)
+		}
+	}
+
+	opts := []fakeupstream.Option{fakeupstream.WithLogger(log)}
+	if *publicURL != "" {
+		opts = append(opts, fakeupstream.WithPublicURL(*publicURL))
+	}
+	if *seed != 0 {
+		opts = append(opts, fakeupstream.WithSeed(*seed))
+	}
+	upstream := fakeupstream.NewServer(catalog, opts...)
+
+	faults := fakeupstream.Faults{
+		Latency:       *latency,
+		Jitter:        *jitter,
+		ErrorRate:     *errorRate,
+		ErrorStatus:   *errorStatus,
+		MalformedRate: *malformedRate,
+		GBK:           *gbk,
+		OmitCharset:   *omitCharset,
+	}
+	if err := upstream.SetFaults(*types, faults); err != nil {
+		log.Fatalf("故障注入配置无效: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: upstream,
+	}
+
+	go func() {
+		log.Info("替身上游服务器启动",
+			logger.String("addr", server.Addr),
+			logger.Int("tracks", len(catalog.Tracks)),
+		)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("替身上游服务器启动失败", logger.ErrorField("error", err))
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("替身上游服务器关闭失败", logger.ErrorField("error", err))
+	}
+	log.Sync()
+}
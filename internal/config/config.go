@@ -3,6 +3,8 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +18,7 @@ type Config struct {
 	Monitoring MonitoringConfig `json:"monitoring" yaml:"monitoring" mapstructure:"monitoring"`
 	Sources    SourcesConfig    `json:"sources" yaml:"sources" mapstructure:"sources"`
 	Cache      CacheConfig      `json:"cache" yaml:"cache" mapstructure:"cache"`
+	Stream     StreamConfig     `json:"stream" yaml:"stream" mapstructure:"stream"`
 	// 数据库和Redis配置已移除 - 项目不再使用数据库
 	HTTPClient HTTPClientConfig `json:"http_client" yaml:"http_client" mapstructure:"http_client"`
 	Middleware MiddlewareConfig `json:"middleware" yaml:"middleware" mapstructure:"middleware"`
@@ -44,11 +47,54 @@ type LoggingConfig struct {
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
-	Enabled         bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
-	Type            string        `json:"type" yaml:"type" mapstructure:"type"`
-	TTL             time.Duration `json:"ttl" yaml:"ttl" mapstructure:"ttl"`
-	MaxSize         string        `json:"max_size" yaml:"max_size" mapstructure:"max_size"`
-	CleanupInterval time.Duration `json:"cleanup_interval" yaml:"cleanup_interval" mapstructure:"cleanup_interval"`
+	Enabled         bool                `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	Type            string              `json:"type" yaml:"type" mapstructure:"type"`
+	TTL             time.Duration       `json:"ttl" yaml:"ttl" mapstructure:"ttl"`
+	MaxSize         string              `json:"max_size" yaml:"max_size" mapstructure:"max_size"`
+	CleanupInterval time.Duration       `json:"cleanup_interval" yaml:"cleanup_interval" mapstructure:"cleanup_interval"`
+	Redis           RedisConfig         `json:"redis" yaml:"redis" mapstructure:"redis"`          // type为redis时的服务器配置
+	Disk            DiskCacheConfig     `json:"disk" yaml:"disk" mapstructure:"disk"`             // type为disk或tiered时的磁盘缓存配置
+	Policies        CachePoliciesConfig `json:"policies" yaml:"policies" mapstructure:"policies"` // 各类查询的缓存策略
+}
+
+// RedisConfig Redis缓存配置，服务器不可用时自动回退到内存缓存
+type RedisConfig struct {
+	Addr                string        `json:"addr" yaml:"addr" mapstructure:"addr"`                                                    // 服务器地址，host:port
+	Username            string        `json:"username" yaml:"username" mapstructure:"username"`                                        // ACL用户名，为空时只使用密码认证
+	Password            string        `json:"password" yaml:"password" mapstructure:"password"`                                        // 密码
+	DB                  int           `json:"db" yaml:"db" mapstructure:"db"`                                                          // 数据库编号
+	KeyPrefix           string        `json:"key_prefix" yaml:"key_prefix" mapstructure:"key_prefix"`                                  // 键前缀，多个服务共用服务器时用于隔离
+	PoolSize            int           `json:"pool_size" yaml:"pool_size" mapstructure:"pool_size"`                                     // 最大连接数
+	DialTimeout         time.Duration `json:"dial_timeout" yaml:"dial_timeout" mapstructure:"dial_timeout"`                            // 建立连接超时
+	ReadTimeout         time.Duration `json:"read_timeout" yaml:"read_timeout" mapstructure:"read_timeout"`                            // 读取超时
+	WriteTimeout        time.Duration `json:"write_timeout" yaml:"write_timeout" mapstructure:"write_timeout"`                         // 写入超时
+	HealthCheckInterval time.Duration `json:"health_check_interval" yaml:"health_check_interval" mapstructure:"health_check_interval"` // 回退到内存缓存后探测服务器恢复的间隔
+}
+
+// DiskCacheConfig 磁盘缓存配置，数据以追加日志的形式持久化，重启后保留
+type DiskCacheConfig struct {
+	Directory          string        `json:"directory" yaml:"directory" mapstructure:"directory"`                               // 数据目录
+	MaxSize            string        `json:"max_size" yaml:"max_size" mapstructure:"max_size"`                                  // 有效数据的容量上限，如 "1GB"，超出后淘汰最先过期的条目
+	SyncWrites         bool          `json:"sync_writes" yaml:"sync_writes" mapstructure:"sync_writes"`                         // 每次写入后同步到磁盘，关闭时断电可能丢失最近的写入
+	CompactionInterval time.Duration `json:"compaction_interval" yaml:"compaction_interval" mapstructure:"compaction_interval"` // 清理过期条目并检查是否需要压缩日志的间隔
+	HotMaxItems        int           `json:"hot_max_items" yaml:"hot_max_items" mapstructure:"hot_max_items"`                   // tiered模式下内存热数据的最大条目数
+}
+
+// CachePoliciesConfig 各类查询的缓存策略
+type CachePoliciesConfig struct {
+	Match  CachePolicyConfig `json:"match" yaml:"match" mapstructure:"match"`    // /match 匹配结果
+	NCM    CachePolicyConfig `json:"ncm" yaml:"ncm" mapstructure:"ncm"`          // /ncm 网易云音乐结果
+	Other  CachePolicyConfig `json:"other" yaml:"other" mapstructure:"other"`    // /other 按歌名获取的结果
+	Search CachePolicyConfig `json:"search" yaml:"search" mapstructure:"search"` // 搜索结果
+	Info   CachePolicyConfig `json:"info" yaml:"info" mapstructure:"info"`       // 音乐信息
+	Lyric  CachePolicyConfig `json:"lyric" yaml:"lyric" mapstructure:"lyric"`    // 歌词
+}
+
+// CachePolicyConfig 单类查询的缓存策略
+type CachePolicyConfig struct {
+	TTL         time.Duration `json:"ttl" yaml:"ttl" mapstructure:"ttl"`                            // 新鲜期，期间直接返回缓存
+	StaleTTL    time.Duration `json:"stale_ttl" yaml:"stale_ttl" mapstructure:"stale_ttl"`          // 新鲜期过后继续返回旧值并在后台刷新的时长，0表示不使用旧值
+	NegativeTTL time.Duration `json:"negative_ttl" yaml:"negative_ttl" mapstructure:"negative_ttl"` // 所有音源都明确答复未找到时缓存该结果的时长，0表示不缓存
 }
 
 // ServerConfig 服务器配置
@@ -66,6 +112,59 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration `json:"idle_timeout" yaml:"idle_timeout" mapstructure:"idle_timeout"`
 }
 
+// StreamConfig 音频流式代理配置
+type StreamConfig struct {
+	Enabled         bool              `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                            // 启用 /api/v1/stream 并在匹配结果中填充proxy_url
+	PublicURL       string            `json:"public_url" yaml:"public_url" mapstructure:"public_url"`                   // 代理对外访问地址，为空时proxy_url为相对路径
+	UpstreamTimeout time.Duration     `json:"upstream_timeout" yaml:"upstream_timeout" mapstructure:"upstream_timeout"` // 等待上游响应头的超时时间
+	MaxRedirects    int               `json:"max_redirects" yaml:"max_redirects" mapstructure:"max_redirects"`          // 最多跟随的上游重定向次数
+	UserAgent       string            `json:"user_agent" yaml:"user_agent" mapstructure:"user_agent"`                   // 请求上游使用的User-Agent，为空时使用默认值
+	Signing         LinkSigningConfig `json:"signing" yaml:"signing" mapstructure:"signing"`                            // 代理链接签名
+	Cache           AudioCacheConfig  `json:"cache" yaml:"cache" mapstructure:"cache"`                                  // 音频磁盘缓存
+	Download        DownloadConfig    `json:"download" yaml:"download" mapstructure:"download"`                         // 带标签下载，依赖流式代理
+	Cover           CoverProxyConfig  `json:"cover" yaml:"cover" mapstructure:"cover"`                                  // 封面图片代理，与音频代理共用对外地址与链接签名
+}
+
+// DownloadConfig 带标签下载配置
+// 文件名模板为Go模板，可用变量：.Title .Artist .Album .ID .Source .Quality .Ext
+type DownloadConfig struct {
+	Enabled          bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                               // 启用 /api/v1/download 并在匹配结果中填充download_url
+	FilenameTemplate string        `json:"filename_template" yaml:"filename_template" mapstructure:"filename_template"` // Content-Disposition文件名模板
+	MetadataTimeout  time.Duration `json:"metadata_timeout" yaml:"metadata_timeout" mapstructure:"metadata_timeout"`    // 获取曲目信息、封面与歌词的超时时间，超时后不写入对应标签
+}
+
+// CoverProxyConfig 封面图片代理配置
+type CoverProxyConfig struct {
+	Enabled        bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                         // 启用 /api/v1/cover 并在专辑图结果中填充proxy_url
+	Directory      string        `json:"directory" yaml:"directory" mapstructure:"directory"`                   // 渲染结果缓存目录
+	MaxSize        string        `json:"max_size" yaml:"max_size" mapstructure:"max_size"`                      // 渲染结果缓存容量上限，如 "256MB"
+	MaxDimension   int           `json:"max_dimension" yaml:"max_dimension" mapstructure:"max_dimension"`       // 允许的最大边长（像素）
+	DefaultQuality int           `json:"default_quality" yaml:"default_quality" mapstructure:"default_quality"` // 默认JPEG质量（1-100）
+	FetchTimeout   time.Duration `json:"fetch_timeout" yaml:"fetch_timeout" mapstructure:"fetch_timeout"`       // 下载原图的超时时间
+}
+
+// AudioCacheConfig 音频磁盘缓存配置
+type AudioCacheConfig struct {
+	Enabled     bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`                // 启用后代理的音频写入磁盘缓存，后续请求直接由缓存提供
+	Directory   string        `json:"directory" yaml:"directory" mapstructure:"directory"`          // 缓存目录
+	MaxSize     string        `json:"max_size" yaml:"max_size" mapstructure:"max_size"`             // 缓存容量上限，如 "2GB"，超出后按最近访问时间淘汰
+	FillTimeout time.Duration `json:"fill_timeout" yaml:"fill_timeout" mapstructure:"fill_timeout"` // 单个文件下载的最长时间，超时后保留已下载部分
+}
+
+// LinkSigningConfig 代理链接签名配置
+type LinkSigningConfig struct {
+	Enabled   bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`          // 启用后代理链接携带HMAC签名与过期时间，未签名或签名无效的请求返回403
+	TTL       time.Duration `json:"ttl" yaml:"ttl" mapstructure:"ttl"`                      // 链接有效期
+	ActiveKey string        `json:"active_key" yaml:"active_key" mapstructure:"active_key"` // 签发新链接使用的密钥ID，为空时使用第一把密钥
+	Keys      []SigningKey  `json:"keys" yaml:"keys" mapstructure:"keys"`                   // 生效的密钥列表，轮换时保留旧密钥直至其签发的链接过期；为空时使用security.jwt_secret
+}
+
+// SigningKey 链接签名密钥
+type SigningKey struct {
+	ID     string `json:"id" yaml:"id" mapstructure:"id"`             // 密钥ID
+	Secret string `json:"secret" yaml:"secret" mapstructure:"secret"` // 密钥内容
+}
+
 // SecurityConfig 安全配置
 type SecurityConfig struct {
 	EnableAuth   bool     `json:"enable_auth" yaml:"enable_auth" mapstructure:"enable_auth"`
@@ -161,6 +260,23 @@ type SourcesConfig struct {
 	TestSources    []string `json:"test_sources" yaml:"test_sources" mapstructure:"test_sources"`
 	Timeout       time.Duration `json:"timeout" yaml:"timeout" mapstructure:"timeout"`
 	RetryCount    int      `json:"retry_count" yaml:"retry_count" mapstructure:"retry_count"`
+
+	// 音乐平台配置
+	Platforms       []string `json:"platforms" yaml:"platforms" mapstructure:"platforms"`                      // 允许请求的平台白名单
+	DefaultPlatform string   `json:"default_platform" yaml:"default_platform" mapstructure:"default_platform"` // 未指定平台时使用的默认平台
+
+	// 匹配策略配置
+	MatchStrategy string        `json:"match_strategy" yaml:"match_strategy" mapstructure:"match_strategy"` // sequential, parallel, hedged
+	HedgeDelay    time.Duration `json:"hedge_delay" yaml:"hedge_delay" mapstructure:"hedge_delay"`          // 对冲策略下启动下一个音源前的等待时间
+
+	// 音源熔断器配置
+	CircuitBreaker CircuitBreakerConfig `json:"circuit_breaker" yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
+
+	// 声明式HTTP音源配置
+	HTTPSources []HTTPSourceConfig `json:"http_sources" yaml:"http_sources" mapstructure:"http_sources"`
+
+	// 本地曲库音源配置
+	Local LocalSourceConfig `json:"local" yaml:"local" mapstructure:"local"`
 }
 
 // UNMServerConfig UnblockNeteaseMusic服务器配置
@@ -199,6 +315,65 @@ type SearchFallbackConfig struct {
 	MaxKeywords int      `json:"max_keywords" yaml:"max_keywords" mapstructure:"max_keywords"`
 }
 
+// CircuitBreakerConfig 音源熔断器配置
+type CircuitBreakerConfig struct {
+	Enabled              bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	FailureThreshold     int           `json:"failure_threshold" yaml:"failure_threshold" mapstructure:"failure_threshold"`                // 连续失败次数阈值
+	FailureRateThreshold float64       `json:"failure_rate_threshold" yaml:"failure_rate_threshold" mapstructure:"failure_rate_threshold"` // 滑动窗口内失败率阈值（0-1）
+	WindowSize           int           `json:"window_size" yaml:"window_size" mapstructure:"window_size"`                                  // 统计失败率的滑动窗口大小
+	MinRequests          int           `json:"min_requests" yaml:"min_requests" mapstructure:"min_requests"`                               // 计算失败率所需的最少请求数
+	CoolDown             time.Duration `json:"cool_down" yaml:"cool_down" mapstructure:"cool_down"`                                        // 打开状态持续时间，之后进入半开状态
+	HalfOpenProbes       int           `json:"half_open_probes" yaml:"half_open_probes" mapstructure:"half_open_probes"`                   // 半开状态下允许的探测请求数
+}
+
+// LocalSourceConfig 本地曲库音源配置
+type LocalSourceConfig struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	Directory    string        `json:"directory" yaml:"directory" mapstructure:"directory"`             // 曲库根目录
+	PublicURL    string        `json:"public_url" yaml:"public_url" mapstructure:"public_url"`          // 代理对外访问地址，用于生成播放链接
+	Extensions   []string      `json:"extensions" yaml:"extensions" mapstructure:"extensions"`          // 索引的文件扩展名
+	Priority     int           `json:"priority" yaml:"priority" mapstructure:"priority"`                // 音源优先级
+	Watch        bool          `json:"watch" yaml:"watch" mapstructure:"watch"`                         // 是否监听目录变化自动重建索引
+	ReindexDelay time.Duration `json:"reindex_delay" yaml:"reindex_delay" mapstructure:"reindex_delay"` // 目录变化后延迟重建索引的时间
+}
+
+// HTTPSourceConfig 声明式HTTP音源配置
+// 各操作的URL、请求头、请求体均为Go模板，可用变量：
+// .BaseURL .APIKey .UserAgent .Platform .Keyword .ID .Quality .Size .Limit
+type HTTPSourceConfig struct {
+	Name        string                               `json:"name" yaml:"name" mapstructure:"name"`
+	Enabled     bool                                 `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	Priority    int                                  `json:"priority" yaml:"priority" mapstructure:"priority"`
+	BaseURL     string                               `json:"base_url" yaml:"base_url" mapstructure:"base_url"`
+	APIKey      string                               `json:"api_key" yaml:"api_key" mapstructure:"api_key"`
+	UserAgent   string                               `json:"user_agent" yaml:"user_agent" mapstructure:"user_agent"`
+	Timeout     time.Duration                        `json:"timeout" yaml:"timeout" mapstructure:"timeout"`
+	Headers     map[string]string                    `json:"headers" yaml:"headers" mapstructure:"headers"`                // 所有请求共用的请求头模板
+	Qualities   []string                             `json:"qualities" yaml:"qualities" mapstructure:"qualities"`          // 支持的音质列表
+	FixEncoding bool                                 `json:"fix_encoding" yaml:"fix_encoding" mapstructure:"fix_encoding"` // 是否修复文本字段的中文编码
+	Operations  map[string]HTTPSourceOperationConfig `json:"operations" yaml:"operations" mapstructure:"operations"`       // search, url, info, lyric, pic, health
+}
+
+// HTTPSourceOperationConfig 声明式HTTP音源的单个操作配置
+// Fields可映射的字段：search/info为id name artist album duration pic_url，
+// url为url quality size format，lyric为lyric tlyric，pic为url
+type HTTPSourceOperationConfig struct {
+	Method  string                  `json:"method" yaml:"method" mapstructure:"method"`
+	URL     string                  `json:"url" yaml:"url" mapstructure:"url"`
+	Headers map[string]string       `json:"headers" yaml:"headers" mapstructure:"headers"`
+	Body    string                  `json:"body" yaml:"body" mapstructure:"body"`
+	Results string                  `json:"results" yaml:"results" mapstructure:"results"` // 结果列表的JSON路径，仅search、info使用
+	Fields  map[string]string       `json:"fields" yaml:"fields" mapstructure:"fields"`    // 目标字段到JSON路径的映射
+	Success HTTPSourceSuccessConfig `json:"success" yaml:"success" mapstructure:"success"`
+}
+
+// HTTPSourceSuccessConfig 声明式HTTP音源的成功判定配置
+type HTTPSourceSuccessConfig struct {
+	Status []int  `json:"status" yaml:"status" mapstructure:"status"` // 允许的HTTP状态码，默认200
+	Path   string `json:"path" yaml:"path" mapstructure:"path"`       // 需要校验的JSON路径
+	Equals string `json:"equals" yaml:"equals" mapstructure:"equals"` // 期望值，为空时要求该路径存在且非空
+}
+
 // 数据库和Redis配置结构已移除 - 项目不再使用数据库
 
 // HTTPClientConfig HTTP客户端配置
@@ -280,6 +455,30 @@ type EndpointConfig struct {
 	Sources    []string `json:"sources" yaml:"sources" mapstructure:"sources"`
 }
 
+// ParseByteSize 解析带单位的容量，如 "512KB"、"10MB"、"2GB"，无单位时按字节计算
+func ParseByteSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("无效的容量: %s", size)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
 // IsProduction 判断是否为生产环境
 func (c *AppConfig) IsProduction() bool {
 	return c.Mode == "production"
@@ -231,6 +231,18 @@ func (l *Loader) loadFromEnv(config *Config) {
 	if cacheTTL := os.Getenv("CACHE_TTL"); cacheTTL != "" {
 		l.viper.Set("cache.ttl", cacheTTL)
 	}
+	if cacheType := os.Getenv("CACHE_TYPE"); cacheType != "" {
+		config.Cache.Type = cacheType
+	}
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		config.Cache.Redis.Addr = redisAddr
+	}
+	if redisPassword := os.Getenv("REDIS_PASSWORD"); redisPassword != "" {
+		config.Cache.Redis.Password = redisPassword
+	}
+	if cacheDir := os.Getenv("CACHE_DIR"); cacheDir != "" {
+		config.Cache.Disk.Directory = cacheDir
+	}
 	
 	// 监控配置
 	if metricsEnabled := os.Getenv("METRICS_ENABLED"); metricsEnabled != "" {
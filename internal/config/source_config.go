@@ -84,6 +84,46 @@ func (scm *SourceConfigManager) ParseSources(serverParam string) []string {
 	return sources
 }
 
+// GetDefaultPlatform 获取默认音乐平台
+func (scm *SourceConfigManager) GetDefaultPlatform() string {
+	if scm.config.Sources.DefaultPlatform != "" {
+		return scm.config.Sources.DefaultPlatform
+	}
+	return "netease"
+}
+
+// GetAllowedPlatforms 获取允许请求的音乐平台列表
+func (scm *SourceConfigManager) GetAllowedPlatforms() []string {
+	if len(scm.config.Sources.Platforms) > 0 {
+		return scm.config.Sources.Platforms
+	}
+	return []string{scm.GetDefaultPlatform()}
+}
+
+// IsAllowedPlatform 检查音乐平台是否在白名单中
+func (scm *SourceConfigManager) IsAllowedPlatform(platform string) bool {
+	for _, allowed := range scm.GetAllowedPlatforms() {
+		if allowed == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePlatform 解析平台参数，为空时返回默认平台
+func (scm *SourceConfigManager) ResolvePlatform(platform string) (string, error) {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform == "" {
+		return scm.GetDefaultPlatform(), nil
+	}
+
+	if !scm.IsAllowedPlatform(platform) {
+		return "", fmt.Errorf("不支持的平台参数: %s，允许的平台: %s", platform, strings.Join(scm.GetAllowedPlatforms(), ", "))
+	}
+
+	return platform, nil
+}
+
 // GetSourceConfig 获取指定音源的配置
 func (scm *SourceConfigManager) GetSourceConfig(sourceName string) (interface{}, error) {
 	switch sourceName {
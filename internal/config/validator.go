@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
@@ -65,6 +66,10 @@ func (v *Validator) Validate(config *Config) error {
 		return fmt.Errorf("缓存配置验证失败: %w", err)
 	}
 
+	if err := v.validateStream(&config.Stream, &config.Security); err != nil {
+		return fmt.Errorf("流式代理配置验证失败: %w", err)
+	}
+
 	if err := v.validatePlugins(&config.Plugins); err != nil {
 		return fmt.Errorf("插件配置验证失败: %w", err)
 	}
@@ -270,12 +275,277 @@ func (v *Validator) validateSources(config *SourcesConfig) error {
 	if config.RetryCount > 10 {
 		return fmt.Errorf("重试次数不能超过10次，当前值: %d", config.RetryCount)
 	}
+
+	// 验证音乐平台
+	if config.DefaultPlatform == "" {
+		config.DefaultPlatform = "netease"
+	}
+	if len(config.Platforms) == 0 {
+		config.Platforms = []string{config.DefaultPlatform}
+	}
+	if !contains(config.Platforms, config.DefaultPlatform) {
+		return fmt.Errorf("默认平台 %s 不在平台白名单中: %s", config.DefaultPlatform, strings.Join(config.Platforms, ", "))
+	}
+
+	// 验证匹配策略
+	if config.MatchStrategy == "" {
+		config.MatchStrategy = "sequential"
+	}
+	validStrategies := []string{"sequential", "parallel", "hedged"}
+	if !contains(validStrategies, config.MatchStrategy) {
+		return fmt.Errorf("无效的匹配策略: %s，支持的策略: %s", config.MatchStrategy, strings.Join(validStrategies, ", "))
+	}
+	if config.HedgeDelay <= 0 {
+		config.HedgeDelay = 500 * time.Millisecond
+	}
+
+	// 验证音源熔断器
+	breaker := &config.CircuitBreaker
+	if breaker.FailureThreshold <= 0 {
+		breaker.FailureThreshold = 5
+	}
+	if breaker.FailureRateThreshold <= 0 {
+		breaker.FailureRateThreshold = 0.5
+	}
+	if breaker.FailureRateThreshold > 1 {
+		return fmt.Errorf("熔断器失败率阈值必须在0-1之间，当前值: %.2f", breaker.FailureRateThreshold)
+	}
+	if breaker.WindowSize <= 0 {
+		breaker.WindowSize = 20
+	}
+	if breaker.MinRequests <= 0 {
+		breaker.MinRequests = 10
+	}
+	if breaker.MinRequests > breaker.WindowSize {
+		return fmt.Errorf("熔断器最少请求数(%d)不能大于滑动窗口大小(%d)", breaker.MinRequests, breaker.WindowSize)
+	}
+	if breaker.CoolDown <= 0 {
+		breaker.CoolDown = 30 * time.Second
+	}
+	if breaker.HalfOpenProbes <= 0 {
+		breaker.HalfOpenProbes = 1
+	}
+
+	// 验证声明式HTTP音源
+	if err := v.validateHTTPSources(config.HTTPSources); err != nil {
+		return err
+	}
+
+	// 验证本地曲库音源
+	if config.Local.Enabled {
+		if config.Local.Directory == "" {
+			return fmt.Errorf("本地曲库音源已启用但未配置目录")
+		}
+		if config.Local.PublicURL != "" {
+			if _, err := url.Parse(config.Local.PublicURL); err != nil {
+				return fmt.Errorf("本地曲库对外访问地址格式无效: %s", config.Local.PublicURL)
+			}
+		}
+	}
+	if len(config.Local.Extensions) == 0 {
+		config.Local.Extensions = []string{".mp3", ".flac", ".ogg", ".oga", ".opus", ".m4a"}
+	}
+	if config.Local.Priority <= 0 {
+		config.Local.Priority = 10 // 本地曲目ID与平台ID不同，默认排在第三方音源之后
+	}
+	if config.Local.ReindexDelay <= 0 {
+		config.Local.ReindexDelay = 2 * time.Second
+	}
 	
 	return nil
 }
 
+// validateHTTPSources 验证声明式HTTP音源配置
+func (v *Validator) validateHTTPSources(sources []HTTPSourceConfig) error {
+	names := make(map[string]bool, len(sources))
+	for i := range sources {
+		source := &sources[i]
+		if source.Name == "" {
+			return fmt.Errorf("第%d个HTTP音源缺少名称", i+1)
+		}
+		if names[source.Name] {
+			return fmt.Errorf("HTTP音源名称重复: %s", source.Name)
+		}
+		names[source.Name] = true
+
+		if _, ok := source.Operations["url"]; !ok {
+			return fmt.Errorf("HTTP音源 %s 缺少url操作配置", source.Name)
+		}
+
+		for name, op := range source.Operations {
+			switch name {
+			case "search", "url", "info", "lyric", "pic", "health":
+			default:
+				return fmt.Errorf("HTTP音源 %s 包含不支持的操作: %s", source.Name, name)
+			}
+			if op.URL == "" {
+				return fmt.Errorf("HTTP音源 %s 的 %s 操作缺少url", source.Name, name)
+			}
+			op.Method = strings.ToUpper(op.Method)
+			if op.Method == "" {
+				op.Method = "GET"
+			}
+			if op.Method != "GET" && op.Method != "POST" {
+				return fmt.Errorf("HTTP音源 %s 的 %s 操作请求方法无效: %s", source.Name, name, op.Method)
+			}
+			source.Operations[name] = op
+		}
+	}
+	return nil
+}
+
 // 数据库和Redis验证函数已移除 - 项目不再使用数据库
 
+// validateStream 验证流式代理配置
+func (v *Validator) validateStream(config *StreamConfig, security *SecurityConfig) error {
+	if config.PublicURL != "" {
+		parsed, err := url.Parse(config.PublicURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("流式代理对外访问地址格式无效: %s", config.PublicURL)
+		}
+	}
+	if config.UpstreamTimeout <= 0 {
+		config.UpstreamTimeout = 15 * time.Second
+	}
+	if config.MaxRedirects < 0 {
+		return fmt.Errorf("上游重定向次数不能为负数，当前值: %d", config.MaxRedirects)
+	}
+	if config.MaxRedirects == 0 {
+		config.MaxRedirects = 5
+	}
+	if err := v.validateAudioCache(&config.Cache); err != nil {
+		return err
+	}
+	if err := v.validateDownload(config); err != nil {
+		return err
+	}
+	if err := v.validateCoverProxy(&config.Cover); err != nil {
+		return err
+	}
+	return v.validateLinkSigning(&config.Signing, security)
+}
+
+// validateDownload 验证带标签下载配置
+func (v *Validator) validateDownload(config *StreamConfig) error {
+	download := &config.Download
+	if !download.Enabled {
+		return nil
+	}
+	if !config.Enabled {
+		return fmt.Errorf("启用下载接口需要同时启用流式代理")
+	}
+	if download.FilenameTemplate == "" {
+		download.FilenameTemplate = "{{.Artist}} - {{.Title}}.{{.Ext}}"
+	}
+	if _, err := template.New("filename").Parse(download.FilenameTemplate); err != nil {
+		return fmt.Errorf("下载文件名模板无效: %w", err)
+	}
+	if download.MetadataTimeout <= 0 {
+		download.MetadataTimeout = 10 * time.Second
+	}
+	return nil
+}
+
+// validateCoverProxy 验证封面图片代理配置
+func (v *Validator) validateCoverProxy(config *CoverProxyConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Directory == "" {
+		config.Directory = "./data/cover-cache"
+	}
+	if config.MaxSize == "" {
+		config.MaxSize = "256MB"
+	}
+	size, err := ParseByteSize(config.MaxSize)
+	if err != nil {
+		return fmt.Errorf("封面缓存容量格式无效: %w", err)
+	}
+	if size <= 0 {
+		return fmt.Errorf("封面缓存容量必须大于0")
+	}
+	if config.MaxDimension == 0 {
+		config.MaxDimension = 1200
+	}
+	if config.MaxDimension < 16 || config.MaxDimension > 4096 {
+		return fmt.Errorf("封面最大边长必须在16-4096之间，当前值: %d", config.MaxDimension)
+	}
+	if config.DefaultQuality == 0 {
+		config.DefaultQuality = 85
+	}
+	if config.DefaultQuality < 1 || config.DefaultQuality > 100 {
+		return fmt.Errorf("封面默认质量必须在1-100之间，当前值: %d", config.DefaultQuality)
+	}
+	if config.FetchTimeout <= 0 {
+		config.FetchTimeout = 10 * time.Second
+	}
+	return nil
+}
+
+// validateAudioCache 验证音频磁盘缓存配置
+func (v *Validator) validateAudioCache(config *AudioCacheConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.Directory == "" {
+		config.Directory = "./data/audio-cache"
+	}
+	if config.MaxSize == "" {
+		config.MaxSize = "1GB"
+	}
+	size, err := ParseByteSize(config.MaxSize)
+	if err != nil {
+		return fmt.Errorf("音频缓存容量格式无效: %w", err)
+	}
+	if size <= 0 {
+		return fmt.Errorf("音频缓存容量必须大于0")
+	}
+	if config.FillTimeout <= 0 {
+		config.FillTimeout = 10 * time.Minute
+	}
+	return nil
+}
+
+// validateLinkSigning 验证代理链接签名配置
+func (v *Validator) validateLinkSigning(config *LinkSigningConfig, security *SecurityConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.TTL <= 0 {
+		config.TTL = 6 * time.Hour
+	}
+
+	// 未配置独立密钥时复用JWT密钥
+	if len(config.Keys) == 0 {
+		if security.JWTSecret == "" {
+			return fmt.Errorf("启用链接签名时必须配置签名密钥或security.jwt_secret")
+		}
+		config.Keys = []SigningKey{{ID: "jwt", Secret: security.JWTSecret}}
+	}
+
+	seen := make(map[string]bool, len(config.Keys))
+	for _, key := range config.Keys {
+		if key.ID == "" {
+			return fmt.Errorf("签名密钥ID不能为空")
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("签名密钥ID重复: %s", key.ID)
+		}
+		seen[key.ID] = true
+		if len(key.Secret) < 32 {
+			return fmt.Errorf("签名密钥 %s 长度不能少于32个字符", key.ID)
+		}
+	}
+
+	if config.ActiveKey == "" {
+		config.ActiveKey = config.Keys[0].ID
+	}
+	if !seen[config.ActiveKey] {
+		return fmt.Errorf("当前签名密钥不存在: %s", config.ActiveKey)
+	}
+	return nil
+}
+
 // validateApp 验证应用配置
 func (v *Validator) validateApp(app *AppConfig) error {
 	if app.Name == "" {
@@ -322,11 +592,23 @@ func (v *Validator) validateLogging(logging *LoggingConfig) error {
 // validateCache 验证缓存配置
 func (v *Validator) validateCache(cache *CacheConfig) error {
 	if cache.Enabled {
-		validTypes := []string{"memory"}  // 只支持内存缓存
+		validTypes := []string{"memory", "redis", "disk", "tiered"}
 		if !contains(validTypes, cache.Type) {
 			return fmt.Errorf("无效的缓存类型: %s，支持的类型: %s", cache.Type, strings.Join(validTypes, ", "))
 		}
 
+		if cache.Type == "redis" {
+			if err := v.validateRedis(&cache.Redis); err != nil {
+				return err
+			}
+		}
+
+		if cache.Type == "disk" || cache.Type == "tiered" {
+			if err := v.validateDiskCache(&cache.Disk); err != nil {
+				return err
+			}
+		}
+
 		if cache.TTL <= 0 {
 			return fmt.Errorf("缓存TTL必须大于0")
 		}
@@ -334,8 +616,104 @@ func (v *Validator) validateCache(cache *CacheConfig) error {
 		if cache.MaxSize == "" {
 			return fmt.Errorf("缓存最大大小不能为空")
 		}
+		size, err := ParseByteSize(cache.MaxSize)
+		if err != nil {
+			return fmt.Errorf("缓存最大大小格式无效: %w", err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("缓存最大大小必须大于0")
+		}
+
+		if cache.CleanupInterval <= 0 {
+			cache.CleanupInterval = time.Minute
+		}
+
+		if err := v.validateCachePolicies(&cache.Policies); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCachePolicies 验证各类查询的缓存策略，未配置新鲜期时使用默认值
+func (v *Validator) validateCachePolicies(policies *CachePoliciesConfig) error {
+	defaults := []struct {
+		name   string
+		policy *CachePolicyConfig
+		ttl    time.Duration
+	}{
+		{"match", &policies.Match, 5 * time.Minute},
+		{"ncm", &policies.NCM, 5 * time.Minute},
+		{"other", &policies.Other, 5 * time.Minute},
+		{"search", &policies.Search, 10 * time.Minute},
+		{"info", &policies.Info, 30 * time.Minute},
+		{"lyric", &policies.Lyric, 30 * time.Minute},
+	}
+	for _, d := range defaults {
+		if d.policy.TTL < 0 || d.policy.StaleTTL < 0 || d.policy.NegativeTTL < 0 {
+			return fmt.Errorf("缓存策略 %s 的时长不能为负数", d.name)
+		}
+		if d.policy.TTL == 0 {
+			d.policy.TTL = d.ttl
+		}
+	}
+	return nil
+}
+
+// validateRedis 验证Redis缓存配置并补全默认值
+func (v *Validator) validateRedis(redis *RedisConfig) error {
+	if redis.Addr == "" {
+		return fmt.Errorf("Redis缓存地址不能为空")
+	}
+	if redis.DB < 0 {
+		return fmt.Errorf("Redis数据库编号不能为负数")
+	}
+	if redis.KeyPrefix == "" {
+		redis.KeyPrefix = "music-api-proxy:"
+	}
+	if redis.PoolSize <= 0 {
+		redis.PoolSize = 10
+	}
+	if redis.DialTimeout <= 0 {
+		redis.DialTimeout = 5 * time.Second
 	}
+	if redis.ReadTimeout <= 0 {
+		redis.ReadTimeout = 3 * time.Second
+	}
+	if redis.WriteTimeout <= 0 {
+		redis.WriteTimeout = 3 * time.Second
+	}
+	if redis.HealthCheckInterval <= 0 {
+		redis.HealthCheckInterval = 10 * time.Second
+	}
+	return nil
+}
 
+// validateDiskCache 验证磁盘缓存配置并补全默认值
+func (v *Validator) validateDiskCache(disk *DiskCacheConfig) error {
+	if disk.Directory == "" {
+		disk.Directory = "./data/cache"
+	}
+	if disk.MaxSize == "" {
+		disk.MaxSize = "1GB"
+	}
+	size, err := ParseByteSize(disk.MaxSize)
+	if err != nil {
+		return fmt.Errorf("磁盘缓存容量格式无效: %w", err)
+	}
+	if size <= 0 {
+		return fmt.Errorf("磁盘缓存容量必须大于0")
+	}
+	if disk.CompactionInterval <= 0 {
+		disk.CompactionInterval = 10 * time.Minute
+	}
+	if disk.HotMaxItems < 0 {
+		return fmt.Errorf("热数据最大条目数不能为负数")
+	}
+	if disk.HotMaxItems == 0 {
+		disk.HotMaxItems = 10000
+	}
 	return nil
 }
 
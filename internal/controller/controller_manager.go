@@ -15,10 +15,13 @@ import (
 // ControllerManager 控制器管理器 - 生产环境安全版本
 type ControllerManager struct {
 	// 控制器实例
-	MusicController  *MusicController
-	SystemController *SystemController
-	ConfigController *ConfigController
-	HealthController *HealthController
+	MusicController    *MusicController
+	SystemController   *SystemController
+	ConfigController   *ConfigController
+	HealthController   *HealthController
+	StreamController   *StreamController
+	DownloadController *DownloadController
+	CoverController    *CoverController
 
 	// 服务管理器
 	ServiceManager *service.ServiceManager
@@ -122,6 +125,7 @@ func (cm *ControllerManager) Initialize() error {
 	// 创建音乐控制器
 	cm.MusicController = NewMusicController(
 		cm.ServiceManager.GetMusicService(),
+		cm.ServiceManager.GetStreamLinker(),
 		cm.Logger,
 	)
 	
@@ -137,6 +141,21 @@ func (cm *ControllerManager) Initialize() error {
 		cm.Logger,
 	)
 	
+	// 创建流式代理控制器（仅在启用流式代理时）
+	if streamService := cm.ServiceManager.GetStreamService(); streamService != nil {
+		cm.StreamController = NewStreamController(streamService, cm.ServiceManager.GetStreamLinker(), cm.Logger)
+	}
+
+	// 创建下载控制器（仅在启用下载接口时）
+	if downloadService := cm.ServiceManager.GetDownloadService(); downloadService != nil {
+		cm.DownloadController = NewDownloadController(downloadService, cm.ServiceManager.GetStreamLinker(), cm.Logger)
+	}
+
+	// 创建封面代理控制器（仅在启用封面代理时）
+	if coverService := cm.ServiceManager.GetCoverService(); coverService != nil {
+		cm.CoverController = NewCoverController(coverService, cm.ServiceManager.GetStreamLinker(), cm.Logger)
+	}
+	
 	// 创建健康检查控制器
 	cm.HealthController = NewHealthController()
 	
@@ -164,6 +183,31 @@ func (cm *ControllerManager) RegisterRoutes(router *gin.Engine) {
 		cm.Logger.Debug("音乐控制器路由注册完成")
 	}
 
+	// 注册流式代理路由（公开API）
+	if cm.StreamController != nil {
+		cm.StreamController.RegisterRoutes(v1)
+
+		// 音频缓存管理路由需要管理员密钥
+		streamAdminGroup := v1.Group("")
+		if cm.securityEnabled {
+			streamAdminGroup.Use(middleware.AdminAuth(cm.authConfig, cm.rateLimiter, cm.Logger))
+		}
+		cm.StreamController.RegisterAdminRoutes(streamAdminGroup)
+		cm.Logger.Debug("流式代理控制器路由注册完成")
+	}
+
+	// 注册下载路由（公开API）
+	if cm.DownloadController != nil {
+		cm.DownloadController.RegisterRoutes(v1)
+		cm.Logger.Debug("下载控制器路由注册完成")
+	}
+
+	// 注册封面代理路由（公开API）
+	if cm.CoverController != nil {
+		cm.CoverController.RegisterRoutes(v1)
+		cm.Logger.Debug("封面代理控制器路由注册完成")
+	}
+
 	// 注册系统相关路由（需要API密钥）
 	if cm.SystemController != nil {
 		systemGroup := v1.Group("/system")
@@ -173,6 +217,14 @@ func (cm *ControllerManager) RegisterRoutes(router *gin.Engine) {
 			cm.Logger.Debug("为系统API应用API密钥认证")
 		}
 		cm.SystemController.RegisterRoutes(v1) // 保持原有路径结构
+
+		// 音源熔断器与缓存条目管理路由需要管理员密钥
+		adminGroup := v1.Group("")
+		if cm.securityEnabled {
+			adminGroup.Use(middleware.AdminAuth(cm.authConfig, cm.rateLimiter, cm.Logger))
+			cm.Logger.Debug("为熔断器与缓存管理API应用管理员认证")
+		}
+		cm.SystemController.RegisterAdminRoutes(adminGroup)
 		cm.Logger.Debug("系统控制器路由注册完成")
 	}
 
@@ -227,13 +279,25 @@ func (cm *ControllerManager) registerRootRoutes(router *gin.Engine) {
 	
 	// API v1 信息 - 生产环境安全版本
 	router.GET("/api/v1", func(ctx *gin.Context) {
+		musicEndpoints := map[string]string{
+			"search": "GET /api/v1/search",
+			"info":   "GET /api/v1/info",
+			"picture": "GET /api/v1/picture",
+			"lyric":  "GET /api/v1/lyric",
+			"local_file":  "GET /api/v1/local/:id/file",
+			"local_cover": "GET /api/v1/local/:id/cover",
+		}
+		if cm.StreamController != nil {
+			musicEndpoints["stream"] = "GET /api/v1/stream"
+		}
+		if cm.DownloadController != nil {
+			musicEndpoints["download"] = "GET /api/v1/download"
+		}
+		if cm.CoverController != nil {
+			musicEndpoints["cover"] = "GET /api/v1/cover"
+		}
 		endpoints := map[string]interface{}{
-			"music": map[string]string{
-				"search": "GET /api/v1/search",
-				"info":   "GET /api/v1/info",
-				"picture": "GET /api/v1/picture",
-				"lyric":  "GET /api/v1/lyric",
-			},
+			"music": musicEndpoints,
 		}
 
 		// 根据安全配置决定是否显示敏感端点
@@ -246,13 +310,17 @@ func (cm *ControllerManager) registerRootRoutes(router *gin.Engine) {
 			// 配置端点需要认证，不在公开API列表中显示
 		} else {
 			// 开发环境显示所有端点
-			endpoints["system"] = map[string]string{
+			systemEndpoints := map[string]string{
 				"info":    "GET /api/v1/system/info",
 				"health":  "GET /api/v1/system/health",
 				"metrics": "GET /api/v1/system/metrics",
 				"sources": "GET /api/v1/system/sources",
 				"cache":   "GET /api/v1/system/cache/stats",
 			}
+			if cm.StreamController != nil {
+				systemEndpoints["audio_cache"] = "GET /api/v1/stream/cache"
+			}
+			endpoints["system"] = systemEndpoints
 			endpoints["config"] = map[string]string{
 				"get":      "GET /api/v1/config",
 				"update":   "PUT /api/v1/config",
@@ -299,6 +367,21 @@ func (cm *ControllerManager) GetHealthController() *HealthController {
 	return cm.HealthController
 }
 
+// GetStreamController 获取流式代理控制器，未启用流式代理时返回nil
+func (cm *ControllerManager) GetStreamController() *StreamController {
+	return cm.StreamController
+}
+
+// GetDownloadController 获取下载控制器，未启用下载接口时返回nil
+func (cm *ControllerManager) GetDownloadController() *DownloadController {
+	return cm.DownloadController
+}
+
+// GetCoverController 获取封面代理控制器，未启用封面代理时返回nil
+func (cm *ControllerManager) GetCoverController() *CoverController {
+	return cm.CoverController
+}
+
 // IsInitialized 检查是否已初始化
 func (cm *ControllerManager) IsInitialized() bool {
 	return cm.MusicController != nil &&
@@ -324,8 +407,14 @@ func (cm *ControllerManager) GetRouteInfo() map[string]interface{} {
 			"GET /api/v1/system/metrics",
 			"GET /api/v1/system/sources",
 			"POST /api/v1/system/sources/refresh",
+			"POST /api/v1/system/sources/:name/breaker/open",
+			"POST /api/v1/system/sources/:name/breaker/reset",
 			"GET /api/v1/system/cache/stats",
 			"POST /api/v1/system/cache/clear",
+			"GET /api/v1/system/cache/keys",
+			"DELETE /api/v1/system/cache/keys",
+			"GET /api/v1/system/cache/entry",
+			"POST /api/v1/system/cache/expire",
 			"GET /ping",
 			"GET /version",
 		},
@@ -0,0 +1,151 @@
+// Package controller 封面图片代理控制器
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/service"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/errors"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// coverCacheControl 封面响应的缓存策略，同一链接的渲染结果不会变化
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+// CoverController 封面图片代理控制器
+type CoverController struct {
+	coverService service.CoverService
+	linker       *service.StreamLinker
+	logger       logger.Logger
+}
+
+// NewCoverController 创建封面图片代理控制器，linker用于校验代理链接签名
+func NewCoverController(coverService service.CoverService, linker *service.StreamLinker, log logger.Logger) *CoverController {
+	return &CoverController{
+		coverService: coverService,
+		linker:       linker,
+		logger:       log,
+	}
+}
+
+// Cover 代理封面图片
+// @Summary 代理封面图片
+// @Description 获取专辑图后在服务端缩放并转换格式，去除图片元数据，渲染结果缓存在磁盘；启用链接签名时须使用专辑图接口返回的proxy_url
+// @Tags 音乐
+// @Produce jpeg
+// @Produce png
+// @Param id query string true "专辑图ID"
+// @Param source query string false "优先使用的音源"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param size query integer false "输出边长（像素），只缩小不放大" default(300)
+// @Param format query string false "输出格式: jpeg, png" default(jpeg)
+// @Param quality query integer false "JPEG质量（1-100）"
+// @Param exp query integer false "链接过期时间（启用链接签名时必需）"
+// @Param kid query string false "签名密钥ID（启用链接签名时必需）"
+// @Param sig query string false "链接签名（启用链接签名时必需）"
+// @Success 200 {file} file "图片内容"
+// @Success 304 "未修改"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "链接签名无效或已过期"
+// @Failure 404 {object} response.ErrorResponse "未找到专辑图"
+// @Failure 502 {object} response.ErrorResponse "上游请求失败"
+// @Router /cover [get]
+func (c *CoverController) Cover(ctx *gin.Context) {
+	start := time.Now()
+
+	var req model.CoverRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn("参数绑定失败",
+			logger.String("path", ctx.Request.URL.Path),
+			logger.ErrorField("error", err),
+		)
+		response.Error(ctx, errors.ErrInvalidParameter.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return
+	}
+
+	if err := c.linker.Verify(service.CoverPath, ctx.Request.URL.Query()); err != nil {
+		c.logger.Warn("代理链接校验失败",
+			logger.String("id", req.ID),
+			logger.String("client_ip", ctx.ClientIP()),
+			logger.ErrorField("error", err),
+		)
+		linkError(ctx, err)
+		return
+	}
+
+	result, err := c.coverService.Render(ctx.Request.Context(), &req)
+	if err != nil {
+		if stderrors.Is(err, context.Canceled) {
+			return
+		}
+		c.logger.Error("获取封面失败",
+			logger.String("id", req.ID),
+			logger.String("duration", time.Since(start).String()),
+			logger.ErrorField("error", err),
+		)
+
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "参数"):
+			response.Error(ctx, errors.ErrInvalidParameter.WithMessage(msg))
+		case strings.Contains(msg, "限流"):
+			response.Error(ctx, errors.ErrRateLimitExceeded.WithMessage(msg))
+		case stderrors.Is(err, service.ErrUpstreamCover):
+			response.ErrorWithCode(ctx, http.StatusBadGateway, errors.CodeProxyError, msg)
+		case strings.Contains(msg, "没有支持"):
+			response.ServiceUnavailable(ctx, msg)
+		case strings.Contains(msg, "未找到"), strings.Contains(msg, "无法获取"):
+			response.Error(ctx, errors.ErrResourceNotFound.WithMessage(msg))
+		default:
+			response.Error(ctx, errors.ErrInternalServer.WithMessage(msg))
+		}
+		return
+	}
+
+	header := ctx.Writer.Header()
+	header.Set("ETag", result.ETag)
+	header.Set("Cache-Control", coverCacheControl)
+	if result.Cached {
+		header.Set("X-Cover-Cache", "hit")
+	} else {
+		header.Set("X-Cover-Cache", "miss")
+	}
+
+	if match := ctx.GetHeader("If-None-Match"); match != "" && etagMatches(match, result.ETag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	c.logger.Debug("封面代理完成",
+		logger.String("id", req.ID),
+		logger.Bool("cached", result.Cached),
+		logger.Int("bytes", len(result.Data)),
+		logger.String("duration", time.Since(start).String()),
+	)
+	ctx.Data(http.StatusOK, result.ContentType, result.Data)
+}
+
+// etagMatches 判断If-None-Match是否包含指定ETag，支持逗号分隔的列表与通配符
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterRoutes 注册路由
+func (c *CoverController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/cover", c.Cover) // 代理封面图片
+}
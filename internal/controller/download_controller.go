@@ -0,0 +1,193 @@
+// Package controller 带标签下载控制器
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/service"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/errors"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadController 带标签下载控制器
+type DownloadController struct {
+	downloadService service.DownloadService
+	linker          *service.StreamLinker
+	logger          logger.Logger
+}
+
+// NewDownloadController 创建带标签下载控制器，linker用于校验下载链接签名
+func NewDownloadController(downloadService service.DownloadService, linker *service.StreamLinker, log logger.Logger) *DownloadController {
+	return &DownloadController{
+		downloadService: downloadService,
+		linker:          linker,
+		logger:          log,
+	}
+}
+
+// Download 下载带标签的曲目
+// @Summary 下载带标签的曲目
+// @Description 由服务端获取音频并写入标题、艺术家、专辑、封面与歌词标签后以附件形式返回：MP3写入ID3v2.4，FLAC写入Vorbis注释与图片块，其他格式原样返回；启用链接签名时须使用匹配接口返回的download_url
+// @Tags 音乐
+// @Produce octet-stream
+// @Param id query string true "音乐ID"
+// @Param server query string false "指定音源，逗号分隔"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param br query string false "期望音质: 128, 192, 320, 740, 999" default(320)
+// @Param quality_policy query string false "音质协商策略: exact, at-most, at-least, best-available" default(at-most)
+// @Param exp query integer false "链接过期时间（启用链接签名时必需）"
+// @Param kid query string false "签名密钥ID（启用链接签名时必需）"
+// @Param sig query string false "链接签名（启用链接签名时必需）"
+// @Success 200 {file} file "音频文件"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "链接签名无效或已过期"
+// @Failure 404 {object} response.ErrorResponse "未找到曲目"
+// @Failure 502 {object} response.ErrorResponse "上游请求失败"
+// @Router /download [get]
+func (c *DownloadController) Download(ctx *gin.Context) {
+	start := time.Now()
+
+	var req model.StreamRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn("参数绑定失败",
+			logger.String("path", ctx.Request.URL.Path),
+			logger.ErrorField("error", err),
+		)
+		response.Error(ctx, errors.ErrInvalidParameter.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return
+	}
+
+	if err := c.linker.Verify(service.DownloadPath, ctx.Request.URL.Query()); err != nil {
+		c.logger.Warn("下载链接校验失败",
+			logger.String("id", req.ID),
+			logger.String("client_ip", ctx.ClientIP()),
+			logger.ErrorField("error", err),
+		)
+		linkError(ctx, err)
+		return
+	}
+
+	result, err := c.downloadService.Open(ctx.Request.Context(), &req)
+	if err != nil {
+		if stderrors.Is(err, context.Canceled) {
+			c.logger.Debug("客户端已断开，取消下载", logger.String("id", req.ID))
+			return
+		}
+		c.logger.Error("打开下载失败",
+			logger.String("id", req.ID),
+			logger.String("duration", time.Since(start).String()),
+			logger.ErrorField("error", err),
+		)
+
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "参数"):
+			response.Error(ctx, errors.ErrInvalidParameter.WithMessage(msg))
+		case strings.Contains(msg, "限流"):
+			response.Error(ctx, errors.ErrRateLimitExceeded.WithMessage(msg))
+		case stderrors.Is(err, service.ErrUpstreamStream):
+			response.ErrorWithCode(ctx, http.StatusBadGateway, errors.CodeProxyError, msg)
+		case strings.Contains(msg, "未找到"), strings.Contains(msg, "无法匹配"):
+			response.Error(ctx, errors.ErrResourceNotFound.WithMessage(msg))
+		default:
+			response.Error(ctx, errors.ErrInternalServer.WithMessage(msg))
+		}
+		return
+	}
+	defer result.Body.Close()
+
+	header := ctx.Writer.Header()
+	if result.ContentType != "" {
+		header.Set("Content-Type", result.ContentType)
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	if result.ContentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(result.ContentLength, 10))
+	}
+	header.Set("Content-Disposition", contentDisposition(result.Filename))
+	header.Set("X-Music-Source", result.Source)
+	if result.Quality != "" {
+		header.Set("X-Music-Quality", result.Quality)
+	}
+	header.Set("X-Audio-Tagged", strconv.FormatBool(result.Tagged))
+
+	// 下载的传输时间可能超过服务器写超时，单独取消该请求的写截止时间
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.logger.Debug("无法取消写超时", logger.ErrorField("error", err))
+	}
+
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+
+	written, err := io.Copy(ctx.Writer, result.Body)
+	if err != nil {
+		if ctx.Request.Context().Err() != nil {
+			c.logger.Debug("客户端已断开下载",
+				logger.String("id", req.ID),
+				logger.Any("written", written),
+			)
+			return
+		}
+		c.logger.Warn("下载传输中断",
+			logger.String("id", req.ID),
+			logger.Any("written", written),
+			logger.ErrorField("error", err),
+		)
+		return
+	}
+
+	c.logger.Info("下载完成",
+		logger.String("id", req.ID),
+		logger.String("source", result.Source),
+		logger.String("filename", result.Filename),
+		logger.Any("written", written),
+		logger.String("duration", time.Since(start).String()),
+	)
+}
+
+// contentDisposition 生成附件响应头，filename为ASCII回退名称，filename*携带UTF-8原名（RFC 6266）
+func contentDisposition(filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x80 && r != '"' && r != '\\' {
+			fallback.WriteRune(r)
+		} else {
+			fallback.WriteByte('_')
+		}
+	}
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			encoded.WriteString("%" + strings.ToUpper(strconv.FormatInt(int64(b)|0x100, 16)[1:]))
+		}
+	}
+	return `attachment; filename="` + fallback.String() + `"; filename*=UTF-8''` + encoded.String()
+}
+
+// isAttrChar 判断字节是否为RFC 5987允许不编码的字符
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+	}
+}
+
+// RegisterRoutes 注册路由
+func (c *DownloadController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/download", c.Download) // 下载带标签的曲目
+}
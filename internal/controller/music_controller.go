@@ -2,6 +2,7 @@
 package controller
 
 import (
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -10,19 +11,22 @@ import (
 	"github.com/IIXINGCHEN/music-api-proxy/internal/service"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/errors"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/lyrics"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/response"
 )
 
 // MusicController 音乐控制器
 type MusicController struct {
 	musicService service.MusicService
+	linker       *service.StreamLinker
 	logger       logger.Logger
 }
 
-// NewMusicController 创建音乐控制器
-func NewMusicController(musicService service.MusicService, log logger.Logger) *MusicController {
+// NewMusicController 创建音乐控制器，linker用于校验本地曲库链接签名
+func NewMusicController(musicService service.MusicService, linker *service.StreamLinker, log logger.Logger) *MusicController {
 	return &MusicController{
 		musicService: musicService,
+		linker:       linker,
 		logger:       log,
 	}
 }
@@ -35,6 +39,10 @@ func NewMusicController(musicService service.MusicService, log logger.Logger) *M
 // @Produce json
 // @Param id query string true "音乐ID"
 // @Param server query string false "指定音源，逗号分隔"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param strategy query string false "匹配策略: sequential, parallel, hedged"
+// @Param br query string false "期望音质: 128, 192, 320, 740, 999" default(320)
+// @Param quality_policy query string false "音质协商策略: exact, at-most, at-least, best-available" default(at-most)
 // @Success 200 {object} model.MatchResponse "匹配成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
 // @Failure 500 {object} response.ErrorResponse "服务器错误"
@@ -61,12 +69,18 @@ func (c *MusicController) Match(ctx *gin.Context) {
 	c.logger.Info("开始匹配音乐",
 		logger.String("id", req.ID),
 		logger.String("server", req.Server),
+		logger.String("platform", req.Platform),
+		logger.String("strategy", req.Strategy),
+		logger.String("br", req.BR),
+		logger.String("quality_policy", req.QualityPolicy),
 		logger.Any("sources", req.Sources),
 		logger.String("client_ip", ctx.ClientIP()),
 	)
 	
-	// 调用服务
-	result, err := c.musicService.MatchMusic(ctx.Request.Context(), &req)
+	// 调用服务，X-Cache响应头标明结果是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+	result, err := c.musicService.MatchMusic(cacheCtx, &req)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("匹配音乐失败",
 			logger.String("id", req.ID),
@@ -104,6 +118,8 @@ func (c *MusicController) Match(ctx *gin.Context) {
 // @Produce json
 // @Param id query string true "音乐ID"
 // @Param br query string false "音质参数"
+// @Param strategy query string false "匹配策略: sequential, parallel, hedged"
+// @Param quality_policy query string false "音质协商策略: exact, at-most, at-least, best-available" default(at-most)
 // @Success 200 {object} model.NCMGetResponse "获取成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
 // @Failure 500 {object} response.ErrorResponse "服务器错误"
@@ -127,11 +143,14 @@ func (c *MusicController) GetNCM(ctx *gin.Context) {
 	c.logger.Info("开始获取网易云音乐",
 		logger.String("id", req.ID),
 		logger.String("br", req.BR),
+		logger.String("quality_policy", req.QualityPolicy),
 		logger.String("client_ip", ctx.ClientIP()),
 	)
 	
-	// 调用服务
-	result, err := c.musicService.GetNCMMusic(ctx.Request.Context(), &req)
+	// 调用服务，X-Cache响应头标明结果是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+	result, err := c.musicService.GetNCMMusic(cacheCtx, &req)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("获取网易云音乐失败",
 			logger.String("id", req.ID),
@@ -140,7 +159,7 @@ func (c *MusicController) GetNCM(ctx *gin.Context) {
 			logger.ErrorField("error", err),
 		)
 		
-		if strings.Contains(err.Error(), "参数") || strings.Contains(err.Error(), "音质") {
+		if strings.Contains(err.Error(), "参数") || strings.Contains(err.Error(), "不支持的音质") {
 			response.Error(ctx, errors.ErrInvalidParameter.WithMessage(err.Error()))
 		} else if strings.Contains(err.Error(), "限流") {
 			response.Error(ctx, errors.ErrRateLimitExceeded.WithMessage(err.Error()))
@@ -193,8 +212,10 @@ func (c *MusicController) GetOther(ctx *gin.Context) {
 		logger.String("client_ip", ctx.ClientIP()),
 	)
 	
-	// 调用服务
-	result, err := c.musicService.GetOtherMusic(ctx.Request.Context(), &req)
+	// 调用服务，X-Cache响应头标明结果是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+	result, err := c.musicService.GetOtherMusic(cacheCtx, &req)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("获取其他音源音乐失败",
 			logger.String("name", req.Name),
@@ -227,13 +248,15 @@ func (c *MusicController) GetOther(ctx *gin.Context) {
 
 // Search 搜索音乐
 // @Summary 搜索音乐
-// @Description 根据关键词搜索音乐
+// @Description 根据关键词搜索音乐，结果按匹配度评分排序
 // @Tags 音乐
 // @Accept json
 // @Produce json
 // @Param keyword query string true "搜索关键词"
 // @Param sources query string false "音源列表，逗号分隔"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
 // @Param limit query int false "结果数量限制" default(20)
+// @Param explain query bool false "是否返回评分明细" default(false)
 // @Success 200 {object} response.SuccessResponse{data=[]model.SearchResult} "搜索成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
 // @Failure 500 {object} response.ErrorResponse "服务器错误"
@@ -248,6 +271,7 @@ func (c *MusicController) Search(ctx *gin.Context) {
 		return
 	}
 	
+	platform := ctx.Query("platform")
 	sourcesParam := ctx.Query("sources")
 	var sources []string
 	if sourcesParam != "" {
@@ -266,15 +290,20 @@ func (c *MusicController) Search(ctx *gin.Context) {
 		limit = 100 // 限制最大返回数量
 	}
 	
+	explain, _ := strconv.ParseBool(ctx.Query("explain"))
+	
 	c.logger.Info("开始搜索音乐",
 		logger.String("keyword", keyword),
+		logger.String("platform", platform),
 		logger.Any("sources", sources),
 		logger.Int("limit", limit),
 		logger.String("client_ip", ctx.ClientIP()),
 	)
 	
-	// 调用服务
-	results, err := c.musicService.SearchMusic(ctx.Request.Context(), keyword, sources)
+	// 调用服务，X-Cache响应头标明结果是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+	results, err := c.musicService.SearchMusic(cacheCtx, keyword, platform, sources)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("搜索音乐失败",
 			logger.String("keyword", keyword),
@@ -297,6 +326,17 @@ func (c *MusicController) Search(ctx *gin.Context) {
 		results = results[:limit]
 	}
 	
+	// 未请求评分明细时去掉明细，结果可能来自缓存，需复制后修改
+	if !explain {
+		stripped := make([]*model.SearchResult, len(results))
+		for i, result := range results {
+			copied := *result
+			copied.ScoreDetail = nil
+			stripped[i] = &copied
+		}
+		results = stripped
+	}
+	
 	c.logger.Info("搜索音乐成功",
 		logger.String("keyword", keyword),
 		logger.Int("result_count", len(results)),
@@ -313,6 +353,7 @@ func (c *MusicController) Search(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param source query string true "音源名称"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
 // @Param id query string true "音乐ID"
 // @Success 200 {object} model.MusicInfo "获取成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
@@ -323,6 +364,7 @@ func (c *MusicController) GetInfo(ctx *gin.Context) {
 	
 	// 解析参数
 	source := ctx.Query("source")
+	platform := ctx.Query("platform")
 	id := ctx.Query("id")
 	
 	if source == "" {
@@ -337,12 +379,15 @@ func (c *MusicController) GetInfo(ctx *gin.Context) {
 	
 	c.logger.Info("开始获取音乐信息",
 		logger.String("source", source),
+		logger.String("platform", platform),
 		logger.String("id", id),
 		logger.String("client_ip", ctx.ClientIP()),
 	)
 	
-	// 调用服务
-	info, err := c.musicService.GetMusicInfo(ctx.Request.Context(), source, id)
+	// 调用服务，X-Cache响应头标明结果是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+	info, err := c.musicService.GetMusicInfo(cacheCtx, source, platform, id)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("获取音乐信息失败",
 			logger.String("source", source),
@@ -376,9 +421,24 @@ func (c *MusicController) GetInfo(ctx *gin.Context) {
 }
 
 // GetPicture 获取专辑图
+// @Summary 获取专辑图
+// @Description 获取专辑图链接，未指定音源时在所有支持专辑图的音源间依次回退
+// @Tags 音乐
+// @Accept json
+// @Produce json
+// @Param id query string true "专辑图ID"
+// @Param source query string false "优先使用的音源名称"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param size query string false "尺寸: 300或500" default(300)
+// @Success 200 {object} model.PictureResult "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 404 {object} response.ErrorResponse "未找到专辑图"
+// @Failure 503 {object} response.ErrorResponse "没有可用音源"
+// @Router /picture [get]
 func (c *MusicController) GetPicture(ctx *gin.Context) {
 	// 获取参数
-	source := ctx.DefaultQuery("source", "gdstudio")
+	source := ctx.Query("source")
+	platform := ctx.Query("platform")
 	picID := ctx.Query("id")
 	size := ctx.DefaultQuery("size", "300")
 
@@ -388,12 +448,6 @@ func (c *MusicController) GetPicture(ctx *gin.Context) {
 		return
 	}
 
-	// 验证音源
-	if source != "gdstudio" {
-		response.BadRequest(ctx, "当前仅支持gdstudio音源")
-		return
-	}
-
 	// 验证尺寸
 	if size != "300" && size != "500" {
 		response.BadRequest(ctx, "尺寸只能是300或500")
@@ -402,66 +456,270 @@ func (c *MusicController) GetPicture(ctx *gin.Context) {
 
 	c.logger.Info("获取专辑图请求",
 		logger.String("source", source),
+		logger.String("platform", platform),
 		logger.String("pic_id", picID),
 		logger.String("size", size),
 	)
 
 	// 调用服务获取专辑图
-	picURL, err := c.musicService.GetPicture(ctx, source, picID, size)
+	picture, err := c.musicService.GetPicture(ctx, source, platform, picID, size)
 	if err != nil {
 		c.logger.Error("获取专辑图失败",
 			logger.String("source", source),
 			logger.String("pic_id", picID),
 			logger.ErrorField("error", err),
 		)
-		response.InternalServerError(ctx, "获取专辑图失败: "+err.Error())
+		c.capabilityError(ctx, "获取专辑图失败", err)
 		return
 	}
 
-	response.Success(ctx, "获取成功", map[string]string{
-		"url": picURL,
-	})
+	response.Success(ctx, "获取成功", picture)
 }
 
 // GetLyric 获取歌词
+// @Summary 获取歌词
+// @Description 获取歌词及翻译歌词，未指定音源时在所有支持歌词的音源间依次回退；format=json时返回解析后的时间轴，翻译按时间合并到原文行，增强LRC歌词带逐字时间；format=karaoke时每行带起止时间与逐字时间；format=srt、vtt、ttml时直接输出字幕文件，可供<track>元素加载
+// @Tags 音乐
+// @Accept json
+// @Produce json,text/vtt,application/x-subrip,application/ttml+xml
+// @Param id query string true "歌词ID"
+// @Param source query string false "优先使用的音源名称"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param format query string false "返回格式: lrc, json, karaoke, srt, vtt, ttml" default(lrc)
+// @Param offset query integer false "整体时间偏移（毫秒），正值推迟显示，不适用于lrc格式" default(0)
+// @Param translation query string false "字幕中翻译的输出方式: inline（第二行）, none, only（单独轨道）" default(inline)
+// @Success 200 {object} model.LyricResult "获取成功"
+// @Success 200 {object} model.LyricTimeline "获取成功（format=json）"
+// @Success 200 {object} model.KaraokeLyric "获取成功（format=karaoke）"
+// @Success 200 {file} file "字幕文件（format=srt、vtt、ttml）"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 404 {object} response.ErrorResponse "未找到歌词"
+// @Failure 503 {object} response.ErrorResponse "没有可用音源"
+// @Router /lyric [get]
 func (c *MusicController) GetLyric(ctx *gin.Context) {
 	// 获取参数
-	source := ctx.DefaultQuery("source", "gdstudio")
+	source := ctx.Query("source")
+	platform := ctx.Query("platform")
 	lyricID := ctx.Query("id")
+	format := ctx.DefaultQuery("format", "lrc")
+	translation := ctx.DefaultQuery("translation", string(lyrics.TranslationInline))
 
 	// 参数验证
 	if lyricID == "" {
 		response.BadRequest(ctx, "歌词ID不能为空")
 		return
 	}
-
-	// 验证音源
-	if source != "gdstudio" {
-		response.BadRequest(ctx, "当前仅支持gdstudio音源")
+	switch format {
+	case "lrc", "json", "karaoke", lyrics.FormatSRT, lyrics.FormatVTT, lyrics.FormatTTML:
+	default:
+		response.BadRequest(ctx, "不支持的歌词格式: "+format+"，支持的格式: lrc, json, karaoke, srt, vtt, ttml")
+		return
+	}
+	switch lyrics.TranslationMode(translation) {
+	case lyrics.TranslationInline, lyrics.TranslationNone, lyrics.TranslationOnly:
+	default:
+		response.BadRequest(ctx, "不支持的翻译输出方式: "+translation+"，支持的方式: inline, none, only")
 		return
 	}
 
+	var offset time.Duration
+	if value := ctx.Query("offset"); value != "" {
+		if format == "lrc" {
+			response.BadRequest(ctx, "offset参数不适用于lrc格式")
+			return
+		}
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			response.BadRequest(ctx, "offset必须是整数毫秒")
+			return
+		}
+		offset = time.Duration(ms) * time.Millisecond
+	}
+
 	c.logger.Info("获取歌词请求",
 		logger.String("source", source),
+		logger.String("platform", platform),
 		logger.String("lyric_id", lyricID),
+		logger.String("format", format),
 	)
 
+	// X-Cache响应头标明歌词是否来自缓存
+	cacheCtx, cacheStatus := model.WithCacheStatus(ctx.Request.Context())
+
+	switch format {
+	case "json":
+		timeline, err := c.musicService.GetLyricTimeline(cacheCtx, source, platform, lyricID, offset)
+		setCacheHeader(ctx, cacheStatus)
+		if err != nil {
+			c.logger.Error("获取歌词失败",
+				logger.String("source", source),
+				logger.String("lyric_id", lyricID),
+				logger.ErrorField("error", err),
+			)
+			c.capabilityError(ctx, "获取歌词失败", err)
+			return
+		}
+		response.Success(ctx, "获取成功", timeline)
+		return
+	case "karaoke":
+		karaoke, err := c.musicService.GetKaraokeLyric(cacheCtx, source, platform, lyricID, offset)
+		setCacheHeader(ctx, cacheStatus)
+		if err != nil {
+			c.logger.Error("获取歌词失败",
+				logger.String("source", source),
+				logger.String("lyric_id", lyricID),
+				logger.ErrorField("error", err),
+			)
+			c.capabilityError(ctx, "获取歌词失败", err)
+			return
+		}
+		response.Success(ctx, "获取成功", karaoke)
+		return
+	case lyrics.FormatSRT, lyrics.FormatVTT, lyrics.FormatTTML:
+		export, err := c.musicService.ExportLyric(cacheCtx, source, platform, lyricID, &model.LyricExportOptions{
+			Format:      format,
+			Translation: translation,
+			Offset:      offset,
+		})
+		setCacheHeader(ctx, cacheStatus)
+		if err != nil {
+			c.logger.Error("导出歌词字幕失败",
+				logger.String("source", source),
+				logger.String("lyric_id", lyricID),
+				logger.String("format", format),
+				logger.ErrorField("error", err),
+			)
+			c.capabilityError(ctx, "导出歌词字幕失败", err)
+			return
+		}
+		ctx.Header("X-Music-Source", export.Source)
+		ctx.Data(http.StatusOK, export.ContentType, export.Content)
+		return
+	}
+
 	// 调用服务获取歌词
-	lyric, tlyric, err := c.musicService.GetLyric(ctx, source, lyricID)
+	lyric, err := c.musicService.GetLyric(cacheCtx, source, platform, lyricID)
+	setCacheHeader(ctx, cacheStatus)
 	if err != nil {
 		c.logger.Error("获取歌词失败",
 			logger.String("source", source),
 			logger.String("lyric_id", lyricID),
 			logger.ErrorField("error", err),
 		)
-		response.InternalServerError(ctx, "获取歌词失败: "+err.Error())
+		c.capabilityError(ctx, "获取歌词失败", err)
+		return
+	}
+
+	response.Success(ctx, "获取成功", lyric)
+}
+
+// GetLocalFile 获取本地曲库音频文件
+// @Summary 获取本地曲库音频文件
+// @Description 输出本地曲库中的音频文件，支持Range请求；启用链接签名时须使用匹配接口返回的链接
+// @Tags 音乐
+// @Produce octet-stream
+// @Param id path string true "本地曲目ID"
+// @Param exp query integer false "链接过期时间（启用链接签名时必需）"
+// @Param kid query string false "签名密钥ID（启用链接签名时必需）"
+// @Param sig query string false "链接签名（启用链接签名时必需）"
+// @Success 200 {file} file "音频文件"
+// @Success 206 {file} file "部分内容"
+// @Failure 403 {object} response.ErrorResponse "链接签名无效或已过期"
+// @Failure 404 {object} response.ErrorResponse "未找到曲目"
+// @Failure 503 {object} response.ErrorResponse "本地曲库未启用"
+// @Router /local/{id}/file [get]
+func (c *MusicController) GetLocalFile(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !c.verifyLocalLink(ctx, id, "file") {
+		return
+	}
+
+	file, err := c.musicService.GetLocalTrack(ctx, id)
+	if err != nil {
+		c.logger.Warn("获取本地曲目失败",
+			logger.String("id", id),
+			logger.ErrorField("error", err),
+		)
+		c.capabilityError(ctx, "获取本地曲目失败", err)
+		return
+	}
+
+	if file.MIMEType != "" {
+		ctx.Header("Content-Type", file.MIMEType)
+	}
+	ctx.File(file.Path)
+}
+
+// GetLocalCover 获取本地曲库内嵌封面
+// @Summary 获取本地曲库内嵌封面
+// @Description 输出本地曲目内嵌的封面图片；启用链接签名时须使用接口返回的封面链接
+// @Tags 音乐
+// @Produce image/jpeg,image/png
+// @Param id path string true "本地曲目ID"
+// @Param exp query integer false "链接过期时间（启用链接签名时必需）"
+// @Param kid query string false "签名密钥ID（启用链接签名时必需）"
+// @Param sig query string false "链接签名（启用链接签名时必需）"
+// @Success 200 {file} file "封面图片"
+// @Failure 403 {object} response.ErrorResponse "链接签名无效或已过期"
+// @Failure 404 {object} response.ErrorResponse "未找到封面"
+// @Failure 503 {object} response.ErrorResponse "本地曲库未启用"
+// @Router /local/{id}/cover [get]
+func (c *MusicController) GetLocalCover(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !c.verifyLocalLink(ctx, id, "cover") {
+		return
+	}
+
+	cover, err := c.musicService.GetLocalCover(ctx, id)
+	if err != nil {
+		c.logger.Warn("获取本地曲目封面失败",
+			logger.String("id", id),
+			logger.ErrorField("error", err),
+		)
+		c.capabilityError(ctx, "获取本地曲目封面失败", err)
 		return
 	}
 
-	response.Success(ctx, "获取成功", map[string]string{
-		"lyric":  lyric,
-		"tlyric": tlyric,
-	})
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(http.StatusOK, cover.MIMEType, cover.Data)
+}
+
+// verifyLocalLink 校验本地曲库链接签名，未启用链接签名时总是通过
+func (c *MusicController) verifyLocalLink(ctx *gin.Context, id, resource string) bool {
+	scope := service.LocalPath + "/" + id + "/" + resource
+	if err := c.linker.Verify(scope, ctx.Request.URL.Query()); err != nil {
+		c.logger.Warn("本地曲库链接校验失败",
+			logger.String("id", id),
+			logger.String("resource", resource),
+			logger.String("client_ip", ctx.ClientIP()),
+			logger.ErrorField("error", err),
+		)
+		linkError(ctx, err)
+		return false
+	}
+	return true
+}
+
+// capabilityError 输出歌词、专辑图等音源能力请求的错误响应
+func (c *MusicController) capabilityError(ctx *gin.Context, prefix string, err error) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "参数"):
+		response.BadRequest(ctx, msg)
+	case strings.Contains(msg, "没有支持"):
+		response.ServiceUnavailable(ctx, msg)
+	case strings.Contains(msg, "未找到"):
+		response.NotFound(ctx, prefix+": "+msg)
+	default:
+		response.InternalServerError(ctx, prefix+": "+msg)
+	}
+}
+
+// setCacheHeader 将缓存状态写入X-Cache响应头，请求未经过缓存时不设置
+func setCacheHeader(ctx *gin.Context, recorder *model.CacheStatusRecorder) {
+	if status := recorder.Status(); status != "" {
+		ctx.Header("X-Cache", status)
+	}
 }
 
 // RegisterRoutes 注册路由
@@ -473,4 +731,6 @@ func (c *MusicController) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("/info", c.GetInfo)   // 获取音乐信息
 	router.GET("/picture", c.GetPicture)  // 新增专辑图接口
 	router.GET("/lyric", c.GetLyric)      // 新增歌词接口
+	router.GET("/local/:id/file", c.GetLocalFile)   // 本地曲库音频文件
+	router.GET("/local/:id/cover", c.GetLocalCover) // 本地曲库内嵌封面
 }
@@ -0,0 +1,287 @@
+// Package controller 流式代理控制器
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/service"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/errors"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/response"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/urlsign"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamController 流式代理控制器
+type StreamController struct {
+	streamService service.StreamService
+	linker        *service.StreamLinker
+	logger        logger.Logger
+}
+
+// NewStreamController 创建流式代理控制器，linker用于校验代理链接签名
+func NewStreamController(streamService service.StreamService, linker *service.StreamLinker, log logger.Logger) *StreamController {
+	return &StreamController{
+		streamService: streamService,
+		linker:        linker,
+		logger:        log,
+	}
+}
+
+// Stream 代理音频流
+// @Summary 代理音频流
+// @Description 解析曲目后由服务端转发上游音频，支持Range与If-Range请求，跟随上游重定向，本地曲库曲目直接输出文件；启用链接签名时须使用匹配接口返回的proxy_url
+// @Tags 音乐
+// @Produce octet-stream
+// @Param id query string true "音乐ID"
+// @Param server query string false "指定音源，逗号分隔"
+// @Param platform query string false "音乐平台，如netease、tencent、kugou" default(netease)
+// @Param br query string false "期望音质: 128, 192, 320, 740, 999" default(320)
+// @Param quality_policy query string false "音质协商策略: exact, at-most, at-least, best-available" default(at-most)
+// @Param exp query integer false "链接过期时间（启用链接签名时必需）"
+// @Param kid query string false "签名密钥ID（启用链接签名时必需）"
+// @Param sig query string false "链接签名（启用链接签名时必需）"
+// @Param Range header string false "字节范围，如 bytes=0-1023"
+// @Success 200 {file} file "音频内容"
+// @Success 206 {file} file "部分内容"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "链接签名无效或已过期"
+// @Failure 404 {object} response.ErrorResponse "未找到曲目"
+// @Failure 416 {object} response.ErrorResponse "范围无效"
+// @Failure 502 {object} response.ErrorResponse "上游请求失败"
+// @Router /stream [get]
+func (c *StreamController) Stream(ctx *gin.Context) {
+	start := time.Now()
+
+	var req model.StreamRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn("参数绑定失败",
+			logger.String("path", ctx.Request.URL.Path),
+			logger.ErrorField("error", err),
+		)
+		response.Error(ctx, errors.ErrInvalidParameter.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return
+	}
+
+	if err := c.linker.Verify(service.StreamPath, ctx.Request.URL.Query()); err != nil {
+		c.logger.Warn("代理链接校验失败",
+			logger.String("id", req.ID),
+			logger.String("client_ip", ctx.ClientIP()),
+			logger.ErrorField("error", err),
+		)
+		linkError(ctx, err)
+		return
+	}
+
+	result, err := c.streamService.Open(ctx.Request.Context(), ctx.Request.Method, &req, ctx.Request.Header)
+	if err != nil {
+		if stderrors.Is(err, context.Canceled) {
+			c.logger.Debug("客户端已断开，取消上游请求", logger.String("id", req.ID))
+			return
+		}
+		c.logger.Error("打开音频流失败",
+			logger.String("id", req.ID),
+			logger.String("duration", time.Since(start).String()),
+			logger.ErrorField("error", err),
+		)
+
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "参数"):
+			response.Error(ctx, errors.ErrInvalidParameter.WithMessage(msg))
+		case strings.Contains(msg, "限流"):
+			response.Error(ctx, errors.ErrRateLimitExceeded.WithMessage(msg))
+		case stderrors.Is(err, service.ErrUpstreamStream):
+			response.ErrorWithCode(ctx, http.StatusBadGateway, errors.CodeProxyError, msg)
+		case strings.Contains(msg, "未找到"), strings.Contains(msg, "无法匹配"):
+			response.Error(ctx, errors.ErrResourceNotFound.WithMessage(msg))
+		default:
+			response.Error(ctx, errors.ErrInternalServer.WithMessage(msg))
+		}
+		return
+	}
+
+	if result.File != nil {
+		c.serveLocalFile(ctx, result)
+		return
+	}
+	defer result.Body.Close()
+
+	header := ctx.Writer.Header()
+	for name, values := range result.Header {
+		header[name] = values
+	}
+	header.Set("X-Music-Source", result.Source)
+	if result.Quality != "" {
+		header.Set("X-Music-Quality", result.Quality)
+	}
+
+	// 音频流的传输时间可能超过服务器写超时，单独取消该请求的写截止时间
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.logger.Debug("无法取消写超时", logger.ErrorField("error", err))
+	}
+
+	ctx.Status(result.StatusCode)
+	ctx.Writer.WriteHeaderNow()
+	if ctx.Request.Method == http.MethodHead || result.StatusCode == http.StatusNotModified {
+		return
+	}
+
+	written, err := io.Copy(ctx.Writer, result.Body)
+	if err != nil {
+		if ctx.Request.Context().Err() != nil {
+			c.logger.Debug("客户端已断开音频流",
+				logger.String("id", req.ID),
+				logger.Any("written", written),
+			)
+			return
+		}
+		c.logger.Warn("转发音频流中断",
+			logger.String("id", req.ID),
+			logger.Any("written", written),
+			logger.ErrorField("error", err),
+		)
+		return
+	}
+
+	c.logger.Debug("音频流转发完成",
+		logger.String("id", req.ID),
+		logger.String("source", result.Source),
+		logger.Int("status", result.StatusCode),
+		logger.Any("written", written),
+		logger.String("duration", time.Since(start).String()),
+	)
+}
+
+// serveLocalFile 输出本地曲库曲目文件，Range与条件请求由http.ServeFile处理
+func (c *StreamController) serveLocalFile(ctx *gin.Context, result *service.StreamResult) {
+	header := ctx.Writer.Header()
+	header.Set("X-Music-Source", result.Source)
+	if result.Quality != "" {
+		header.Set("X-Music-Quality", result.Quality)
+	}
+	if result.File.MIMEType != "" {
+		header.Set("Content-Type", result.File.MIMEType)
+	}
+
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.logger.Debug("无法取消写超时", logger.ErrorField("error", err))
+	}
+	http.ServeFile(ctx.Writer, ctx.Request, result.File.Path)
+}
+
+// linkError 输出代理链接签名校验失败的响应
+func linkError(ctx *gin.Context, err error) {
+	if stderrors.Is(err, urlsign.ErrExpired) {
+		response.Error(ctx, errors.ErrLinkExpired.WithMessage(err.Error()))
+		return
+	}
+	response.Error(ctx, errors.ErrLinkSignatureInvalid.WithMessage(err.Error()))
+}
+
+// GetCache 查看音频缓存
+// @Summary 查看音频缓存
+// @Description 获取音频磁盘缓存的统计信息与条目列表（按最近访问排序）
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} response.SuccessResponse "获取成功"
+// @Failure 503 {object} response.ErrorResponse "音频缓存未启用"
+// @Router /stream/cache [get]
+func (c *StreamController) GetCache(ctx *gin.Context) {
+	stats, err := c.streamService.CacheStats(ctx.Request.Context())
+	if err != nil {
+		c.cacheError(ctx, err)
+		return
+	}
+	entries, err := c.streamService.CacheEntries(ctx.Request.Context())
+	if err != nil {
+		c.cacheError(ctx, err)
+		return
+	}
+
+	response.Success(ctx, "获取成功", map[string]interface{}{
+		"stats":   stats,
+		"entries": entries,
+	})
+}
+
+// PurgeCacheEntry 清除音频缓存条目
+// @Summary 清除音频缓存条目
+// @Description 删除指定的音频缓存条目，正在进行的下载随之中止
+// @Tags 系统
+// @Produce json
+// @Param id path string true "条目ID"
+// @Success 200 {object} response.SuccessResponse "清除成功"
+// @Failure 404 {object} response.ErrorResponse "条目不存在"
+// @Failure 503 {object} response.ErrorResponse "音频缓存未启用"
+// @Router /stream/cache/{id} [delete]
+func (c *StreamController) PurgeCacheEntry(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	c.logger.Info("清除音频缓存条目",
+		logger.String("id", id),
+		logger.String("client_ip", ctx.ClientIP()),
+	)
+
+	if err := c.streamService.PurgeCacheEntry(ctx.Request.Context(), id); err != nil {
+		c.cacheError(ctx, err)
+		return
+	}
+	response.Success(ctx, "清除成功", map[string]interface{}{"id": id})
+}
+
+// PurgeCache 清空音频缓存
+// @Summary 清空音频缓存
+// @Description 删除全部音频缓存条目
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} response.SuccessResponse "清除成功"
+// @Failure 503 {object} response.ErrorResponse "音频缓存未启用"
+// @Router /stream/cache [delete]
+func (c *StreamController) PurgeCache(ctx *gin.Context) {
+	c.logger.Info("清空音频缓存", logger.String("client_ip", ctx.ClientIP()))
+
+	count, err := c.streamService.PurgeCache(ctx.Request.Context())
+	if err != nil {
+		c.cacheError(ctx, err)
+		return
+	}
+	response.Success(ctx, "清除成功", map[string]interface{}{"purged": count})
+}
+
+// cacheError 输出音频缓存管理接口的错误响应
+func (c *StreamController) cacheError(ctx *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case stderrors.Is(err, service.ErrAudioCacheDisabled):
+		response.ServiceUnavailable(ctx, msg)
+	case strings.Contains(msg, "未找到"):
+		response.NotFound(ctx, msg)
+	default:
+		response.InternalServerError(ctx, msg)
+	}
+}
+
+// RegisterRoutes 注册路由
+func (c *StreamController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/stream", c.Stream)  // 代理音频流
+	router.HEAD("/stream", c.Stream) // 查询音频流元信息
+}
+
+// RegisterAdminRoutes 注册需要管理员权限的音频缓存管理路由
+func (c *StreamController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	cacheGroup := router.Group("/stream/cache")
+	{
+		cacheGroup.GET("", c.GetCache)
+		cacheGroup.DELETE("", c.PurgeCache)
+		cacheGroup.DELETE("/:id", c.PurgeCacheEntry)
+	}
+}
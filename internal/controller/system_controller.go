@@ -2,9 +2,13 @@
 package controller
 
 import (
+	stderrors "errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/service"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/errors"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
@@ -204,6 +208,240 @@ func (c *SystemController) RefreshSources(ctx *gin.Context) {
 	response.Success(ctx, "刷新成功", nil)
 }
 
+// OpenCircuitBreaker 强制打开音源熔断器
+// @Summary 强制打开音源熔断器
+// @Description 强制打开指定音源的熔断器，直到手动重置前该音源不再接收请求
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param name path string true "音源名称"
+// @Success 200 {object} response.SuccessResponse{data=model.CircuitBreakerStatus} "操作成功"
+// @Failure 400 {object} response.ErrorResponse "音源未启用熔断器"
+// @Failure 404 {object} response.ErrorResponse "音源不存在"
+// @Router /system/sources/{name}/breaker/open [post]
+func (c *SystemController) OpenCircuitBreaker(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	c.logger.Info("强制打开音源熔断器",
+		logger.String("source", name),
+		logger.String("client_ip", ctx.ClientIP()),
+	)
+
+	status, err := c.systemService.OpenCircuitBreaker(ctx.Request.Context(), name)
+	if err != nil {
+		response.Error(ctx, circuitBreakerError(err))
+		return
+	}
+
+	response.Success(ctx, "熔断器已打开", status)
+}
+
+// ResetCircuitBreaker 重置音源熔断器
+// @Summary 重置音源熔断器
+// @Description 将指定音源的熔断器重置为关闭状态并清空失败统计
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param name path string true "音源名称"
+// @Success 200 {object} response.SuccessResponse{data=model.CircuitBreakerStatus} "操作成功"
+// @Failure 400 {object} response.ErrorResponse "音源未启用熔断器"
+// @Failure 404 {object} response.ErrorResponse "音源不存在"
+// @Router /system/sources/{name}/breaker/reset [post]
+func (c *SystemController) ResetCircuitBreaker(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	c.logger.Info("重置音源熔断器",
+		logger.String("source", name),
+		logger.String("client_ip", ctx.ClientIP()),
+	)
+
+	status, err := c.systemService.ResetCircuitBreaker(ctx.Request.Context(), name)
+	if err != nil {
+		response.Error(ctx, circuitBreakerError(err))
+		return
+	}
+
+	response.Success(ctx, "熔断器已重置", status)
+}
+
+// circuitBreakerError 将熔断器管理错误映射为业务错误
+func circuitBreakerError(err error) *errors.BusinessError {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "不存在"):
+		return errors.ErrResourceNotFound.WithMessage(msg)
+	case strings.Contains(msg, "未启用"):
+		return errors.ErrInvalidParameter.WithMessage(msg)
+	default:
+		return errors.ErrInternalServer.WithMessage(msg)
+	}
+}
+
+// ListCacheKeys 列出缓存键
+// @Summary 列出缓存键
+// @Description 按glob模式或命名空间分页列出缓存键，键按字典序排列，未指定条件时列出所有键
+// @Tags 系统
+// @Produce json
+// @Param pattern query string false "键的glob模式，如 unm:search:*"
+// @Param namespace query string false "缓存命名空间，如 match、search"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大1000" default(50)
+// @Success 200 {object} response.SuccessResponse{data=model.CacheKeyList} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 503 {object} response.ErrorResponse "缓存未启用"
+// @Router /system/cache/keys [get]
+func (c *SystemController) ListCacheKeys(ctx *gin.Context) {
+	var selector model.CacheKeySelector
+	if err := ctx.ShouldBindQuery(&selector); err != nil {
+		response.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil {
+		response.BadRequest(ctx, "page必须是整数")
+		return
+	}
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "0"))
+	if err != nil {
+		response.BadRequest(ctx, "page_size必须是整数")
+		return
+	}
+
+	list, err := c.systemService.ListCacheKeys(ctx.Request.Context(), &selector, page, pageSize)
+	if err != nil {
+		response.Error(ctx, cacheAdminError(err))
+		return
+	}
+	response.Success(ctx, "获取成功", list)
+}
+
+// GetCacheEntry 查看缓存条目
+// @Summary 查看缓存条目
+// @Description 获取缓存条目的值与剩余过期时间，JSON值原样返回
+// @Tags 系统
+// @Produce json
+// @Param key query string true "缓存键"
+// @Success 200 {object} response.SuccessResponse{data=model.CacheEntry} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 404 {object} response.ErrorResponse "缓存条目不存在"
+// @Failure 503 {object} response.ErrorResponse "缓存未启用"
+// @Router /system/cache/entry [get]
+func (c *SystemController) GetCacheEntry(ctx *gin.Context) {
+	entry, err := c.systemService.GetCacheEntry(ctx.Request.Context(), ctx.Query("key"))
+	if err != nil {
+		response.Error(ctx, cacheAdminError(err))
+		return
+	}
+	response.Success(ctx, "获取成功", entry)
+}
+
+// DeleteCacheKeys 删除缓存键
+// @Summary 删除缓存键
+// @Description 删除单个缓存键，或按glob模式、命名空间批量删除；清空全部缓存请使用 /system/cache/clear
+// @Tags 系统
+// @Produce json
+// @Param key query string false "缓存键，按原样匹配"
+// @Param pattern query string false "键的glob模式，如 unm:match:*"
+// @Param namespace query string false "缓存命名空间，如 match、search"
+// @Success 200 {object} response.SuccessResponse "删除成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 503 {object} response.ErrorResponse "缓存未启用"
+// @Router /system/cache/keys [delete]
+func (c *SystemController) DeleteCacheKeys(ctx *gin.Context) {
+	var selector model.CacheKeySelector
+	if err := ctx.ShouldBindQuery(&selector); err != nil {
+		response.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+
+	c.logger.Info("删除缓存键",
+		logger.String("key", selector.Key),
+		logger.String("pattern", selector.Pattern),
+		logger.String("namespace", selector.Namespace),
+		logger.String("client_ip", ctx.ClientIP()),
+	)
+
+	deleted, err := c.systemService.DeleteCacheKeys(ctx.Request.Context(), &selector)
+	if err != nil {
+		response.Error(ctx, cacheAdminError(err))
+		return
+	}
+	response.Success(ctx, "删除成功", map[string]interface{}{"deleted": deleted})
+}
+
+// ExpireCacheKeys 设置缓存过期时间
+// @Summary 设置缓存过期时间
+// @Description 重新设置单个缓存键或按模式、命名空间选中的键的剩余过期时间，可用于延长缓存；带缓存策略的条目同时改写新鲜期，响应列出每个键的处理方式
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param request body model.CacheExpireRequest true "选择条件与新的过期时间"
+// @Success 200 {object} response.SuccessResponse{data=model.CacheExpireSummary} "设置成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 503 {object} response.ErrorResponse "缓存未启用"
+// @Router /system/cache/expire [post]
+func (c *SystemController) ExpireCacheKeys(ctx *gin.Context) {
+	var req model.CacheExpireRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "参数错误: "+err.Error())
+		return
+	}
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil {
+		response.BadRequest(ctx, "ttl格式无效，如 30m、1h")
+		return
+	}
+
+	c.logger.Info("设置缓存过期时间",
+		logger.String("key", req.Key),
+		logger.String("pattern", req.Pattern),
+		logger.String("namespace", req.Namespace),
+		logger.Duration("ttl", ttl),
+		logger.String("client_ip", ctx.ClientIP()),
+	)
+
+	summary, err := c.systemService.ExpireCacheKeys(ctx.Request.Context(), &req.CacheKeySelector, ttl)
+	if err != nil {
+		response.Error(ctx, cacheAdminError(err))
+		return
+	}
+	response.Success(ctx, "设置成功", summary)
+}
+
+// cacheAdminError 将缓存管理错误映射为业务错误
+func cacheAdminError(err error) *errors.BusinessError {
+	msg := err.Error()
+	switch {
+	case stderrors.Is(err, service.ErrCacheDisabled):
+		return errors.ErrServiceUnavailable.WithMessage(msg)
+	case strings.Contains(msg, "参数"):
+		return errors.ErrInvalidParameter.WithMessage(msg)
+	case strings.Contains(msg, "不存在"):
+		return errors.ErrResourceNotFound.WithMessage(msg)
+	default:
+		return errors.ErrInternalServer.WithMessage(msg)
+	}
+}
+
+// RegisterAdminRoutes 注册需要管理员权限的系统路由
+func (c *SystemController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	// 音源熔断器管理
+	breakerGroup := router.Group("/system/sources/:name/breaker")
+	{
+		breakerGroup.POST("/open", c.OpenCircuitBreaker)
+		breakerGroup.POST("/reset", c.ResetCircuitBreaker)
+	}
+
+	// 缓存条目管理
+	cacheGroup := router.Group("/system/cache")
+	{
+		cacheGroup.GET("/keys", c.ListCacheKeys)
+		cacheGroup.DELETE("/keys", c.DeleteCacheKeys)
+		cacheGroup.GET("/entry", c.GetCacheEntry)
+		cacheGroup.POST("/expire", c.ExpireCacheKeys)
+	}
+}
+
 // ClearCache 清空缓存
 // @Summary 清空缓存
 // @Description 清空所有缓存数据
@@ -0,0 +1,43 @@
+// Package model 缓存状态模型
+package model
+
+import "context"
+
+// 缓存状态，通过X-Cache响应头告知客户端
+const (
+	CacheStatusHit   = "HIT"   // 命中新鲜的缓存或未找到结果的否定缓存
+	CacheStatusStale = "STALE" // 命中过期但仍可使用的缓存，后台正在刷新
+	CacheStatusMiss  = "MISS"  // 未命中缓存，结果来自上游
+)
+
+// cacheStatusContextKey 上下文中缓存状态记录器的键
+type cacheStatusContextKey struct{}
+
+// CacheStatusRecorder 记录一次请求的缓存状态，由处理请求的goroutine使用
+type CacheStatusRecorder struct {
+	status string
+}
+
+// Status 获取记录的缓存状态，未经过缓存时返回空字符串
+func (r *CacheStatusRecorder) Status() string {
+	if r == nil {
+		return ""
+	}
+	return r.status
+}
+
+// WithCacheStatus 在上下文中放入缓存状态记录器
+func WithCacheStatus(ctx context.Context) (context.Context, *CacheStatusRecorder) {
+	recorder := &CacheStatusRecorder{}
+	return context.WithValue(ctx, cacheStatusContextKey{}, recorder), recorder
+}
+
+// RecordCacheStatus 将缓存状态写入上下文中的记录器，上下文中没有记录器时忽略
+func RecordCacheStatus(ctx context.Context, status string) {
+	if ctx == nil {
+		return
+	}
+	if recorder, ok := ctx.Value(cacheStatusContextKey{}).(*CacheStatusRecorder); ok {
+		recorder.status = status
+	}
+}
@@ -120,6 +120,78 @@ type SourcesConfigModel struct {
 	TestSources    []string      `json:"test_sources" yaml:"test_sources"`
 	Timeout        time.Duration `json:"timeout" yaml:"timeout"`
 	RetryCount     int           `json:"retry_count" yaml:"retry_count"`
+
+	// 音乐平台配置
+	Platforms       []string `json:"platforms" yaml:"platforms"`
+	DefaultPlatform string   `json:"default_platform" yaml:"default_platform"`
+
+	// 匹配策略配置
+	MatchStrategy string        `json:"match_strategy" yaml:"match_strategy"`
+	HedgeDelay    time.Duration `json:"hedge_delay" yaml:"hedge_delay"`
+
+	// 音源熔断器配置
+	CircuitBreaker CircuitBreakerConfigModel `json:"circuit_breaker" yaml:"circuit_breaker"`
+
+	// 声明式HTTP音源配置
+	HTTPSources []HTTPSourceConfigModel `json:"http_sources" yaml:"http_sources"`
+
+	// 本地曲库音源配置
+	Local LocalSourceConfigModel `json:"local" yaml:"local"`
+}
+
+// LocalSourceConfigModel 本地曲库音源配置模型
+type LocalSourceConfigModel struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled"`
+	Directory    string        `json:"directory" yaml:"directory"`
+	PublicURL    string        `json:"public_url" yaml:"public_url"`
+	Extensions   []string      `json:"extensions" yaml:"extensions"`
+	Priority     int           `json:"priority" yaml:"priority"`
+	Watch        bool          `json:"watch" yaml:"watch"`
+	ReindexDelay time.Duration `json:"reindex_delay" yaml:"reindex_delay"`
+}
+
+// HTTPSourceConfigModel 声明式HTTP音源配置模型
+type HTTPSourceConfigModel struct {
+	Name        string                              `json:"name" yaml:"name"`
+	Enabled     bool                                `json:"enabled" yaml:"enabled"`
+	Priority    int                                 `json:"priority" yaml:"priority"`
+	BaseURL     string                              `json:"base_url" yaml:"base_url"`
+	APIKey      string                              `json:"api_key" yaml:"api_key"`
+	UserAgent   string                              `json:"user_agent" yaml:"user_agent"`
+	Timeout     time.Duration                       `json:"timeout" yaml:"timeout"`
+	Headers     map[string]string                   `json:"headers" yaml:"headers"`
+	Qualities   []string                            `json:"qualities" yaml:"qualities"`
+	FixEncoding bool                                `json:"fix_encoding" yaml:"fix_encoding"`
+	Operations  map[string]HTTPSourceOperationModel `json:"operations" yaml:"operations"`
+}
+
+// HTTPSourceOperationModel 声明式HTTP音源操作配置模型
+type HTTPSourceOperationModel struct {
+	Method  string                 `json:"method" yaml:"method"`
+	URL     string                 `json:"url" yaml:"url"`
+	Headers map[string]string      `json:"headers" yaml:"headers"`
+	Body    string                 `json:"body" yaml:"body"`
+	Results string                 `json:"results" yaml:"results"`
+	Fields  map[string]string      `json:"fields" yaml:"fields"`
+	Success HTTPSourceSuccessModel `json:"success" yaml:"success"`
+}
+
+// HTTPSourceSuccessModel 声明式HTTP音源成功判定配置模型
+type HTTPSourceSuccessModel struct {
+	Status []int  `json:"status" yaml:"status"`
+	Path   string `json:"path" yaml:"path"`
+	Equals string `json:"equals" yaml:"equals"`
+}
+
+// CircuitBreakerConfigModel 音源熔断器配置模型
+type CircuitBreakerConfigModel struct {
+	Enabled              bool          `json:"enabled" yaml:"enabled"`
+	FailureThreshold     int           `json:"failure_threshold" yaml:"failure_threshold"`
+	FailureRateThreshold float64       `json:"failure_rate_threshold" yaml:"failure_rate_threshold"`
+	WindowSize           int           `json:"window_size" yaml:"window_size"`
+	MinRequests          int           `json:"min_requests" yaml:"min_requests"`
+	CoolDown             time.Duration `json:"cool_down" yaml:"cool_down"`
+	HalfOpenProbes       int           `json:"half_open_probes" yaml:"half_open_probes"`
 }
 
 // UNMServerConfigModel UnblockNeteaseMusic服务器配置模型
@@ -222,6 +294,114 @@ type SourceStatus struct {
 	ResponseTime time.Duration `json:"response_time"` // 响应时间
 	ErrorCount   int           `json:"error_count"`  // 错误次数
 	LastError    string        `json:"last_error"`   // 最后错误
+
+	Capabilities []string `json:"capabilities"`        // 音源能力
+	Qualities    []string `json:"qualities,omitempty"` // 支持的音质
+
+	CircuitBreaker *CircuitBreakerStatus `json:"circuit_breaker,omitempty"` // 熔断器状态
+}
+
+// 音源熔断器状态
+const (
+	CircuitStateClosed   = "closed"    // 关闭，正常放行请求
+	CircuitStateOpen     = "open"      // 打开，拒绝所有请求
+	CircuitStateHalfOpen = "half_open" // 半开，仅放行探测请求
+)
+
+// CircuitBreakerStatus 音源熔断器状态
+type CircuitBreakerStatus struct {
+	State               string     `json:"state"`                // 当前状态
+	Forced              bool       `json:"forced"`               // 是否被管理员强制打开
+	ConsecutiveFailures int        `json:"consecutive_failures"` // 连续失败次数
+	FailureRate         float64    `json:"failure_rate"`         // 滑动窗口内失败率
+	WindowRequests      int        `json:"window_requests"`      // 滑动窗口内请求数
+	Rejected            int64      `json:"rejected"`             // 累计拒绝请求数
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`  // 最近一次打开时间
+	RetryAt             *time.Time `json:"retry_at,omitempty"`   // 允许探测请求的时间
+}
+
+// AudioCacheEntry 音频缓存条目信息
+type AudioCacheEntry struct {
+	ID          string    `json:"id"`            // 条目ID（键的摘要），用于管理接口
+	Key         string    `json:"key"`           // 缓存键
+	Source      string    `json:"source"`        // 音源名称
+	Quality     string    `json:"quality"`       // 音质
+	ContentType string    `json:"content_type"`  // 音频类型
+	MD5         string    `json:"md5,omitempty"` // 音源提供的MD5
+	Size        int64     `json:"size"`          // 完整文件大小，未知时为-1
+	Downloaded  int64     `json:"downloaded"`    // 已下载的字节数
+	Complete    bool      `json:"complete"`      // 是否下载完成并通过校验
+	Filling     bool      `json:"filling"`       // 是否正在下载
+	Readers     int       `json:"readers"`       // 正在读取的请求数
+	CreatedAt   time.Time `json:"created_at"`    // 创建时间
+	LastAccess  time.Time `json:"last_access"`   // 最近访问时间
+}
+
+// AudioCacheStats 音频缓存统计
+type AudioCacheStats struct {
+	Entries   int   `json:"entries"`   // 条目数
+	Bytes     int64 `json:"bytes"`     // 占用磁盘字节数
+	MaxBytes  int64 `json:"max_bytes"` // 容量上限
+	Hits      int64 `json:"hits"`      // 完整命中次数
+	Partial   int64 `json:"partial"`   // 命中部分下载的次数
+	Misses    int64 `json:"misses"`    // 未命中次数
+	Evictions int64 `json:"evictions"` // 淘汰次数
+	Failures  int64 `json:"failures"`  // 下载失败或校验失败次数
+}
+
+// CacheKeySelector 缓存管理接口选择键的方式，key、pattern与namespace按此优先级只取其一
+type CacheKeySelector struct {
+	Key       string `json:"key" form:"key"`             // 单个缓存键，按原样匹配
+	Pattern   string `json:"pattern" form:"pattern"`     // 键的glob模式，如 unm:search:*
+	Namespace string `json:"namespace" form:"namespace"` // 缓存命名空间，如 match、search，等同于 unm:<namespace>:*
+}
+
+// CacheExpireRequest 重新设置缓存过期时间请求
+type CacheExpireRequest struct {
+	CacheKeySelector
+	TTL string `json:"ttl" binding:"required"` // 新的剩余过期时间，如 "1h"
+}
+
+// 缓存过期时间的处理方式
+const (
+	CacheExpireFresh   = "fresh"   // 带缓存策略的条目，过期时间与新鲜期截止时间一起改写
+	CacheExpireTTL     = "ttl"     // 普通条目，只修改过期时间
+	CacheExpireSkipped = "skipped" // 处理期间已过期或被删除
+	CacheExpireFailed  = "failed"  // 处理失败
+)
+
+// CacheExpireResult 单个缓存键的过期时间处理结果
+type CacheExpireResult struct {
+	Key        string     `json:"key"`                   // 缓存键
+	Applied    string     `json:"applied"`               // 处理方式
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`  // 新的过期时间
+	FreshUntil *time.Time `json:"fresh_until,omitempty"` // 新的新鲜期截止时间，仅带缓存策略的条目
+	Error      string     `json:"error,omitempty"`       // 失败原因
+}
+
+// CacheExpireSummary 重新设置缓存过期时间的结果
+type CacheExpireSummary struct {
+	Pattern string               `json:"pattern"` // 实际使用的glob模式或键
+	TTL     string               `json:"ttl"`     // 新的剩余过期时间
+	Matched int                  `json:"matched"` // 选中的键数
+	Updated int                  `json:"updated"` // 成功更新的键数
+	Results []*CacheExpireResult `json:"results"` // 每个键的处理结果
+}
+
+// CacheKeyList 缓存键分页列表
+type CacheKeyList struct {
+	Pattern    string     `json:"pattern"`    // 实际使用的glob模式
+	Keys       []string   `json:"keys"`       // 当前页的键，按字典序排列
+	Pagination Pagination `json:"pagination"` // 分页信息
+}
+
+// CacheEntry 缓存条目详情
+type CacheEntry struct {
+	Key       string      `json:"key"`                  // 缓存键
+	Value     interface{} `json:"value"`                // 缓存值，JSON值原样输出，其余按字符串输出
+	Size      int         `json:"size"`                 // 值的字节数
+	TTL       int64       `json:"ttl"`                  // 剩余过期时间（秒），0表示永不过期
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"` // 过期时间
 }
 
 // SystemStatus 系统状态
@@ -0,0 +1,61 @@
+// Package model 音乐匹配策略模型
+package model
+
+import (
+	"context"
+	"strings"
+)
+
+// 音乐匹配策略
+const (
+	MatchStrategySequential = "sequential" // 顺序尝试每个音源
+	MatchStrategyParallel   = "parallel"   // 所有音源并行竞速，首个有效结果胜出
+	MatchStrategyHedged     = "hedged"     // 对冲请求，按延迟依次启动下一个音源
+)
+
+// DefaultMatchStrategy 默认匹配策略
+const DefaultMatchStrategy = MatchStrategySequential
+
+// SupportedMatchStrategies 支持的匹配策略列表
+var SupportedMatchStrategies = []string{
+	MatchStrategySequential,
+	MatchStrategyParallel,
+	MatchStrategyHedged,
+}
+
+// matchStrategyContextKey 上下文中匹配策略的键
+type matchStrategyContextKey struct{}
+
+// IsValidMatchStrategy 检查匹配策略是否有效
+func IsValidMatchStrategy(strategy string) bool {
+	for _, s := range SupportedMatchStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeMatchStrategy 规范化匹配策略名称
+func NormalizeMatchStrategy(strategy string) string {
+	return strings.ToLower(strings.TrimSpace(strategy))
+}
+
+// WithMatchStrategy 将请求指定的匹配策略写入上下文
+func WithMatchStrategy(ctx context.Context, strategy string) context.Context {
+	strategy = NormalizeMatchStrategy(strategy)
+	if strategy == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, matchStrategyContextKey{}, strategy)
+}
+
+// MatchStrategyFromContext 从上下文读取匹配策略，未指定时返回空字符串
+func MatchStrategyFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if strategy, ok := ctx.Value(matchStrategyContextKey{}).(string); ok {
+			return strategy
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package model
 
 import (
 	"strings"
+	"time"
 )
 
 // MusicInfo 音乐详细信息
@@ -13,6 +14,7 @@ type MusicInfo struct {
 	Album    string `json:"album"`                       // 专辑名称
 	Duration int64  `json:"duration"`                    // 时长（秒）
 	PicURL   string `json:"pic_url"`                     // 封面图片URL
+	Platform string `json:"platform,omitempty"`          // 所属音乐平台
 }
 
 // Music 音乐信息别名（兼容性）
@@ -27,46 +29,65 @@ type Music struct {
 
 // MusicURL 音乐播放链接
 type MusicURL struct {
-	URL      string     `json:"url"`                         // 播放链接
-	ProxyURL string     `json:"proxy_url,omitempty"`         // 代理链接
-	Quality  string     `json:"quality,omitempty"`           // 音质
-	Size     int64      `json:"size,omitempty"`              // 文件大小
-	Format   string     `json:"format,omitempty"`            // 文件格式
-	Source   string     `json:"source"`                      // 音源名称
-	Info     *MusicInfo `json:"info,omitempty"`              // 音乐信息
+	URL      string     `json:"url"`                 // 播放链接
+	ProxyURL string     `json:"proxy_url,omitempty"` // 代理链接
+	Quality  string     `json:"quality,omitempty"`   // 音质
+	Size     int64      `json:"size,omitempty"`      // 文件大小
+	Format   string     `json:"format,omitempty"`    // 文件格式
+	MD5      string     `json:"md5,omitempty"`       // 文件MD5，音源提供时用于校验缓存
+	Source   string     `json:"source"`              // 音源名称
+	Info     *MusicInfo `json:"info,omitempty"`      // 音乐信息
 }
 
 // MatchRequest 音乐匹配请求
 type MatchRequest struct {
-	ID      string   `form:"id" binding:"required"`      // 音乐ID
-	Server  string   `form:"server"`                     // 指定音源，逗号分隔
-	Sources []string `json:"sources"`                    // 解析后的音源列表
+	ID            string   `form:"id" binding:"required"` // 音乐ID
+	Server        string   `form:"server"`                // 指定音源，逗号分隔
+	Platform      string   `form:"platform"`              // 音乐平台，默认netease
+	Strategy      string   `form:"strategy"`              // 匹配策略: sequential, parallel, hedged
+	BR            string   `form:"br"`                    // 期望音质，默认320
+	QualityPolicy string   `form:"quality_policy"`        // 音质协商策略: exact, at-most, at-least, best-available
+	Sources       []string `json:"sources"`               // 解析后的音源列表
 }
 
 // MatchResponse 音乐匹配响应
 type MatchResponse struct {
-	ID       string     `json:"id"`                      // 音乐ID
-	URL      string     `json:"url"`                     // 播放链接
-	ProxyURL string     `json:"proxy_url,omitempty"`     // 代理链接
-	Quality  string     `json:"quality,omitempty"`       // 音质
-	Source   string     `json:"source"`                  // 成功的音源
-	Info     *MusicInfo `json:"info,omitempty"`          // 音乐信息
+	ID               string     `json:"id"`                          // 音乐ID
+	URL              string     `json:"url"`                         // 播放链接
+	ProxyURL         string     `json:"proxy_url,omitempty"`         // 代理链接
+	DownloadURL      string     `json:"download_url,omitempty"`      // 带标签下载链接
+	Quality          string     `json:"quality,omitempty"`           // 实际音质
+	RequestedQuality string     `json:"requested_quality,omitempty"` // 期望音质
+	QualityPolicy    string     `json:"quality_policy,omitempty"`    // 音质协商策略
+	Format           string     `json:"format,omitempty"`            // 文件格式
+	Size             int64      `json:"size,omitempty"`              // 文件大小
+	MD5              string     `json:"md5,omitempty"`               // 文件MD5
+	Source           string     `json:"source"`                      // 成功的音源
+	Platform         string     `json:"platform"`                    // 音乐平台
+	Info             *MusicInfo `json:"info,omitempty"`              // 音乐信息
 }
 
 // NCMGetRequest 网易云音乐获取请求
 type NCMGetRequest struct {
-	ID string `form:"id" binding:"required"`            // 音乐ID
-	BR string `form:"br"`                               // 音质参数
+	ID            string `form:"id" binding:"required"` // 音乐ID
+	BR            string `form:"br"`                    // 音质参数
+	Strategy      string `form:"strategy"`              // 匹配策略: sequential, parallel, hedged
+	QualityPolicy string `form:"quality_policy"`        // 音质协商策略: exact, at-most, at-least, best-available
 }
 
 // NCMGetResponse 网易云音乐获取响应
 type NCMGetResponse struct {
-	ID       string     `json:"id"`                      // 音乐ID
-	BR       string     `json:"br"`                      // 音质参数
-	URL      string     `json:"url"`                     // 播放链接
-	ProxyURL string     `json:"proxy_url,omitempty"`     // 代理链接
-	Quality  string     `json:"quality,omitempty"`       // 实际音质
-	Info     *MusicInfo `json:"info,omitempty"`          // 音乐信息
+	ID            string     `json:"id"`                       // 音乐ID
+	BR            string     `json:"br"`                       // 音质参数
+	URL           string     `json:"url"`                      // 播放链接
+	ProxyURL      string     `json:"proxy_url,omitempty"`      // 代理链接
+	DownloadURL   string     `json:"download_url,omitempty"`   // 带标签下载链接
+	Quality       string     `json:"quality,omitempty"`        // 实际音质
+	QualityPolicy string     `json:"quality_policy,omitempty"` // 音质协商策略
+	Format        string     `json:"format,omitempty"`         // 文件格式
+	Size          int64      `json:"size,omitempty"`           // 文件大小
+	Source        string     `json:"source,omitempty"`         // 成功的音源
+	Info          *MusicInfo `json:"info,omitempty"`           // 音乐信息
 }
 
 // OtherGetRequest 其他音源获取请求
@@ -83,6 +104,25 @@ type OtherGetResponse struct {
 	Info     *MusicInfo `json:"info,omitempty"`          // 音乐信息
 }
 
+// StreamRequest 流式代理请求，参数与匹配请求一致
+type StreamRequest struct {
+	ID            string `form:"id" binding:"required"` // 音乐ID
+	Server        string `form:"server"`                // 指定音源，逗号分隔
+	Platform      string `form:"platform"`              // 音乐平台，默认netease
+	BR            string `form:"br"`                    // 期望音质，默认320
+	QualityPolicy string `form:"quality_policy"`        // 音质协商策略
+}
+
+// CoverRequest 封面图片代理请求
+type CoverRequest struct {
+	ID       string `form:"id" binding:"required"` // 专辑图ID
+	Source   string `form:"source"`                // 优先使用的音源
+	Platform string `form:"platform"`              // 音乐平台，默认netease
+	Size     int    `form:"size"`                  // 输出边长（像素），默认300
+	Format   string `form:"format"`                // 输出格式: jpeg, png，默认jpeg
+	Quality  int    `form:"quality"`               // JPEG质量（1-100）
+}
+
 // SearchResult 搜索结果
 type SearchResult struct {
 	ID       string `json:"id"`                         // 音乐ID
@@ -91,7 +131,101 @@ type SearchResult struct {
 	Album    string `json:"album"`                      // 专辑
 	Duration int64  `json:"duration"`                   // 时长
 	Source   string `json:"source"`                     // 来源音源
+	Platform string `json:"platform"`                   // 所属音乐平台
 	Score    float64 `json:"score"`                     // 匹配度评分
+	ScoreDetail *ScoreDetail `json:"score_detail,omitempty"` // 评分明细，仅在请求explain时返回
+}
+
+// ScoreDetail 搜索结果评分明细
+type ScoreDetail struct {
+	Query          string  `json:"query"`           // 归一化后的查询
+	Title          string  `json:"title"`           // 归一化后的标题
+	TitleScore     float64 `json:"title_score"`     // 标题相似度（0-1）
+	ArtistScore    float64 `json:"artist_score"`    // 艺术家匹配度（0-1）
+	AlbumScore     float64 `json:"album_score"`     // 专辑匹配度（0-1）
+	ExactBoost     float64 `json:"exact_boost"`     // 完全匹配加分
+	VersionPenalty float64 `json:"version_penalty"` // 版本（原版、现场、伴奏等）与查询不一致的扣分
+	SourceBoost    float64 `json:"source_boost"`    // 音源优先级加分
+	Total          float64 `json:"total"`           // 总分
+}
+
+// LyricResult 歌词结果
+type LyricResult struct {
+	Lyric  string `json:"lyric"`  // 歌词
+	TLyric string `json:"tlyric"` // 翻译歌词
+	Source string `json:"source"` // 提供歌词的音源
+}
+
+// LyricTimeline 结构化的时间轴歌词
+type LyricTimeline struct {
+	Metadata map[string]string `json:"metadata,omitempty"` // LRC元数据标签，如ti、ar、al
+	Timeline []LyricLine       `json:"timeline"`           // 按时间排序的歌词行
+	Source   string            `json:"source"`             // 提供歌词的音源
+}
+
+// LyricLine 时间轴歌词行
+type LyricLine struct {
+	Time        int64       `json:"time"`                  // 开始时间（毫秒）
+	Content     string      `json:"content"`               // 歌词内容
+	Translation string      `json:"translation,omitempty"` // 翻译
+	Words       []LyricWord `json:"words,omitempty"`       // 逐字时间，仅增强LRC歌词有
+}
+
+// LyricWord 逐字歌词中的一个字或词
+type LyricWord struct {
+	Time     int64  `json:"time"`     // 开始时间（毫秒）
+	Duration int64  `json:"duration"` // 持续时间（毫秒）
+	Text     string `json:"text"`     // 文本，保留词间空格
+}
+
+// KaraokeLyric 卡拉OK模式歌词，每行都带起止时间与逐字时间
+type KaraokeLyric struct {
+	Lines     []KaraokeLine `json:"lines"`      // 按时间排序的歌词行
+	WordTimed bool          `json:"word_timed"` // 原歌词是否带逐字时间，否则每行整体作为一个词
+	Source    string        `json:"source"`     // 提供歌词的音源
+}
+
+// KaraokeLine 卡拉OK模式歌词行
+type KaraokeLine struct {
+	Start       int64       `json:"start"`                 // 开始时间（毫秒）
+	End         int64       `json:"end"`                   // 结束时间（毫秒）
+	Content     string      `json:"content"`               // 歌词内容
+	Translation string      `json:"translation,omitempty"` // 翻译
+	Words       []LyricWord `json:"words"`                 // 逐字时间
+}
+
+// LyricExportOptions 歌词字幕导出选项
+type LyricExportOptions struct {
+	Format      string        // 字幕格式: srt, vtt, ttml
+	Translation string        // 翻译输出方式: inline, none, only
+	Offset      time.Duration // 整体时间偏移，正值推迟显示
+}
+
+// LyricExport 导出的歌词字幕
+type LyricExport struct {
+	Content     []byte // 字幕内容
+	ContentType string // 字幕Content-Type
+	Source      string // 提供歌词的音源
+}
+
+// PictureResult 专辑图结果
+type PictureResult struct {
+	URL      string `json:"url"`                 // 专辑图链接
+	ProxyURL string `json:"proxy_url,omitempty"` // 封面代理链接
+	Source   string `json:"source"`              // 提供专辑图的音源
+}
+
+// CoverImage 封面图片数据
+type CoverImage struct {
+	MIMEType string // 图片MIME类型
+	Data     []byte // 图片数据
+}
+
+// LocalTrackFile 本地曲目文件
+type LocalTrackFile struct {
+	Path     string // 文件绝对路径
+	Format   string // 音频格式
+	MIMEType string // 音频MIME类型
 }
 
 // QualityInfo 音质信息
@@ -0,0 +1,81 @@
+// Package model 音乐平台数据模型
+package model
+
+import (
+	"context"
+	"strings"
+)
+
+// 上游API支持的音乐平台
+const (
+	PlatformNetease  = "netease"  // 网易云音乐
+	PlatformTencent  = "tencent"  // QQ音乐
+	PlatformKugou    = "kugou"    // 酷狗音乐
+	PlatformKuwo     = "kuwo"     // 酷我音乐
+	PlatformMigu     = "migu"     // 咪咕音乐
+	PlatformJoox     = "joox"     // JOOX
+	PlatformTidal    = "tidal"    // Tidal
+	PlatformSpotify  = "spotify"  // Spotify
+	PlatformYtmusic  = "ytmusic"  // YouTube Music
+	PlatformQobuz    = "qobuz"    // Qobuz
+	PlatformDeezer   = "deezer"   // Deezer
+	PlatformXimalaya = "ximalaya" // 喜马拉雅
+	PlatformApple    = "apple"    // Apple Music
+)
+
+// DefaultPlatform 默认音乐平台
+const DefaultPlatform = PlatformNetease
+
+// SupportedPlatforms 上游API支持的音乐平台列表
+var SupportedPlatforms = []string{
+	PlatformNetease,
+	PlatformTencent,
+	PlatformKugou,
+	PlatformKuwo,
+	PlatformMigu,
+	PlatformJoox,
+	PlatformTidal,
+	PlatformSpotify,
+	PlatformYtmusic,
+	PlatformQobuz,
+	PlatformDeezer,
+	PlatformXimalaya,
+	PlatformApple,
+}
+
+// platformContextKey 上下文中平台参数的键
+type platformContextKey struct{}
+
+// IsSupportedPlatform 检查平台是否为上游API支持的平台
+func IsSupportedPlatform(platform string) bool {
+	for _, p := range SupportedPlatforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePlatform 规范化平台名称
+func NormalizePlatform(platform string) string {
+	return strings.ToLower(strings.TrimSpace(platform))
+}
+
+// WithPlatform 将平台参数写入上下文，供音源构建上游请求使用
+func WithPlatform(ctx context.Context, platform string) context.Context {
+	platform = NormalizePlatform(platform)
+	if platform == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, platformContextKey{}, platform)
+}
+
+// PlatformFromContext 从上下文读取平台参数，未设置时返回默认平台
+func PlatformFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if platform, ok := ctx.Value(platformContextKey{}).(string); ok && platform != "" {
+			return platform
+		}
+	}
+	return DefaultPlatform
+}
@@ -0,0 +1,160 @@
+// Package model 音质协商模型
+package model
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// 音质协商策略
+const (
+	QualityPolicyExact         = "exact"          // 只接受请求的音质
+	QualityPolicyAtMost        = "at-most"        // 接受不高于请求的音质，从请求音质逐级降低
+	QualityPolicyAtLeast       = "at-least"       // 接受不低于请求的音质，从请求音质逐级升高
+	QualityPolicyBestAvailable = "best-available" // 接受任意音质，先逐级降低再逐级升高
+)
+
+// DefaultQualityPolicy 默认音质协商策略
+const DefaultQualityPolicy = QualityPolicyAtMost
+
+// DefaultQuality 默认音质
+const DefaultQuality = "320"
+
+// SupportedQualityPolicies 支持的音质协商策略列表
+var SupportedQualityPolicies = []string{
+	QualityPolicyExact,
+	QualityPolicyAtMost,
+	QualityPolicyAtLeast,
+	QualityPolicyBestAvailable,
+}
+
+// qualityPolicyContextKey 上下文中音质协商策略的键
+type qualityPolicyContextKey struct{}
+
+// IsValidQualityPolicy 检查音质协商策略是否有效
+func IsValidQualityPolicy(policy string) bool {
+	for _, p := range SupportedQualityPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeQualityPolicy 规范化音质协商策略名称，兼容下划线写法
+func NormalizeQualityPolicy(policy string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(policy)), "_", "-")
+}
+
+// WithQualityPolicy 将请求指定的音质协商策略写入上下文
+func WithQualityPolicy(ctx context.Context, policy string) context.Context {
+	policy = NormalizeQualityPolicy(policy)
+	if policy == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, qualityPolicyContextKey{}, policy)
+}
+
+// QualityPolicyFromContext 从上下文读取音质协商策略，未指定时返回默认策略
+func QualityPolicyFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if policy, ok := ctx.Value(qualityPolicyContextKey{}).(string); ok && IsValidQualityPolicy(policy) {
+			return policy
+		}
+	}
+	return DefaultQualityPolicy
+}
+
+// QualityLadder 按策略生成依次尝试的音质列表，请求的音质总在首位
+func QualityLadder(preferred, policy string) []string {
+	index := qualityIndex(preferred)
+	if index < 0 {
+		return []string{preferred}
+	}
+
+	ladder := []string{SupportedQualities[index].BR}
+	lower := func() {
+		for i := index - 1; i >= 0; i-- {
+			ladder = append(ladder, SupportedQualities[i].BR)
+		}
+	}
+	higher := func() {
+		for i := index + 1; i < len(SupportedQualities); i++ {
+			ladder = append(ladder, SupportedQualities[i].BR)
+		}
+	}
+
+	switch policy {
+	case QualityPolicyAtMost:
+		lower()
+	case QualityPolicyAtLeast:
+		higher()
+	case QualityPolicyBestAvailable:
+		lower()
+		higher()
+	}
+	return ladder
+}
+
+// AcceptsQuality 检查实际音质是否满足策略，实际音质未知时视为满足
+func AcceptsQuality(delivered, preferred, policy string) bool {
+	got, want := qualityIndex(NormalizeQuality(delivered)), qualityIndex(preferred)
+	if got < 0 || want < 0 {
+		return true
+	}
+
+	switch policy {
+	case QualityPolicyExact:
+		return got == want
+	case QualityPolicyAtMost:
+		return got <= want
+	case QualityPolicyAtLeast:
+		return got >= want
+	default:
+		return true
+	}
+}
+
+// NormalizeQuality 将音源返回的码率归一化为支持的音质标识
+// 例如 "320000" -> "320"，"1411" -> "999"，"256" -> "192"；无法识别时返回空字符串
+func NormalizeQuality(quality string) string {
+	quality = strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(quality)), "k"))
+	if quality == "" {
+		return ""
+	}
+	if info := GetQualityInfo(quality); info != nil {
+		return info.BR
+	}
+
+	bitrate, err := strconv.Atoi(quality)
+	if err != nil || bitrate <= 0 {
+		return ""
+	}
+	// 部分音源以bps返回码率
+	if bitrate >= 32000 {
+		bitrate /= 1000
+	}
+
+	normalized := ""
+	for _, q := range SupportedQualities {
+		if bitrate >= q.Bitrate {
+			normalized = q.BR
+		}
+	}
+	// 低于最低档的码率按最低档处理
+	if normalized == "" {
+		normalized = SupportedQualities[0].BR
+	}
+	return normalized
+}
+
+// qualityIndex 获取音质在SupportedQualities中的位置，不存在时返回-1
+func qualityIndex(br string) int {
+	for i, q := range SupportedQualities {
+		if q.BR == br {
+			return i
+		}
+	}
+	return -1
+}
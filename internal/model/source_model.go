@@ -4,6 +4,15 @@ import (
 	"time"
 )
 
+// 音源能力
+const (
+	SourceCapabilityMusic   = "music"   // 获取播放链接
+	SourceCapabilitySearch  = "search"  // 搜索
+	SourceCapabilityInfo    = "info"    // 获取音乐信息
+	SourceCapabilityLyric   = "lyric"   // 获取歌词
+	SourceCapabilityPicture = "picture" // 获取专辑图
+)
+
 // SourceConfigDetail 音源详细配置
 type SourceConfigDetail struct {
 	Name        string            `json:"name" yaml:"name"`
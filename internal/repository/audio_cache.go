@@ -0,0 +1,732 @@
+// Package repository 音频磁盘缓存
+package repository
+
+import (
+	"container/list"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// 缓存文件扩展名
+const (
+	audioDataExt = ".audio"
+	audioMetaExt = ".json"
+)
+
+// audioFillChunk 下载时每次写入磁盘的块大小
+const audioFillChunk = 64 * 1024
+
+// 音频缓存错误
+var (
+	ErrAudioCacheIntegrity = errors.New("缓存音频校验失败")
+	ErrAudioCacheRemoved   = errors.New("缓存音频已被清除")
+)
+
+// AudioFetchFunc 从上游拉取音频，offset为续传起点
+// 返回的start为响应体实际对应的起始偏移（上游不支持Range时为0），total为完整文件大小，未知时为-1
+type AudioFetchFunc func(ctx context.Context, offset int64) (body io.ReadCloser, start, total int64, contentType string, err error)
+
+// AudioCacheHint 创建缓存条目时已知的曲目信息
+type AudioCacheHint struct {
+	ContentType string // 推断的音频类型，上游未返回音频类型时使用
+	MD5         string // 音源提供的文件MD5，用于完整性校验
+	Source      string // 音源名称
+	Quality     string // 音质
+}
+
+// audioMeta 持久化的条目元数据
+type audioMeta struct {
+	Key         string    `json:"key"`
+	Source      string    `json:"source"`
+	Quality     string    `json:"quality"`
+	ContentType string    `json:"content_type"`
+	MD5         string    `json:"md5,omitempty"`
+	Size        int64     `json:"size"`
+	Complete    bool      `json:"complete"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// audioEntry 缓存条目，字段由AudioCache.mu保护
+type audioEntry struct {
+	id         string
+	meta       audioMeta
+	downloaded int64
+	filling    bool
+	fillErr    error
+	removed    bool
+	readers    int
+	lastAccess time.Time
+	elem       *list.Element
+}
+
+// AudioCache 按字节数限制容量的音频磁盘LRU缓存
+// 上游音频顺序下载到磁盘，下载过程中的读取者可读取已下载部分并等待后续数据；
+// 中断的下载保留已下载部分，下次访问时以Range续传。
+type AudioCache struct {
+	dir         string
+	maxBytes    int64
+	fillTimeout time.Duration
+	logger      logger.Logger
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	entries map[string]*audioEntry // 以条目ID为键
+	lru     *list.List             // 队首为最近访问
+	used    int64
+	stats   model.AudioCacheStats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewAudioCache 创建音频磁盘缓存并加载目录中已有的条目
+func NewAudioCache(dir string, maxBytes int64, fillTimeout time.Duration, log logger.Logger) (*AudioCache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("音频缓存容量必须大于0")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建音频缓存目录失败: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &AudioCache{
+		dir:         dir,
+		maxBytes:    maxBytes,
+		fillTimeout: fillTimeout,
+		logger:      log,
+		entries:     make(map[string]*audioEntry),
+		lru:         list.New(),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	c.cond = sync.NewCond(&c.mu)
+
+	if err := c.load(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+
+	log.Info("音频缓存初始化完成",
+		logger.String("directory", dir),
+		logger.Int("entries", len(c.entries)),
+		logger.Any("bytes", c.used),
+		logger.Any("max_bytes", maxBytes),
+	)
+	return c, nil
+}
+
+// load 从磁盘加载条目，元数据缺失或损坏的文件直接删除
+func (c *AudioCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("读取音频缓存目录失败: %w", err)
+	}
+
+	loaded := make([]*audioEntry, 0)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, audioMetaExt) {
+			continue
+		}
+		id := strings.TrimSuffix(name, audioMetaExt)
+
+		data, err := os.ReadFile(filepath.Join(c.dir, name))
+		var meta audioMeta
+		if err == nil {
+			err = json.Unmarshal(data, &meta)
+		}
+		info, statErr := os.Stat(c.dataPath(id))
+		if err != nil || statErr != nil || audioEntryID(meta.Key) != id {
+			c.removeFiles(id)
+			continue
+		}
+
+		entry := &audioEntry{
+			id:         id,
+			meta:       meta,
+			downloaded: info.Size(),
+			lastAccess: info.ModTime(),
+		}
+		// 标记完成但大小不符的条目按部分下载处理，下次访问时重新校验
+		if meta.Complete && meta.Size >= 0 && info.Size() != meta.Size {
+			entry.meta.Complete = false
+		}
+		loaded = append(loaded, entry)
+	}
+
+	// 按修改时间恢复LRU顺序
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].lastAccess.After(loaded[j].lastAccess) })
+	for _, entry := range loaded {
+		entry.elem = c.lru.PushBack(entry)
+		c.entries[entry.id] = entry
+		c.used += entry.downloaded
+	}
+	return nil
+}
+
+// AudioObject 打开的缓存音频，使用完毕后须调用Close
+type AudioObject struct {
+	cache  *AudioCache
+	entry  *audioEntry
+	state  string
+	closed bool
+}
+
+// Open 打开缓存音频，未缓存或未下载完成时在后台从上游下载
+// 返回前等待获知完整文件大小，上游未返回大小时等到首批数据写入磁盘即返回，此时Size为-1；
+// 下载在获知大小或写入数据前失败时返回错误
+func (c *AudioCache) Open(ctx context.Context, key string, hint AudioCacheHint, fetch AudioFetchFunc) (*AudioObject, error) {
+	id := audioEntryID(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	state := "hit"
+	switch {
+	case !ok:
+		state = "miss"
+		c.stats.Misses++
+		entry = &audioEntry{
+			id: id,
+			meta: audioMeta{
+				Key:         key,
+				Source:      hint.Source,
+				Quality:     hint.Quality,
+				ContentType: hint.ContentType,
+				MD5:         strings.ToLower(hint.MD5),
+				Size:        -1,
+				CreatedAt:   time.Now(),
+			},
+		}
+		entry.elem = c.lru.PushFront(entry)
+		c.entries[id] = entry
+	case entry.meta.Complete:
+		c.stats.Hits++
+	default:
+		state = "partial"
+		c.stats.Partial++
+		if entry.meta.MD5 == "" && hint.MD5 != "" {
+			entry.meta.MD5 = strings.ToLower(hint.MD5)
+		}
+	}
+
+	entry.readers++
+	entry.lastAccess = time.Now()
+	c.lru.MoveToFront(entry.elem)
+
+	if !entry.meta.Complete && !entry.filling {
+		entry.filling = true
+		entry.fillErr = nil
+		go c.fill(entry, fetch)
+	}
+
+	// 等待获知文件大小或首批数据
+	c.waitLocked(ctx, func() bool {
+		return entry.meta.Size >= 0 || entry.downloaded > 0 || entry.meta.Complete || !entry.filling || entry.removed
+	})
+
+	var err error
+	switch {
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	case entry.removed:
+		err = entry.fillErr
+		if err == nil {
+			err = ErrAudioCacheRemoved
+		}
+	case entry.meta.Size < 0 && entry.downloaded == 0 && !entry.meta.Complete:
+		err = entry.fillErr
+		if err == nil {
+			err = fmt.Errorf("上游未返回音频大小")
+		}
+	}
+	if err != nil {
+		c.releaseLocked(entry)
+		return nil, err
+	}
+
+	return &AudioObject{cache: c, entry: entry, state: state}, nil
+}
+
+// OpenComplete 打开已完整缓存的音频，未缓存或未下载完成时返回false且不触发下载
+func (c *AudioCache) OpenComplete(key string) (*AudioObject, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[audioEntryID(key)]
+	if !ok || !entry.meta.Complete {
+		return nil, false
+	}
+	c.stats.Hits++
+	entry.readers++
+	entry.lastAccess = time.Now()
+	c.lru.MoveToFront(entry.elem)
+	return &AudioObject{cache: c, entry: entry, state: "hit"}, true
+}
+
+// fill 从上游下载音频到磁盘，支持从已下载位置续传
+func (c *AudioCache) fill(entry *audioEntry, fetch AudioFetchFunc) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	if c.fillTimeout > 0 {
+		ctx, cancel = context.WithTimeout(c.ctx, c.fillTimeout)
+	}
+	defer cancel()
+
+	err := c.download(ctx, entry, fetch)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.cond.Broadcast()
+
+	entry.filling = false
+	if err == nil {
+		err = c.verifyLocked(entry)
+	}
+	if errors.Is(err, ErrAudioCacheRemoved) {
+		entry.fillErr = err
+		return
+	}
+	if err != nil {
+		entry.fillErr = err
+		c.stats.Failures++
+		c.logger.Warn("音频缓存下载失败",
+			logger.String("key", entry.meta.Key),
+			logger.Any("downloaded", entry.downloaded),
+			logger.ErrorField("error", err),
+		)
+		// 校验失败的数据不可信，直接删除；网络中断保留已下载部分以便续传
+		if errors.Is(err, ErrAudioCacheIntegrity) {
+			c.removeLocked(entry)
+		}
+		c.evictLocked()
+		return
+	}
+
+	entry.meta.Complete = true
+	if err := c.writeMeta(entry); err != nil {
+		c.logger.Warn("写入音频缓存元数据失败", logger.String("key", entry.meta.Key), logger.ErrorField("error", err))
+	}
+	c.logger.Info("音频缓存下载完成",
+		logger.String("key", entry.meta.Key),
+		logger.Any("size", entry.meta.Size),
+	)
+	c.evictLocked()
+}
+
+// download 执行下载，返回前写入的数据均已计入entry.downloaded
+func (c *AudioCache) download(ctx context.Context, entry *audioEntry, fetch AudioFetchFunc) error {
+	c.mu.Lock()
+	offset := entry.downloaded
+	c.mu.Unlock()
+
+	body, start, total, contentType, err := fetch(ctx, offset)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if start != 0 && start != offset {
+		return fmt.Errorf("上游返回的起始位置 %d 与请求的 %d 不一致", start, offset)
+	}
+
+	file, err := os.OpenFile(c.dataPath(entry.id), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开缓存文件失败: %w", err)
+	}
+	defer file.Close()
+
+	c.mu.Lock()
+	if entry.removed {
+		c.mu.Unlock()
+		return ErrAudioCacheRemoved
+	}
+	// 上游忽略Range时从头下载
+	if start == 0 && entry.downloaded > 0 {
+		c.used -= entry.downloaded
+		entry.downloaded = 0
+	}
+	if total >= 0 {
+		entry.meta.Size = total
+	}
+	if contentType != "" {
+		entry.meta.ContentType = contentType
+	}
+	err = c.writeMeta(entry)
+	c.cond.Broadcast()
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("写入缓存元数据失败: %w", err)
+	}
+	if err := file.Truncate(start); err != nil {
+		return fmt.Errorf("截断缓存文件失败: %w", err)
+	}
+
+	buf := make([]byte, audioFillChunk)
+	position := start
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if _, err := file.WriteAt(buf[:n], position); err != nil {
+				return fmt.Errorf("写入缓存文件失败: %w", err)
+			}
+			position += int64(n)
+
+			c.mu.Lock()
+			if entry.removed {
+				c.mu.Unlock()
+				return ErrAudioCacheRemoved
+			}
+			entry.downloaded = position
+			c.used += int64(n)
+			c.cond.Broadcast()
+			c.mu.Unlock()
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// verifyLocked 校验下载完成的文件大小与MD5
+func (c *AudioCache) verifyLocked(entry *audioEntry) error {
+	if entry.removed {
+		return ErrAudioCacheRemoved
+	}
+	if entry.meta.Size < 0 {
+		entry.meta.Size = entry.downloaded
+	}
+	if entry.downloaded != entry.meta.Size {
+		return fmt.Errorf("%w: 已下载 %d 字节，期望 %d 字节", ErrAudioCacheIntegrity, entry.downloaded, entry.meta.Size)
+	}
+	if entry.meta.MD5 == "" {
+		return nil
+	}
+
+	file, err := os.Open(c.dataPath(entry.id))
+	if err != nil {
+		return fmt.Errorf("打开缓存文件失败: %w", err)
+	}
+	defer file.Close()
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("读取缓存文件失败: %w", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != entry.meta.MD5 {
+		return fmt.Errorf("%w: MD5为 %s，期望 %s", ErrAudioCacheIntegrity, sum, entry.meta.MD5)
+	}
+	return nil
+}
+
+// evictLocked 淘汰最久未访问且未被使用的条目，直到占用不超过上限
+func (c *AudioCache) evictLocked() {
+	for elem := c.lru.Back(); elem != nil && c.used > c.maxBytes; {
+		entry := elem.Value.(*audioEntry)
+		elem = elem.Prev()
+		if entry.readers > 0 || entry.filling {
+			continue
+		}
+		c.removeLocked(entry)
+		c.stats.Evictions++
+		c.logger.Debug("淘汰音频缓存", logger.String("key", entry.meta.Key))
+	}
+}
+
+// waitLocked 等待下载进度直到ready返回true或ctx结束，调用方须持有c.mu
+func (c *AudioCache) waitLocked(ctx context.Context, ready func() bool) {
+	if ready() || ctx.Err() != nil {
+		return
+	}
+	stop := context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		c.cond.Broadcast()
+		c.mu.Unlock()
+	})
+	defer stop()
+	for !ready() && ctx.Err() == nil {
+		c.cond.Wait()
+	}
+}
+
+// releaseLocked 释放一次条目引用
+func (c *AudioCache) releaseLocked(entry *audioEntry) {
+	entry.readers--
+	if entry.readers == 0 {
+		c.evictLocked()
+	}
+}
+
+// removeLocked 从索引移除条目并删除文件，已打开的读取者不受影响
+func (c *AudioCache) removeLocked(entry *audioEntry) {
+	if entry.removed {
+		return
+	}
+	entry.removed = true
+	c.lru.Remove(entry.elem)
+	delete(c.entries, entry.id)
+	c.used -= entry.downloaded
+	c.removeFiles(entry.id)
+	c.cond.Broadcast()
+}
+
+// Purge 清除指定条目，返回条目是否存在
+func (c *AudioCache) Purge(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	c.removeLocked(entry)
+	return true
+}
+
+// PurgeAll 清除全部条目，返回清除的条目数
+func (c *AudioCache) PurgeAll() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := len(c.entries)
+	for _, entry := range c.entries {
+		c.removeLocked(entry)
+	}
+	return count
+}
+
+// Entries 按最近访问顺序列出条目
+func (c *AudioCache) Entries() []model.AudioCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	infos := make([]model.AudioCacheEntry, 0, len(c.entries))
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		infos = append(infos, elem.Value.(*audioEntry).info())
+	}
+	return infos
+}
+
+// Entry 获取指定条目信息
+func (c *AudioCache) Entry(id string) (model.AudioCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return model.AudioCacheEntry{}, false
+	}
+	return entry.info(), true
+}
+
+// Stats 获取统计信息
+func (c *AudioCache) Stats() model.AudioCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Bytes = c.used
+	stats.MaxBytes = c.maxBytes
+	return stats
+}
+
+// Close 停止所有后台下载，已下载部分保留在磁盘
+func (c *AudioCache) Close() error {
+	c.cancel()
+	return nil
+}
+
+// info 生成条目信息
+func (e *audioEntry) info() model.AudioCacheEntry {
+	return model.AudioCacheEntry{
+		ID:          e.id,
+		Key:         e.meta.Key,
+		Source:      e.meta.Source,
+		Quality:     e.meta.Quality,
+		ContentType: e.meta.ContentType,
+		MD5:         e.meta.MD5,
+		Size:        e.meta.Size,
+		Downloaded:  e.downloaded,
+		Complete:    e.meta.Complete,
+		Filling:     e.filling,
+		Readers:     e.readers,
+		CreatedAt:   e.meta.CreatedAt,
+		LastAccess:  e.lastAccess,
+	}
+}
+
+// writeMeta 写入条目元数据，先写临时文件再重命名避免写入中断导致损坏
+func (c *AudioCache) writeMeta(entry *audioEntry) error {
+	data, err := json.Marshal(entry.meta)
+	if err != nil {
+		return err
+	}
+	tmp := c.metaPath(entry.id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.metaPath(entry.id))
+}
+
+// removeFiles 删除条目文件
+func (c *AudioCache) removeFiles(id string) {
+	os.Remove(c.dataPath(id))
+	os.Remove(c.metaPath(id))
+}
+
+// dataPath 音频数据文件路径
+func (c *AudioCache) dataPath(id string) string {
+	return filepath.Join(c.dir, id+audioDataExt)
+}
+
+// metaPath 元数据文件路径
+func (c *AudioCache) metaPath(id string) string {
+	return filepath.Join(c.dir, id+audioMetaExt)
+}
+
+// audioEntryID 由缓存键生成条目ID，同时用作文件名
+func audioEntryID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ID 条目ID
+func (o *AudioObject) ID() string {
+	return o.entry.id
+}
+
+// State 打开时的缓存状态：hit、partial或miss
+func (o *AudioObject) State() string {
+	return o.state
+}
+
+// Size 完整文件大小，上游未返回大小且尚未下载完成时为-1
+func (o *AudioObject) Size() int64 {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	return o.entry.meta.Size
+}
+
+// ContentType 音频类型
+func (o *AudioObject) ContentType() string {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	return o.entry.meta.ContentType
+}
+
+// MD5 音源提供的文件MD5
+func (o *AudioObject) MD5() string {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	return o.entry.meta.MD5
+}
+
+// Available 当前可直接读取的字节数
+func (o *AudioObject) Available() int64 {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	return o.entry.downloaded
+}
+
+// NewReader 创建从offset开始读取length字节的读取器，length为负数时读取到下载完成为止
+// 数据未下载到时等待下载进度，读取器关闭时一并关闭AudioObject
+func (o *AudioObject) NewReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	file, err := os.Open(o.cache.dataPath(o.entry.id))
+	if err != nil {
+		o.Close()
+		return nil, fmt.Errorf("打开缓存文件失败: %w", err)
+	}
+	end := int64(-1)
+	if length >= 0 {
+		end = offset + length
+	}
+	return &audioReader{ctx: ctx, object: o, file: file, position: offset, end: end}, nil
+}
+
+// Close 释放条目引用
+func (o *AudioObject) Close() error {
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	o.cache.releaseLocked(o.entry)
+	return nil
+}
+
+// audioReader 读取缓存文件的指定范围，读到尚未下载的位置时等待
+type audioReader struct {
+	ctx      context.Context
+	object   *AudioObject
+	file     *os.File
+	position int64
+	end      int64 // 为-1时读取到下载完成为止
+}
+
+// Read 实现io.Reader
+func (r *audioReader) Read(p []byte) (int, error) {
+	if r.end >= 0 && r.position >= r.end {
+		return 0, io.EOF
+	}
+
+	cache, entry := r.object.cache, r.object.entry
+	cache.mu.Lock()
+	cache.waitLocked(r.ctx, func() bool { return r.position < entry.downloaded || !entry.filling })
+	available := entry.downloaded
+	complete := entry.meta.Complete
+	fillErr := entry.fillErr
+	cache.mu.Unlock()
+
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.position >= available {
+		if r.end < 0 && complete {
+			return 0, io.EOF
+		}
+		if fillErr == nil {
+			fillErr = io.ErrUnexpectedEOF
+		}
+		return 0, fillErr
+	}
+
+	limit := min(int64(len(p)), available-r.position)
+	if r.end >= 0 {
+		limit = min(limit, r.end-r.position)
+	}
+	n, err := r.file.ReadAt(p[:limit], r.position)
+	r.position += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Close 关闭文件并释放条目引用
+func (r *audioReader) Close() error {
+	err := r.file.Close()
+	r.object.Close()
+	return err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// newTestAudioCache 创建临时目录中的音频缓存，测试结束时关闭
+func newTestAudioCache(t *testing.T) *AudioCache {
+	t.Helper()
+	cache, err := NewAudioCache(t.TempDir(), 1<<20, time.Minute, testLogger(t))
+	if err != nil {
+		t.Fatalf("创建音频缓存失败: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+func TestAudioCacheStreamsUnknownSize(t *testing.T) {
+	cache := newTestAudioCache(t)
+	ctx := context.Background()
+
+	// 上游不返回大小，数据分批到达
+	upstream, feed := io.Pipe()
+	fetch := func(ctx context.Context, offset int64) (io.ReadCloser, int64, int64, string, error) {
+		return upstream, 0, -1, "audio/mpeg", nil
+	}
+
+	first := bytes.Repeat([]byte("a"), 1000)
+	second := bytes.Repeat([]byte("b"), 500)
+	go feed.Write(first)
+
+	object, err := cache.Open(ctx, "unknown", AudioCacheHint{}, fetch)
+	if err != nil {
+		t.Fatalf("Open失败: %v", err)
+	}
+	if size := object.Size(); size != -1 {
+		t.Fatalf("下载完成前 Size = %d，期望 -1", size)
+	}
+
+	reader, err := object.NewReader(ctx, 0, -1)
+	if err != nil {
+		t.Fatalf("NewReader失败: %v", err)
+	}
+	defer reader.Close()
+
+	got := make([]byte, len(first))
+	if _, err := io.ReadFull(reader, got); err != nil || !bytes.Equal(got, first) {
+		t.Fatalf("读取首批数据失败: %v", err)
+	}
+
+	go func() {
+		feed.Write(second)
+		feed.Close()
+	}()
+	rest, err := io.ReadAll(reader)
+	if err != nil || !bytes.Equal(rest, second) {
+		t.Fatalf("读取剩余数据 = %d 字节, %v，期望 %d 字节", len(rest), err, len(second))
+	}
+	if size := object.Size(); size != int64(len(first)+len(second)) {
+		t.Errorf("下载完成后 Size = %d", size)
+	}
+}
+
+func TestAudioCacheUnknownSizeFailsBeforeData(t *testing.T) {
+	cache := newTestAudioCache(t)
+
+	fetch := func(ctx context.Context, offset int64) (io.ReadCloser, int64, int64, string, error) {
+		return io.NopCloser(&failingReader{}), 0, -1, "", nil
+	}
+	if _, err := cache.Open(context.Background(), "broken", AudioCacheHint{}, fetch); err == nil {
+		t.Fatal("写入数据前下载失败时Open应返回错误")
+	}
+}
+
+func TestAudioCacheOpenCompleteDoesNotFill(t *testing.T) {
+	cache := newTestAudioCache(t)
+	ctx := context.Background()
+
+	if _, ok := cache.OpenComplete("track"); ok {
+		t.Fatal("未缓存的条目不应打开")
+	}
+	if entries := cache.Entries(); len(entries) != 0 {
+		t.Fatalf("OpenComplete不应创建条目或触发下载: %v", entries)
+	}
+
+	data := []byte("audio")
+	fetch := func(ctx context.Context, offset int64) (io.ReadCloser, int64, int64, string, error) {
+		return io.NopCloser(bytes.NewReader(data)), 0, int64(len(data)), "audio/mpeg", nil
+	}
+	object, err := cache.Open(ctx, "track", AudioCacheHint{}, fetch)
+	if err != nil {
+		t.Fatalf("Open失败: %v", err)
+	}
+	reader, _ := object.NewReader(ctx, 0, object.Size())
+	io.ReadAll(reader)
+	reader.Close()
+	waitFor(t, "下载完成", func() bool {
+		entry, ok := cache.Entry(audioEntryID("track"))
+		return ok && entry.Complete
+	})
+
+	object, ok := cache.OpenComplete("track")
+	if !ok {
+		t.Fatal("已完整缓存的条目应能打开")
+	}
+	defer object.Close()
+	if object.State() != "hit" || object.Size() != int64(len(data)) {
+		t.Errorf("State = %s, Size = %d", object.State(), object.Size())
+	}
+}
+
+// failingReader 总是返回错误的读取器
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
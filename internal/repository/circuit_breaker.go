@@ -0,0 +1,424 @@
+// Package repository 音源熔断器实现
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/repository/sources"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/metrics"
+)
+
+// ErrCircuitOpen 熔断器打开时拒绝请求返回的错误
+var ErrCircuitOpen = errors.New("音源熔断器已打开，暂不可用")
+
+// CircuitBreaker 音源熔断器
+// 关闭状态下统计滑动窗口内的失败率与连续失败次数，超过阈值后打开；
+// 打开状态下直接拒绝请求，冷却时间结束后进入半开状态放行探测请求；
+// 探测全部成功则关闭，任一探测失败则重新打开。
+type CircuitBreaker struct {
+	name   string
+	config model.CircuitBreakerConfigModel
+	logger logger.Logger
+
+	mu                  sync.Mutex
+	state               string
+	forced              bool      // 管理员强制打开，不会自动进入半开状态
+	window              []bool    // 滑动窗口，true表示失败
+	windowPos           int       // 下一次写入的位置
+	windowCount         int       // 窗口内已记录的请求数
+	windowFailures      int       // 窗口内失败次数
+	consecutiveFailures int       // 连续失败次数
+	openedAt            time.Time // 最近一次打开时间
+	probesInFlight      int       // 半开状态下进行中的探测请求数
+	probeSuccesses      int       // 半开状态下成功的探测请求数
+	rejected            int64     // 累计拒绝请求数
+}
+
+// NewCircuitBreaker 创建音源熔断器
+func NewCircuitBreaker(name string, config model.CircuitBreakerConfigModel, log logger.Logger) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = 0.5
+	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = 20
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+
+	cb := &CircuitBreaker{
+		name:   name,
+		config: config,
+		logger: log,
+		state:  model.CircuitStateClosed,
+		window: make([]bool, config.WindowSize),
+	}
+	metrics.SetCircuitState(name, circuitStateValue(cb.state))
+
+	return cb
+}
+
+// Name 获取熔断器对应的音源名称
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// State 获取熔断器当前状态
+func (cb *CircuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Ready 检查熔断器当前是否会放行请求，不占用探测名额
+func (cb *CircuitBreaker) Ready() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case model.CircuitStateOpen:
+		return !cb.forced && !time.Now().Before(cb.openedAt.Add(cb.config.CoolDown))
+	case model.CircuitStateHalfOpen:
+		return cb.probesInFlight < cb.config.HalfOpenProbes
+	default:
+		return true
+	}
+}
+
+// Allow 申请执行一次请求，熔断器拒绝时返回ErrCircuitOpen
+// 调用方获准执行后必须调用Record报告结果
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == model.CircuitStateOpen {
+		if cb.forced || time.Now().Before(cb.openedAt.Add(cb.config.CoolDown)) {
+			return cb.reject()
+		}
+		cb.setState(model.CircuitStateHalfOpen)
+	}
+
+	if cb.state == model.CircuitStateHalfOpen {
+		if cb.probesInFlight >= cb.config.HalfOpenProbes {
+			return cb.reject()
+		}
+		cb.probesInFlight++
+	}
+
+	return nil
+}
+
+// Record 报告请求结果，调用方上下文被取消导致的错误不计入统计
+func (cb *CircuitBreaker) Record(ctx context.Context, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		// 请求被调用方取消（如竞速落败），释放探测名额但不影响统计
+		if cb.state == model.CircuitStateHalfOpen && cb.probesInFlight > 0 {
+			cb.probesInFlight--
+		}
+		return
+	}
+
+	switch cb.state {
+	case model.CircuitStateClosed:
+		cb.push(err != nil)
+		if err == nil {
+			cb.consecutiveFailures = 0
+			return
+		}
+		cb.consecutiveFailures++
+		if cb.shouldTrip() {
+			cb.trip(err)
+		}
+
+	case model.CircuitStateHalfOpen:
+		if cb.probesInFlight > 0 {
+			cb.probesInFlight--
+		}
+		if err != nil {
+			cb.consecutiveFailures++
+			cb.trip(err)
+			return
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.config.HalfOpenProbes {
+			cb.reset()
+			cb.logger.Info("音源熔断器探测成功，已恢复",
+				logger.String("source", cb.name),
+			)
+		}
+	}
+}
+
+// ForceOpen 强制打开熔断器，直到调用Reset才会恢复
+func (cb *CircuitBreaker) ForceOpen() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.forced = true
+	cb.openedAt = time.Now()
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+	cb.setState(model.CircuitStateOpen)
+}
+
+// Reset 重置熔断器为关闭状态并清空统计
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.reset()
+}
+
+// Status 获取熔断器状态快照
+func (cb *CircuitBreaker) Status() *model.CircuitBreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := &model.CircuitBreakerStatus{
+		State:               cb.state,
+		Forced:              cb.forced,
+		ConsecutiveFailures: cb.consecutiveFailures,
+		WindowRequests:      cb.windowCount,
+		Rejected:            cb.rejected,
+	}
+	if cb.windowCount > 0 {
+		status.FailureRate = float64(cb.windowFailures) / float64(cb.windowCount)
+	}
+	if !cb.openedAt.IsZero() {
+		openedAt := cb.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if cb.state == model.CircuitStateOpen && !cb.forced {
+		retryAt := cb.openedAt.Add(cb.config.CoolDown)
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
+
+// push 将请求结果写入滑动窗口
+func (cb *CircuitBreaker) push(failed bool) {
+	if cb.windowCount == len(cb.window) {
+		if cb.window[cb.windowPos] {
+			cb.windowFailures--
+		}
+	} else {
+		cb.windowCount++
+	}
+
+	cb.window[cb.windowPos] = failed
+	if failed {
+		cb.windowFailures++
+	}
+	cb.windowPos = (cb.windowPos + 1) % len(cb.window)
+}
+
+// shouldTrip 检查是否达到打开熔断器的阈值
+func (cb *CircuitBreaker) shouldTrip() bool {
+	if cb.consecutiveFailures >= cb.config.FailureThreshold {
+		return true
+	}
+	if cb.windowCount < cb.config.MinRequests {
+		return false
+	}
+	return float64(cb.windowFailures)/float64(cb.windowCount) >= cb.config.FailureRateThreshold
+}
+
+// trip 打开熔断器
+func (cb *CircuitBreaker) trip(err error) {
+	cb.openedAt = time.Now()
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+	cb.setState(model.CircuitStateOpen)
+
+	cb.logger.Warn("音源熔断器已打开",
+		logger.String("source", cb.name),
+		logger.Int("consecutive_failures", cb.consecutiveFailures),
+		logger.Int("window_failures", cb.windowFailures),
+		logger.Int("window_requests", cb.windowCount),
+		logger.Duration("cool_down", cb.config.CoolDown),
+		logger.ErrorField("error", err),
+	)
+}
+
+// reset 清空统计并关闭熔断器，调用方需持有锁
+func (cb *CircuitBreaker) reset() {
+	for i := range cb.window {
+		cb.window[i] = false
+	}
+	cb.windowPos = 0
+	cb.windowCount = 0
+	cb.windowFailures = 0
+	cb.consecutiveFailures = 0
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+	cb.forced = false
+	cb.setState(model.CircuitStateClosed)
+}
+
+// reject 记录被拒绝的请求
+func (cb *CircuitBreaker) reject() error {
+	cb.rejected++
+	metrics.RecordCircuitRejection(cb.name)
+	return fmt.Errorf("%s: %w", cb.name, ErrCircuitOpen)
+}
+
+// setState 切换熔断器状态并更新指标
+func (cb *CircuitBreaker) setState(state string) {
+	if cb.state == state {
+		return
+	}
+
+	cb.logger.Debug("音源熔断器状态变更",
+		logger.String("source", cb.name),
+		logger.String("from", cb.state),
+		logger.String("to", state),
+	)
+	cb.state = state
+	metrics.SetCircuitState(cb.name, circuitStateValue(state))
+}
+
+// circuitStateValue 将熔断器状态转换为指标值
+func circuitStateValue(state string) float64 {
+	switch state {
+	case model.CircuitStateOpen:
+		return 2
+	case model.CircuitStateHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CircuitBreakerSource 带熔断保护的音源包装器
+// 音源请求（含歌词、专辑图能力）经过熔断器，其余方法直接委托给被包装的音源
+// 判断音源能力时应使用AsLyricProvider等函数，而不是直接对包装器做类型断言
+type CircuitBreakerSource struct {
+	MusicSource
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerSource 创建带熔断保护的音源
+func NewCircuitBreakerSource(source MusicSource, breaker *CircuitBreaker) *CircuitBreakerSource {
+	return &CircuitBreakerSource{
+		MusicSource: source,
+		breaker:     breaker,
+	}
+}
+
+// Breaker 获取音源的熔断器
+func (s *CircuitBreakerSource) Breaker() *CircuitBreaker {
+	return s.breaker
+}
+
+// Unwrap 获取被包装的音源
+func (s *CircuitBreakerSource) Unwrap() MusicSource {
+	return s.MusicSource
+}
+
+// GetMusic 根据音乐ID获取播放链接
+func (s *CircuitBreakerSource) GetMusic(ctx context.Context, id string, quality string) (*model.MusicURL, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	musicURL, err := s.MusicSource.GetMusic(ctx, id, quality)
+	// 音源明确答复没有播放链接或不支持该音质说明音源可用，不计为失败
+	if errors.Is(err, sources.ErrNoMusicURL) || errors.Is(err, ErrQualityMismatch) {
+		s.breaker.Record(ctx, nil)
+	} else {
+		s.breaker.Record(ctx, err)
+	}
+	return musicURL, err
+}
+
+// SearchMusic 根据歌曲名搜索音乐
+func (s *CircuitBreakerSource) SearchMusic(ctx context.Context, keyword string) ([]*model.SearchResult, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	results, err := s.MusicSource.SearchMusic(ctx, keyword)
+	s.breaker.Record(ctx, err)
+	return results, err
+}
+
+// GetMusicInfo 获取音乐详细信息
+func (s *CircuitBreakerSource) GetMusicInfo(ctx context.Context, id string) (*model.MusicInfo, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	info, err := s.MusicSource.GetMusicInfo(ctx, id)
+	s.breaker.Record(ctx, err)
+	return info, err
+}
+
+// GetLyric 获取歌词，被包装的音源不支持时返回错误
+func (s *CircuitBreakerSource) GetLyric(ctx context.Context, lyricID string) (string, string, error) {
+	provider, ok := s.MusicSource.(LyricProvider)
+	if !ok {
+		return "", "", fmt.Errorf("音源 %s 不支持获取歌词", s.GetName())
+	}
+	if err := s.breaker.Allow(); err != nil {
+		return "", "", err
+	}
+	lyric, tlyric, err := provider.GetLyric(ctx, lyricID)
+	s.breaker.Record(ctx, err)
+	return lyric, tlyric, err
+}
+
+// GetPicture 获取专辑图，被包装的音源不支持时返回错误
+func (s *CircuitBreakerSource) GetPicture(ctx context.Context, picID string, size string) (string, error) {
+	provider, ok := s.MusicSource.(PictureProvider)
+	if !ok {
+		return "", fmt.Errorf("音源 %s 不支持获取专辑图", s.GetName())
+	}
+	if err := s.breaker.Allow(); err != nil {
+		return "", err
+	}
+	picURL, err := provider.GetPicture(ctx, picID, size)
+	s.breaker.Record(ctx, err)
+	return picURL, err
+}
+
+// GetSupportedQualities 获取被包装音源支持的音质列表
+func (s *CircuitBreakerSource) GetSupportedQualities() []string {
+	if lister, ok := s.MusicSource.(QualityLister); ok {
+		return lister.GetSupportedQualities()
+	}
+	return nil
+}
+
+// UnwrapSource 逐层解除包装，获取原始音源实现
+func UnwrapSource(source MusicSource) MusicSource {
+	for {
+		wrapper, ok := source.(interface{ Unwrap() MusicSource })
+		if !ok {
+			return source
+		}
+		source = wrapper.Unwrap()
+	}
+}
+
+// breakerOf 获取音源的熔断器，未启用熔断时返回nil
+func breakerOf(source MusicSource) *CircuitBreaker {
+	if wrapped, ok := source.(*CircuitBreakerSource); ok {
+		return wrapped.breaker
+	}
+	return nil
+}
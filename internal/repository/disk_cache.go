@@ -0,0 +1,760 @@
+// Package repository 磁盘缓存仓库实现
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/resp"
+)
+
+// 日志文件
+const (
+	diskCacheLogName     = "cache.log"
+	diskCacheTmpName     = "cache.log.tmp"
+	diskCacheCorruptName = "cache.log.corrupt"
+	diskCacheMagic       = "UNMCACHE1\n"
+)
+
+// 日志记录类型
+const (
+	diskOpSet    byte = 1
+	diskOpDelete byte = 2
+)
+
+// diskRecordHeaderSize 记录头长度：CRC32(4) + 类型(1) + 过期时间(8) + 键长度(4) + 值长度(4)
+// CRC覆盖记录头其余部分、键与值，写入中途崩溃留下的残缺记录在启动时被截断
+const diskRecordHeaderSize = 21
+
+// diskCompactMinGarbage 触发压缩的最小无效数据量，避免日志较小时频繁压缩
+const diskCompactMinGarbage = 4 << 20
+
+// maxDiskKeyLength 键的最大长度
+const maxDiskKeyLength = 64 << 10
+
+// ErrDiskCacheClosed 磁盘缓存已关闭
+var ErrDiskCacheClosed = errors.New("磁盘缓存已关闭")
+
+// diskEntry 索引中的条目，值保留在磁盘上，读取时按偏移读出
+type diskEntry struct {
+	offset    int64 // 记录在日志中的起始位置
+	size      int64 // 记录总长度
+	valueLen  int   // 值长度
+	expiresAt int64 // 过期时间（Unix纳秒），0表示永不过期
+}
+
+// expired 检查是否过期
+func (e *diskEntry) expired(now int64) bool {
+	return e.expiresAt != 0 && now >= e.expiresAt
+}
+
+// valueOffset 值在日志中的位置
+func (e *diskEntry) valueOffset() int64 {
+	return e.offset + e.size - int64(e.valueLen)
+}
+
+// diskCacheRepository 磁盘缓存仓库实现，重启后缓存仍然有效
+// 所有写入以记录的形式追加到单个日志文件，内存中只保留键到记录位置的索引；
+// 无效记录超过有效数据量时在后台重写日志，新日志写完并同步后原子替换旧日志。
+// 同一目录只能由一个进程使用。
+type diskCacheRepository struct {
+	dir        string
+	maxBytes   int64
+	syncWrites bool
+	interval   time.Duration
+	logger     logger.Logger
+
+	mu        sync.RWMutex
+	file      *os.File
+	index     map[string]*diskEntry
+	fileSize  int64 // 日志文件长度
+	liveBytes int64 // 有效记录的总长度
+
+	compactCh chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+
+	// 统计信息
+	stats struct {
+		hits        atomic.Int64
+		misses      atomic.Int64
+		sets        atomic.Int64
+		deletes     atomic.Int64
+		evictions   atomic.Int64
+		errors      atomic.Int64
+		compactions atomic.Int64
+	}
+}
+
+// NewDiskCacheRepository 创建磁盘缓存仓库，载入目录中已有的日志
+func NewDiskCacheRepository(cfg *config.DiskCacheConfig, log logger.Logger) (CacheRepository, error) {
+	return newDiskCacheRepository(cfg, log)
+}
+
+// newDiskCacheRepository 创建磁盘缓存仓库
+func newDiskCacheRepository(cfg *config.DiskCacheConfig, log logger.Logger) (*diskCacheRepository, error) {
+	maxBytes, err := config.ParseByteSize(cfg.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("解析磁盘缓存容量失败: %w", err)
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("磁盘缓存容量必须大于0")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("创建磁盘缓存目录失败: %w", err)
+	}
+
+	r := &diskCacheRepository{
+		dir:        cfg.Directory,
+		maxBytes:   maxBytes,
+		syncWrites: cfg.SyncWrites,
+		interval:   cfg.CompactionInterval,
+		logger:     log,
+		index:      make(map[string]*diskEntry),
+		compactCh:  make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	log.Info("磁盘缓存初始化完成",
+		logger.String("directory", r.dir),
+		logger.Int("entries", len(r.index)),
+		logger.Any("live_bytes", r.liveBytes),
+		logger.Any("file_size", r.fileSize),
+	)
+
+	go r.maintain()
+	return r, nil
+}
+
+// open 打开日志并重建索引，无法识别的日志改名保留后重新创建
+func (r *diskCacheRepository) open() error {
+	// 压缩中途崩溃残留的临时文件
+	os.Remove(filepath.Join(r.dir, diskCacheTmpName))
+
+	path := filepath.Join(r.dir, diskCacheLogName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开磁盘缓存日志失败: %w", err)
+	}
+
+	err = r.replay(file)
+	if errors.Is(err, errDiskLogFormat) {
+		file.Close()
+		r.logger.Warn("磁盘缓存日志格式无法识别，重新创建",
+			logger.String("path", path),
+		)
+		if err := os.Rename(path, filepath.Join(r.dir, diskCacheCorruptName)); err != nil {
+			return fmt.Errorf("移除无法识别的磁盘缓存日志失败: %w", err)
+		}
+		if file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+			return fmt.Errorf("打开磁盘缓存日志失败: %w", err)
+		}
+		err = r.replay(file)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	return nil
+}
+
+// errDiskLogFormat 日志文件头不匹配
+var errDiskLogFormat = errors.New("磁盘缓存日志格式无法识别")
+
+// replay 顺序读取日志重建索引，遇到残缺或校验失败的记录时截断其后的内容
+func (r *diskCacheRepository) replay(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("读取磁盘缓存日志失败: %w", err)
+	}
+	size := info.Size()
+	if size == 0 {
+		if _, err := file.WriteAt([]byte(diskCacheMagic), 0); err != nil {
+			return fmt.Errorf("初始化磁盘缓存日志失败: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("初始化磁盘缓存日志失败: %w", err)
+		}
+		r.fileSize = int64(len(diskCacheMagic))
+		return nil
+	}
+
+	magic := make([]byte, len(diskCacheMagic))
+	if _, err := file.ReadAt(magic, 0); err != nil || string(magic) != diskCacheMagic {
+		return errDiskLogFormat
+	}
+
+	offset := int64(len(diskCacheMagic))
+	reader := bufio.NewReaderSize(io.NewSectionReader(file, offset, size-offset), 256<<10)
+	header := make([]byte, diskRecordHeaderSize)
+	now := time.Now().UnixNano()
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			break
+		}
+		op := header[4]
+		expiresAt := int64(binary.LittleEndian.Uint64(header[5:13]))
+		keyLen := int64(binary.LittleEndian.Uint32(header[13:17]))
+		valueLen := int64(binary.LittleEndian.Uint32(header[17:21]))
+		recordSize := diskRecordHeaderSize + keyLen + valueLen
+		if (op != diskOpSet && op != diskOpDelete) || keyLen == 0 || keyLen > maxDiskKeyLength || offset+recordSize > size {
+			break
+		}
+
+		body := make([]byte, keyLen+valueLen)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			break
+		}
+		checksum := crc32.NewIEEE()
+		checksum.Write(header[4:])
+		checksum.Write(body)
+		if checksum.Sum32() != binary.LittleEndian.Uint32(header[0:4]) {
+			break
+		}
+
+		key := string(body[:keyLen])
+		if old, ok := r.index[key]; ok {
+			r.liveBytes -= old.size
+			delete(r.index, key)
+		}
+		entry := &diskEntry{offset: offset, size: recordSize, valueLen: int(valueLen), expiresAt: expiresAt}
+		if op == diskOpSet && !entry.expired(now) {
+			r.index[key] = entry
+			r.liveBytes += recordSize
+		}
+		offset += recordSize
+	}
+
+	if offset < size {
+		r.logger.Warn("磁盘缓存日志尾部损坏，已截断",
+			logger.String("directory", r.dir),
+			logger.Any("valid_bytes", offset),
+			logger.Any("dropped_bytes", size-offset),
+		)
+		if err := file.Truncate(offset); err != nil {
+			return fmt.Errorf("截断磁盘缓存日志失败: %w", err)
+		}
+	}
+	r.fileSize = offset
+	return nil
+}
+
+// Get 获取缓存，值以字符串返回
+func (r *diskCacheRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	value, _, err := r.get(key)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// get 获取缓存值与过期时间
+func (r *diskCacheRepository) get(key string) (string, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.file == nil {
+		return "", 0, ErrDiskCacheClosed
+	}
+	entry, ok := r.index[key]
+	if !ok || entry.expired(time.Now().UnixNano()) {
+		r.stats.misses.Add(1)
+		return "", 0, fmt.Errorf("缓存键不存在: %s", key)
+	}
+
+	value := make([]byte, entry.valueLen)
+	if _, err := r.file.ReadAt(value, entry.valueOffset()); err != nil {
+		r.stats.misses.Add(1)
+		r.stats.errors.Add(1)
+		return "", 0, fmt.Errorf("读取磁盘缓存失败: %w", err)
+	}
+
+	r.stats.hits.Add(1)
+	return string(value), entry.expiresAt, nil
+}
+
+// Set 设置缓存，非字符串值序列化为JSON后存储，ttl不大于0时永不过期
+func (r *diskCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if key == "" || len(key) > maxDiskKeyLength {
+		return fmt.Errorf("缓存键长度无效: %d", len(key))
+	}
+	data, err := cacheValueBytes(value)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > math.MaxUint32 {
+		return fmt.Errorf("缓存值过大: %s", key)
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	record := encodeDiskRecord(diskOpSet, key, data, expiresAt)
+	size := int64(len(record))
+	if size > r.maxBytes {
+		return fmt.Errorf("缓存值超过磁盘缓存容量: %s", key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ErrDiskCacheClosed
+	}
+	var oldSize int64
+	if old, ok := r.index[key]; ok {
+		oldSize = old.size
+	}
+	if need := r.liveBytes - oldSize + size - r.maxBytes; need > 0 {
+		if err := r.evictLocked(need, key); err != nil {
+			return err
+		}
+	}
+
+	offset, err := r.appendLocked(record)
+	if err != nil {
+		return err
+	}
+	r.index[key] = &diskEntry{offset: offset, size: size, valueLen: len(data), expiresAt: expiresAt}
+	r.liveBytes += size - oldSize
+	r.stats.sets.Add(1)
+	r.scheduleCompactionLocked()
+	return nil
+}
+
+// Delete 删除缓存
+func (r *diskCacheRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ErrDiskCacheClosed
+	}
+	now := time.Now().UnixNano()
+	entry, ok := r.index[key]
+	if !ok || entry.expired(now) {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+	if err := r.removeLocked(key, entry, now); err != nil {
+		return err
+	}
+	r.stats.deletes.Add(1)
+	r.scheduleCompactionLocked()
+	return nil
+}
+
+// Exists 检查缓存是否存在
+func (r *diskCacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.file == nil {
+		return false, ErrDiskCacheClosed
+	}
+	entry, ok := r.index[key]
+	return ok && !entry.expired(time.Now().UnixNano()), nil
+}
+
+// Clear 清空所有缓存，日志截断为只含文件头
+func (r *diskCacheRepository) Clear(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ErrDiskCacheClosed
+	}
+	count := len(r.index)
+	if err := r.file.Truncate(int64(len(diskCacheMagic))); err != nil {
+		return fmt.Errorf("清空磁盘缓存失败: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("清空磁盘缓存失败: %w", err)
+	}
+	r.index = make(map[string]*diskEntry)
+	r.fileSize = int64(len(diskCacheMagic))
+	r.liveBytes = 0
+
+	r.logger.Info("清空缓存",
+		logger.String("type", "disk"),
+		logger.Int("cleared_count", count),
+	)
+	return nil
+}
+
+// GetKeys 获取匹配模式的键，支持与Redis相同的glob语法
+func (r *diskCacheRepository) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.file == nil {
+		return nil, ErrDiskCacheClosed
+	}
+	now := time.Now().UnixNano()
+	var keys []string
+	for key, entry := range r.index {
+		if entry.expired(now) {
+			continue
+		}
+		if pattern == "" || pattern == "*" || resp.Match(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// GetTTL 获取剩余过期时间，永不过期的键返回0
+func (r *diskCacheRepository) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.file == nil {
+		return 0, ErrDiskCacheClosed
+	}
+	entry, ok := r.index[key]
+	if !ok || entry.expired(time.Now().UnixNano()) {
+		return 0, fmt.Errorf("缓存键不存在: %s", key)
+	}
+	if entry.expiresAt == 0 {
+		return 0, nil
+	}
+	return time.Until(time.Unix(0, entry.expiresAt)), nil
+}
+
+// Expire 设置过期时间，以新过期时间重写记录；ttl不大于0时立即过期
+func (r *diskCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ErrDiskCacheClosed
+	}
+	now := time.Now().UnixNano()
+	entry, ok := r.index[key]
+	if !ok || entry.expired(now) {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+	if ttl <= 0 {
+		return r.removeLocked(key, entry, now)
+	}
+
+	value := make([]byte, entry.valueLen)
+	if _, err := r.file.ReadAt(value, entry.valueOffset()); err != nil {
+		r.stats.errors.Add(1)
+		return fmt.Errorf("读取磁盘缓存失败: %w", err)
+	}
+	expiresAt := time.Now().Add(ttl).UnixNano()
+	offset, err := r.appendLocked(encodeDiskRecord(diskOpSet, key, value, expiresAt))
+	if err != nil {
+		return err
+	}
+	entry.offset = offset
+	entry.expiresAt = expiresAt
+	r.scheduleCompactionLocked()
+	return nil
+}
+
+// GetStats 获取缓存统计
+func (r *diskCacheRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hits, misses := r.stats.hits.Load(), r.stats.misses.Load()
+	hitRate := float64(0)
+	totalRequests := hits + misses
+	if totalRequests > 0 {
+		hitRate = float64(hits) / float64(totalRequests) * 100
+	}
+
+	return map[string]interface{}{
+		"type":           "disk",
+		"directory":      r.dir,
+		"hits":           hits,
+		"misses":         misses,
+		"sets":           r.stats.sets.Load(),
+		"deletes":        r.stats.deletes.Load(),
+		"evictions":      r.stats.evictions.Load(),
+		"errors":         r.stats.errors.Load(),
+		"compactions":    r.stats.compactions.Load(),
+		"total_items":    len(r.index),
+		"hit_rate":       hitRate,
+		"total_requests": totalRequests,
+		"live_bytes":     r.liveBytes,
+		"file_size":      r.fileSize,
+		"max_bytes":      r.maxBytes,
+	}, nil
+}
+
+// Close 停止后台维护，同步并关闭日志
+func (r *diskCacheRepository) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Sync()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+	return err
+}
+
+// appendLocked 在日志末尾追加记录，写入失败时截断到写入前的长度
+func (r *diskCacheRepository) appendLocked(record []byte) (int64, error) {
+	offset := r.fileSize
+	if _, err := r.file.WriteAt(record, offset); err != nil {
+		r.stats.errors.Add(1)
+		r.file.Truncate(offset)
+		return 0, fmt.Errorf("写入磁盘缓存失败: %w", err)
+	}
+	if r.syncWrites {
+		if err := r.file.Sync(); err != nil {
+			r.stats.errors.Add(1)
+			return 0, fmt.Errorf("同步磁盘缓存失败: %w", err)
+		}
+	}
+	r.fileSize += int64(len(record))
+	return offset, nil
+}
+
+// removeLocked 从索引中移除条目，未过期的条目写入删除记录，否则重启后会被重新载入
+func (r *diskCacheRepository) removeLocked(key string, entry *diskEntry, now int64) error {
+	if !entry.expired(now) {
+		if _, err := r.appendLocked(encodeDiskRecord(diskOpDelete, key, nil, 0)); err != nil {
+			return err
+		}
+	}
+	delete(r.index, key)
+	r.liveBytes -= entry.size
+	return nil
+}
+
+// evictLocked 按过期时间从早到晚淘汰条目，直到释放need字节，keep为正在写入的键
+func (r *diskCacheRepository) evictLocked(need int64, keep string) error {
+	keys := make([]string, 0, len(r.index))
+	for key := range r.index {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	deadline := func(key string) int64 {
+		if expiresAt := r.index[key].expiresAt; expiresAt != 0 {
+			return expiresAt
+		}
+		return math.MaxInt64
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return deadline(keys[i]) < deadline(keys[j])
+	})
+
+	now := time.Now().UnixNano()
+	var freed int64
+	for _, key := range keys {
+		if freed >= need {
+			break
+		}
+		entry := r.index[key]
+		if err := r.removeLocked(key, entry, now); err != nil {
+			return err
+		}
+		freed += entry.size
+		r.stats.evictions.Add(1)
+	}
+	return nil
+}
+
+// scheduleCompactionLocked 无效记录超过有效数据量时通知后台压缩
+func (r *diskCacheRepository) scheduleCompactionLocked() {
+	if r.needsCompactionLocked() {
+		select {
+		case r.compactCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// needsCompactionLocked 检查是否需要压缩
+func (r *diskCacheRepository) needsCompactionLocked() bool {
+	garbage := r.fileSize - int64(len(diskCacheMagic)) - r.liveBytes
+	return garbage >= diskCompactMinGarbage && garbage > r.liveBytes
+}
+
+// maintain 定期清理过期条目，并在需要时压缩日志
+func (r *diskCacheRepository) maintain() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.sweep()
+		case <-r.compactCh:
+		}
+
+		r.mu.RLock()
+		needed := r.file != nil && r.needsCompactionLocked()
+		r.mu.RUnlock()
+		if !needed {
+			continue
+		}
+		if err := r.compact(); err != nil {
+			r.logger.Warn("磁盘缓存日志压缩失败",
+				logger.String("directory", r.dir),
+				logger.ErrorField("error", err),
+			)
+		}
+	}
+}
+
+// sweep 从索引中移除过期条目，其记录在下次压缩时删除
+func (r *diskCacheRepository) sweep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	var expired int
+	for key, entry := range r.index {
+		if entry.expired(now) {
+			delete(r.index, key)
+			r.liveBytes -= entry.size
+			expired++
+		}
+	}
+	if expired > 0 {
+		r.stats.evictions.Add(int64(expired))
+		r.logger.Debug("清理过期缓存项",
+			logger.String("type", "disk"),
+			logger.Int("expired_count", expired),
+			logger.Int("remaining_items", len(r.index)),
+		)
+	}
+}
+
+// compact 将有效记录按原顺序复制到新日志，同步后原子替换旧日志
+// 压缩期间持有写锁，有效数据受max_size限制，耗时与其大小成正比
+func (r *diskCacheRepository) compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ErrDiskCacheClosed
+	}
+	start := time.Now()
+	before := r.fileSize
+
+	tmpPath := filepath.Join(r.dir, diskCacheTmpName)
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	keys := make([]string, 0, len(r.index))
+	for key := range r.index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return r.index[keys[i]].offset < r.index[keys[j]].offset
+	})
+
+	writer := bufio.NewWriterSize(tmp, 256<<10)
+	writer.WriteString(diskCacheMagic)
+	offset := int64(len(diskCacheMagic))
+	index := make(map[string]*diskEntry, len(keys))
+	now := time.Now().UnixNano()
+	var buf []byte
+	for _, key := range keys {
+		entry := r.index[key]
+		if entry.expired(now) {
+			continue
+		}
+		if int64(cap(buf)) < entry.size {
+			buf = make([]byte, entry.size)
+		}
+		buf = buf[:entry.size]
+		if _, err := r.file.ReadAt(buf, entry.offset); err != nil {
+			return fail(err)
+		}
+		if _, err := writer.Write(buf); err != nil {
+			return fail(err)
+		}
+		index[key] = &diskEntry{offset: offset, size: entry.size, valueLen: entry.valueLen, expiresAt: entry.expiresAt}
+		offset += entry.size
+	}
+	if err := writer.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(r.dir, diskCacheLogName)); err != nil {
+		return fail(err)
+	}
+	syncDir(r.dir)
+
+	r.file.Close()
+	r.file = tmp
+	r.index = index
+	r.fileSize = offset
+	r.liveBytes = offset - int64(len(diskCacheMagic))
+	r.stats.compactions.Add(1)
+
+	r.logger.Info("磁盘缓存日志已压缩",
+		logger.String("directory", r.dir),
+		logger.Int("entries", len(index)),
+		logger.Any("before_bytes", before),
+		logger.Any("after_bytes", offset),
+		logger.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+// encodeDiskRecord 编码一条日志记录
+func encodeDiskRecord(op byte, key string, value []byte, expiresAt int64) []byte {
+	record := make([]byte, diskRecordHeaderSize+len(key)+len(value))
+	record[4] = op
+	binary.LittleEndian.PutUint64(record[5:13], uint64(expiresAt))
+	binary.LittleEndian.PutUint32(record[13:17], uint32(len(key)))
+	binary.LittleEndian.PutUint32(record[17:21], uint32(len(value)))
+	copy(record[diskRecordHeaderSize:], key)
+	copy(record[diskRecordHeaderSize+len(key):], value)
+	binary.LittleEndian.PutUint32(record[0:4], crc32.ChecksumIEEE(record[4:]))
+	return record
+}
+
+// syncDir 同步目录，使重命名在断电后仍然生效；部分平台不支持时忽略
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,203 @@
+// Package repository 封面图片磁盘缓存
+package repository
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// imageCacheExt 缓存文件扩展名
+const imageCacheExt = ".img"
+
+// ImageCache 按字节数限制容量的图片磁盘LRU缓存，用于保存渲染后的封面
+type ImageCache struct {
+	dir      string
+	maxBytes int64
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // 以文件ID为键
+	lru     *list.List               // 队首为最近访问
+	used    int64
+}
+
+// imageCacheEntry 缓存条目
+type imageCacheEntry struct {
+	id   string
+	size int64
+}
+
+// NewImageCache 创建图片磁盘缓存并加载目录中已有的文件
+func NewImageCache(dir string, maxBytes int64, log logger.Logger) (*ImageCache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("图片缓存容量必须大于0")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建图片缓存目录失败: %w", err)
+	}
+
+	c := &ImageCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		logger:   log,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取图片缓存目录失败: %w", err)
+	}
+	type loadedFile struct {
+		entry   *imageCacheEntry
+		modTime time.Time
+	}
+	loaded := make([]loadedFile, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), imageCacheExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		loaded = append(loaded, loadedFile{
+			entry:   &imageCacheEntry{id: strings.TrimSuffix(file.Name(), imageCacheExt), size: info.Size()},
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].modTime.After(loaded[j].modTime) })
+	for _, file := range loaded {
+		c.entries[file.entry.id] = c.lru.PushBack(file.entry)
+		c.used += file.entry.size
+	}
+
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// Get 读取缓存的图片
+func (c *ImageCache) Get(key string) ([]byte, bool) {
+	id := imageCacheID(key)
+
+	c.mu.Lock()
+	elem, ok := c.entries[id]
+	if ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(id))
+	if err != nil {
+		c.mu.Lock()
+		c.removeLocked(id)
+		c.mu.Unlock()
+		return nil, false
+	}
+	// 更新修改时间，重启后据此恢复访问顺序
+	now := time.Now()
+	_ = os.Chtimes(c.path(id), now, now)
+	return data, true
+}
+
+// Put 写入图片，先写临时文件再重命名，避免读取到写了一半的文件
+func (c *ImageCache) Put(key string, data []byte) error {
+	id := imageCacheID(key)
+	tmp, err := os.CreateTemp(c.dir, id+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建缓存文件失败: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("写入缓存文件失败: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(tmp.Name(), c.path(id)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("保存缓存文件失败: %w", err)
+	}
+	if elem, ok := c.entries[id]; ok {
+		entry := elem.Value.(*imageCacheEntry)
+		c.used += int64(len(data)) - entry.size
+		entry.size = int64(len(data))
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[id] = c.lru.PushFront(&imageCacheEntry{id: id, size: int64(len(data))})
+		c.used += int64(len(data))
+	}
+	c.evictLocked()
+	return nil
+}
+
+// Purge 清空缓存，返回删除的文件数
+func (c *ImageCache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := len(c.entries)
+	for id := range c.entries {
+		c.removeLocked(id)
+	}
+	return count
+}
+
+// Stats 获取条目数与占用字节数
+func (c *ImageCache) Stats() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries), c.used
+}
+
+// evictLocked 淘汰最久未访问的文件直到占用不超过上限
+func (c *ImageCache) evictLocked() {
+	for c.used > c.maxBytes && c.lru.Len() > 0 {
+		entry := c.lru.Back().Value.(*imageCacheEntry)
+		c.removeLocked(entry.id)
+		c.logger.Debug("淘汰封面缓存", logger.String("id", entry.id))
+	}
+}
+
+// removeLocked 删除条目及文件
+func (c *ImageCache) removeLocked(id string) {
+	elem, ok := c.entries[id]
+	if !ok {
+		return
+	}
+	c.used -= elem.Value.(*imageCacheEntry).size
+	c.lru.Remove(elem)
+	delete(c.entries, id)
+	os.Remove(c.path(id))
+}
+
+// path 缓存文件路径
+func (c *ImageCache) path(id string) string {
+	return filepath.Join(c.dir, id+imageCacheExt)
+}
+
+// imageCacheID 由缓存键生成文件ID
+func imageCacheID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -38,6 +38,39 @@ type MusicSource interface {
 	UpdateConfig(config *model.SourceConfig) error
 }
 
+// LyricProvider 歌词能力接口，支持获取歌词的音源可选实现
+type LyricProvider interface {
+	// GetLyric 获取歌词及翻译歌词
+	GetLyric(ctx context.Context, lyricID string) (string, string, error)
+}
+
+// PictureProvider 专辑图能力接口，支持获取专辑图的音源可选实现
+type PictureProvider interface {
+	// GetPicture 获取指定尺寸的专辑图链接
+	GetPicture(ctx context.Context, picID string, size string) (string, error)
+}
+
+// QualityLister 音质列表能力接口，音源可选实现以声明支持的音质
+type QualityLister interface {
+	// GetSupportedQualities 获取支持的音质列表
+	GetSupportedQualities() []string
+}
+
+// LocalLibrary 本地曲库接口，由提供本地文件的音源实现
+type LocalLibrary interface {
+	// TrackFile 获取曲目文件
+	TrackFile(id string) (*model.LocalTrackFile, error)
+
+	// TrackCover 获取曲目内嵌封面
+	TrackCover(id string) (*model.CoverImage, error)
+}
+
+// CapabilityChecker 能力声明接口，能力取决于配置的音源可选实现
+type CapabilityChecker interface {
+	// SupportsCapability 检查是否支持指定能力
+	SupportsCapability(capability string) bool
+}
+
 // SourceManager 音源管理器接口
 type SourceManager interface {
 	// RegisterSource 注册音源
@@ -64,11 +97,23 @@ type SourceManager interface {
 	// SearchMusic 使用多个音源搜索音乐
 	SearchMusic(ctx context.Context, keyword string, sources []string) ([]*model.SearchResult, error)
 
+	// GetLyric 在支持歌词的音源间获取歌词，失败时回退到下一个音源
+	GetLyric(ctx context.Context, lyricID string, sources []string) (*model.LyricResult, error)
+
+	// GetPicture 在支持专辑图的音源间获取专辑图，失败时回退到下一个音源
+	GetPicture(ctx context.Context, picID string, size string, sources []string) (*model.PictureResult, error)
+
 	// GetSourcesStatus 获取音源状态
 	GetSourcesStatus(ctx context.Context) ([]*model.SourceStatus, error)
 	
 	// RefreshSources 刷新音源配置
 	RefreshSources() error
+
+	// OpenCircuitBreaker 强制打开指定音源的熔断器
+	OpenCircuitBreaker(name string) (*model.CircuitBreakerStatus, error)
+
+	// ResetCircuitBreaker 重置指定音源的熔断器
+	ResetCircuitBreaker(name string) (*model.CircuitBreakerStatus, error)
 }
 
 
@@ -242,6 +287,7 @@ type Logger interface {
 type Repository struct {
 	SourceManager SourceManager
 	Cache         CacheRepository
+	CacheMetrics  *CacheMetrics
 	Config        ConfigRepository
 	Metrics       MetricsRepository
 	HTTPClient    HTTPClient
@@ -262,6 +308,7 @@ func NewRepository(
 	return &Repository{
 		SourceManager: sourceManager,
 		Cache:         cache,
+		CacheMetrics:  NewCacheMetrics(),
 		Config:        config,
 		Metrics:       metrics,
 		HTTPClient:    httpClient,
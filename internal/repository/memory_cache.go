@@ -0,0 +1,368 @@
+// Package repository 内存缓存仓库实现
+package repository
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/resp"
+)
+
+// 分片配置：分片数为2的幂，每个分片的容量不低于memoryMinShardBytes，容量较小时减少分片数
+const (
+	memoryMaxShards     = 16
+	memoryMinShardBytes = 1 << 20
+)
+
+// memoryEntryOverhead 每个条目的估算固定开销，包括链表节点、map槽位与条目结构
+const memoryEntryOverhead = 96
+
+// memoryEntry 内存缓存条目
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt int64 // 过期时间（Unix纳秒），0表示永不过期
+	size      int64 // 计入容量的字节数
+}
+
+// expired 检查是否过期
+func (e *memoryEntry) expired(now int64) bool {
+	return e.expiresAt != 0 && now >= e.expiresAt
+}
+
+// memoryShard 内存缓存分片，各自维护LRU链表与容量
+type memoryShard struct {
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	lru      *list.List
+	bytes    int64
+	maxBytes int64
+}
+
+// memoryCacheRepository 内存缓存仓库实现
+// 键按哈希分散到多个分片，每个分片持有独立的锁与容量，超出容量时淘汰最久未访问的条目；
+// 值以编码后的字符串保存，容量按键、值长度与固定开销计算。
+type memoryCacheRepository struct {
+	shards   []*memoryShard
+	maxBytes int64
+	interval time.Duration
+	logger   logger.Logger
+
+	closeOnce sync.Once
+	done      chan struct{}
+
+	// 统计信息
+	stats struct {
+		hits      atomic.Int64
+		misses    atomic.Int64
+		sets      atomic.Int64
+		deletes   atomic.Int64
+		evictions atomic.Int64
+		expired   atomic.Int64
+	}
+}
+
+// NewMemoryCacheRepository 创建内存缓存仓库，maxBytes为容量上限，cleanupInterval为清理过期条目的间隔
+func NewMemoryCacheRepository(maxBytes int64, cleanupInterval time.Duration, log logger.Logger) CacheRepository {
+	if maxBytes <= 0 {
+		maxBytes = 64 << 20
+	}
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+
+	shardCount := memoryMaxShards
+	for shardCount > 1 && maxBytes/int64(shardCount) < memoryMinShardBytes {
+		shardCount /= 2
+	}
+	r := &memoryCacheRepository{
+		shards:   make([]*memoryShard, shardCount),
+		maxBytes: maxBytes,
+		interval: cleanupInterval,
+		logger:   log,
+		done:     make(chan struct{}),
+	}
+	for i := range r.shards {
+		r.shards[i] = &memoryShard{
+			items:    make(map[string]*list.Element),
+			lru:      list.New(),
+			maxBytes: maxBytes / int64(shardCount),
+		}
+	}
+
+	go r.cleanupExpiredItems()
+	return r
+}
+
+// Get 获取缓存，值以字符串返回
+func (r *memoryCacheRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	shard := r.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	if !ok {
+		r.stats.misses.Add(1)
+		return nil, fmt.Errorf("缓存键不存在: %s", key)
+	}
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(time.Now().UnixNano()) {
+		shard.removeLocked(elem)
+		r.stats.misses.Add(1)
+		r.stats.expired.Add(1)
+		return nil, fmt.Errorf("缓存已过期: %s", key)
+	}
+
+	shard.lru.MoveToFront(elem)
+	r.stats.hits.Add(1)
+	return entry.value, nil
+}
+
+// Set 设置缓存，非字符串值序列化为JSON后存储，ttl不大于0时永不过期
+func (r *memoryCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := cacheValueBytes(value)
+	if err != nil {
+		return err
+	}
+	entry := &memoryEntry{
+		key:   key,
+		value: string(data),
+		size:  int64(len(key)+len(data)) + memoryEntryOverhead,
+	}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	shard := r.shard(key)
+	if entry.size > shard.maxBytes {
+		return fmt.Errorf("缓存值超过内存缓存分片容量: %s", key)
+	}
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if elem, ok := shard.items[key]; ok {
+		shard.removeLocked(elem)
+	}
+	shard.items[key] = shard.lru.PushFront(entry)
+	shard.bytes += entry.size
+	for shard.bytes > shard.maxBytes {
+		shard.removeLocked(shard.lru.Back())
+		r.stats.evictions.Add(1)
+	}
+
+	r.stats.sets.Add(1)
+	return nil
+}
+
+// Delete 删除缓存
+func (r *memoryCacheRepository) Delete(ctx context.Context, key string) error {
+	shard := r.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	if !ok {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+	shard.removeLocked(elem)
+	r.stats.deletes.Add(1)
+	return nil
+}
+
+// Exists 检查缓存是否存在
+func (r *memoryCacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	shard := r.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	return ok && !elem.Value.(*memoryEntry).expired(time.Now().UnixNano()), nil
+}
+
+// Clear 清空所有缓存
+func (r *memoryCacheRepository) Clear(ctx context.Context) error {
+	count := 0
+	for _, shard := range r.shards {
+		shard.mu.Lock()
+		count += len(shard.items)
+		shard.items = make(map[string]*list.Element)
+		shard.lru.Init()
+		shard.bytes = 0
+		shard.mu.Unlock()
+	}
+
+	r.logger.Info("清空缓存",
+		logger.String("type", "memory"),
+		logger.Int("cleared_count", count),
+	)
+	return nil
+}
+
+// GetKeys 获取匹配模式的键，支持与Redis相同的glob语法
+func (r *memoryCacheRepository) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	now := time.Now().UnixNano()
+	var keys []string
+	for _, shard := range r.shards {
+		shard.mu.Lock()
+		for key, elem := range shard.items {
+			if elem.Value.(*memoryEntry).expired(now) {
+				continue
+			}
+			if pattern == "" || pattern == "*" || resp.Match(pattern, key) {
+				keys = append(keys, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	return keys, nil
+}
+
+// GetTTL 获取剩余过期时间，永不过期的键返回0
+func (r *memoryCacheRepository) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	shard := r.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	if !ok {
+		return 0, fmt.Errorf("缓存键不存在: %s", key)
+	}
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(time.Now().UnixNano()) {
+		return 0, fmt.Errorf("缓存已过期: %s", key)
+	}
+	if entry.expiresAt == 0 {
+		return 0, nil
+	}
+	return time.Until(time.Unix(0, entry.expiresAt)), nil
+}
+
+// Expire 设置过期时间，ttl不大于0时立即过期
+func (r *memoryCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	shard := r.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	elem, ok := shard.items[key]
+	if !ok {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(time.Now().UnixNano()) || ttl <= 0 {
+		shard.removeLocked(elem)
+		r.stats.expired.Add(1)
+		if ttl > 0 {
+			return fmt.Errorf("缓存已过期: %s", key)
+		}
+		return nil
+	}
+
+	entry.expiresAt = time.Now().Add(ttl).UnixNano()
+	return nil
+}
+
+// GetStats 获取缓存统计
+func (r *memoryCacheRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	var items int
+	var bytes int64
+	for _, shard := range r.shards {
+		shard.mu.Lock()
+		items += len(shard.items)
+		bytes += shard.bytes
+		shard.mu.Unlock()
+	}
+
+	hits, misses := r.stats.hits.Load(), r.stats.misses.Load()
+	hitRate := float64(0)
+	totalRequests := hits + misses
+	if totalRequests > 0 {
+		hitRate = float64(hits) / float64(totalRequests) * 100
+	}
+
+	return map[string]interface{}{
+		"type":           "memory",
+		"hits":           hits,
+		"misses":         misses,
+		"sets":           r.stats.sets.Load(),
+		"deletes":        r.stats.deletes.Load(),
+		"evictions":      r.stats.evictions.Load(),
+		"expired":        r.stats.expired.Load(),
+		"total_items":    items,
+		"bytes":          bytes,
+		"max_bytes":      r.maxBytes,
+		"shards":         len(r.shards),
+		"hit_rate":       hitRate,
+		"total_requests": totalRequests,
+	}, nil
+}
+
+// Close 停止后台清理并释放所有条目
+func (r *memoryCacheRepository) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+	for _, shard := range r.shards {
+		shard.mu.Lock()
+		shard.items = make(map[string]*list.Element)
+		shard.lru.Init()
+		shard.bytes = 0
+		shard.mu.Unlock()
+	}
+	return nil
+}
+
+// shard 按键的FNV-1a哈希选择分片
+func (r *memoryCacheRepository) shard(key string) *memoryShard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return r.shards[hash&uint32(len(r.shards)-1)]
+}
+
+// removeLocked 移除条目并更新容量
+func (s *memoryShard) removeLocked(elem *list.Element) {
+	entry := s.lru.Remove(elem).(*memoryEntry)
+	delete(s.items, entry.key)
+	s.bytes -= entry.size
+}
+
+// cleanupExpiredItems 按配置的间隔清理过期条目，每次只锁定一个分片
+func (r *memoryCacheRepository) cleanupExpiredItems() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UnixNano()
+		expired := 0
+		for _, shard := range r.shards {
+			shard.mu.Lock()
+			for _, elem := range shard.items {
+				if elem.Value.(*memoryEntry).expired(now) {
+					shard.removeLocked(elem)
+					expired++
+				}
+			}
+			shard.mu.Unlock()
+		}
+
+		if expired > 0 {
+			r.stats.expired.Add(int64(expired))
+			r.logger.Debug("清理过期缓存项",
+				logger.String("type", "memory"),
+				logger.Int("expired_count", expired),
+			)
+		}
+	}
+}
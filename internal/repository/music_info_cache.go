@@ -99,6 +99,7 @@ func (mip *MusicInfoProvider) GetMusicInfo(ctx context.Context, id string, sourc
 		Album:    "未知专辑",
 		Duration: 0,
 		PicURL:   "",
+		Platform: model.PlatformFromContext(ctx),
 	}
 	
 	return basicInfo, nil
@@ -110,7 +111,7 @@ func (mip *MusicInfoProvider) getFromPersistentCache(ctx context.Context, id str
 		return nil, fmt.Errorf("缓存不可用")
 	}
 	
-	cacheKey := fmt.Sprintf("music_info:%s", id)
+	cacheKey := fmt.Sprintf("music_info:%s:%s", model.PlatformFromContext(ctx), id)
 	data, err := mip.sourceCache.Get(ctx, cacheKey)
 	if err != nil {
 		return nil, err
@@ -134,7 +135,7 @@ func (mip *MusicInfoProvider) setToPersistentCache(ctx context.Context, id strin
 		return nil
 	}
 	
-	cacheKey := fmt.Sprintf("music_info:%s", id)
+	cacheKey := fmt.Sprintf("music_info:%s:%s", model.PlatformFromContext(ctx), id)
 	data, err := json.Marshal(info)
 	if err != nil {
 		return err
@@ -178,6 +179,7 @@ func (mip *MusicInfoProvider) getFromSearchResults(ctx context.Context, id strin
 							Album:    result.Album,
 							Duration: result.Duration,
 							PicURL:   "",
+							Platform: result.Platform,
 						}, nil
 					}
 				}
@@ -196,6 +198,7 @@ func (mip *MusicInfoProvider) getFromSearchResults(ctx context.Context, id strin
 						Album:    results[0].Album,
 						Duration: results[0].Duration,
 						PicURL:   "",
+						Platform: results[0].Platform,
 					}, nil
 				}
 			}
@@ -77,9 +77,10 @@ func (mir *MusicInfoResolver) ResolveMusicInfo(ctx context.Context, id string) (
 		return nil, fmt.Errorf("GDStudio配置未启用")
 	}
 
-	// 检查缓存
+	// 检查缓存（按平台区分，避免不同平台的相同ID冲突）
+	cacheKey := model.PlatformFromContext(ctx) + ":" + id
 	mir.mutex.RLock()
-	if info, exists := mir.cache[id]; exists {
+	if info, exists := mir.cache[cacheKey]; exists {
 		mir.mutex.RUnlock()
 		return info, nil
 	}
@@ -105,7 +106,7 @@ func (mir *MusicInfoResolver) ResolveMusicInfo(ctx context.Context, id string) (
 		if info, err := strategy(ctx, id); err == nil {
 			// 缓存结果
 			mir.mutex.Lock()
-			mir.cache[id] = info
+			mir.cache[cacheKey] = info
 			mir.mutex.Unlock()
 
 			mir.logger.Info("成功解析音乐信息",
@@ -133,7 +134,7 @@ func (mir *MusicInfoResolver) getFromGDStudioDirectSearch(ctx context.Context, i
 	apiURL := mir.gdstudioConfig.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("name", id)
 	params.Set("count", "50") // 增加搜索结果数量
 	params.Set("pages", "1")
@@ -183,6 +184,7 @@ func (mir *MusicInfoResolver) getFromGDStudioDirectSearch(ctx context.Context, i
 				Album:    encoding.FixChineseEncoding(item.Album),
 				Duration: 0, // GDStudio API没有提供时长信息
 				PicURL:   "", // 可以后续通过pic_id获取
+				Platform: model.PlatformFromContext(ctx),
 			}, nil
 		}
 	}
@@ -216,7 +218,7 @@ func (mir *MusicInfoResolver) searchByKeyword(ctx context.Context, keyword, targ
 	apiURL := mir.gdstudioConfig.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("name", keyword)
 	params.Set("count", strconv.Itoa(mir.config.SearchFallback.MaxResults))
 	params.Set("pages", "1")
@@ -264,6 +266,7 @@ func (mir *MusicInfoResolver) searchByKeyword(ctx context.Context, keyword, targ
 			Album:    encoding.FixChineseEncoding(item.Album),
 			Duration: 0,
 			PicURL:   "",
+			Platform: model.PlatformFromContext(ctx),
 		}, nil
 	}
 
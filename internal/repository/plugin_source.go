@@ -0,0 +1,325 @@
+// Package repository 插件音源适配器
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/plugin"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// pluginQualityAliases 音质参数与插件音质标识的对应关系
+var pluginQualityAliases = map[string]string{
+	"128": "128k",
+	"192": "192k",
+	"320": "320k",
+	"740": "flac",
+	"999": "flac",
+}
+
+// PluginSource 将plugin.SourcePlugin适配为MusicSource
+// 同时实现LyricProvider、PictureProvider与QualityLister，插件的歌词、专辑图能力可直接用于请求链路
+type PluginSource struct {
+	plugin  plugin.SourcePlugin
+	enabled bool
+	mu      sync.RWMutex
+	logger  logger.Logger
+}
+
+// NewPluginSource 创建插件音源适配器，插件需已完成初始化
+func NewPluginSource(p plugin.SourcePlugin, log logger.Logger) *PluginSource {
+	return &PluginSource{
+		plugin:  p,
+		enabled: true,
+		logger:  log,
+	}
+}
+
+// Plugin 获取被适配的插件
+func (s *PluginSource) Plugin() plugin.SourcePlugin {
+	return s.plugin
+}
+
+// GetName 获取音源名称
+func (s *PluginSource) GetName() string {
+	return s.plugin.Name()
+}
+
+// IsEnabled 检查音源是否启用
+func (s *PluginSource) IsEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enabled && s.plugin.IsEnabled()
+}
+
+// SetEnabled 设置音源启用状态
+func (s *PluginSource) SetEnabled(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled = enabled
+}
+
+// GetMusic 根据音乐ID获取播放链接
+func (s *PluginSource) GetMusic(ctx context.Context, id string, quality string) (*model.MusicURL, error) {
+	if !s.IsEnabled() {
+		return nil, fmt.Errorf("插件音源 %s 已禁用", s.GetName())
+	}
+
+	pluginQuality, err := s.pluginQuality(quality)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.plugin.GetMusicURL(ctx, id, pluginQuality)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.URL == "" {
+		return nil, fmt.Errorf("插件音源 %s 未获取到有效的音乐链接", s.GetName())
+	}
+
+	return &model.MusicURL{
+		URL:     result.URL,
+		Quality: modelQuality(result.Quality),
+		Size:    result.Size,
+		Format:  result.Format,
+		Source:  s.GetName(),
+		Info:    result.Info,
+	}, nil
+}
+
+// SearchMusic 根据歌曲名搜索音乐
+func (s *PluginSource) SearchMusic(ctx context.Context, keyword string) ([]*model.SearchResult, error) {
+	if !s.IsEnabled() {
+		return nil, fmt.Errorf("插件音源 %s 已禁用", s.GetName())
+	}
+
+	items, err := s.plugin.SearchMusic(ctx, plugin.SearchQuery{
+		Keyword: keyword,
+		Type:    "song",
+		Limit:   20,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	platform := model.PlatformFromContext(ctx)
+	results := make([]*model.SearchResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, &model.SearchResult{
+			ID:       item.ID,
+			Name:     item.Name,
+			Artist:   strings.Join(item.Artist, ", "),
+			Album:    item.Album,
+			Duration: int64(item.Duration),
+			Source:   s.GetName(),
+			Platform: platform,
+		})
+	}
+
+	return results, nil
+}
+
+// GetMusicInfo 获取音乐详细信息
+func (s *PluginSource) GetMusicInfo(ctx context.Context, id string) (*model.MusicInfo, error) {
+	if !s.IsEnabled() {
+		return nil, fmt.Errorf("插件音源 %s 已禁用", s.GetName())
+	}
+	return s.plugin.GetMusicInfo(ctx, id)
+}
+
+// GetLyric 获取歌词，插件不提供翻译歌词
+func (s *PluginSource) GetLyric(ctx context.Context, lyricID string) (string, string, error) {
+	if !s.IsEnabled() {
+		return "", "", fmt.Errorf("插件音源 %s 已禁用", s.GetName())
+	}
+
+	lyrics, err := s.plugin.GetLyrics(ctx, lyricID)
+	if err != nil {
+		return "", "", err
+	}
+	if lyrics == nil {
+		return "", "", nil
+	}
+	if lyrics.LRC != "" {
+		return lyrics.LRC, "", nil
+	}
+	return lyrics.Content, "", nil
+}
+
+// GetPicture 获取专辑图链接，插件接口不支持指定尺寸
+func (s *PluginSource) GetPicture(ctx context.Context, picID string, size string) (string, error) {
+	if !s.IsEnabled() {
+		return "", fmt.Errorf("插件音源 %s 已禁用", s.GetName())
+	}
+
+	picture, err := s.plugin.GetPicture(ctx, picID)
+	if err != nil {
+		return "", err
+	}
+	if picture == nil {
+		return "", nil
+	}
+	return picture.URL, nil
+}
+
+// GetSupportedQualities 获取支持的音质列表
+func (s *PluginSource) GetSupportedQualities() []string {
+	qualities := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, quality := range s.plugin.GetSupportedQualities() {
+		code := modelQuality(quality)
+		if !seen[code] {
+			seen[code] = true
+			qualities = append(qualities, code)
+		}
+	}
+	return qualities
+}
+
+// HealthCheck 健康检查
+func (s *PluginSource) HealthCheck(ctx context.Context) error {
+	return s.plugin.Health(ctx)
+}
+
+// GetConfig 获取音源配置
+func (s *PluginSource) GetConfig() *model.SourceConfig {
+	return &model.SourceConfig{
+		Name:     s.GetName(),
+		Enabled:  s.IsEnabled(),
+		Priority: s.plugin.GetPriority(),
+		Timeout:  s.plugin.GetRateLimit().Timeout,
+	}
+}
+
+// UpdateConfig 更新音源配置，插件配置在加载时确定，这里只更新启用状态
+func (s *PluginSource) UpdateConfig(config *model.SourceConfig) error {
+	if config == nil {
+		return fmt.Errorf("配置不能为空")
+	}
+	s.SetEnabled(config.Enabled)
+	return nil
+}
+
+// pluginQuality 将音质参数转换为插件支持的音质标识
+// 插件声明了音质列表但不支持该音质时返回ErrQualityMismatch，不替换为其他音质，由音质阶梯决定是否降级
+func (s *PluginSource) pluginQuality(quality string) (string, error) {
+	supported := s.plugin.GetSupportedQualities()
+	if len(supported) == 0 {
+		return quality, nil
+	}
+	for _, q := range supported {
+		if q == quality {
+			return quality, nil
+		}
+	}
+	alias, ok := pluginQualityAliases[quality]
+	if !ok {
+		return quality, nil
+	}
+	for _, q := range supported {
+		if q == alias {
+			return alias, nil
+		}
+	}
+	return "", fmt.Errorf("%w: 插件音源 %s 不支持音质 %s", ErrQualityMismatch, s.GetName(), quality)
+}
+
+// modelQuality 将插件音质标识转换为音质参数
+func modelQuality(quality string) string {
+	switch quality {
+	case "128k":
+		return "128"
+	case "192k":
+		return "192"
+	case "320k":
+		return "320"
+	case "flac":
+		return "999"
+	default:
+		return quality
+	}
+}
+
+// LoadPluginSources 从插件注册表加载音源插件并注册为音源
+// 仅加载配置中启用的插件，与已注册音源重名的插件会被跳过
+func (sm *DefaultSourceManager) LoadPluginSources(ctx context.Context, registry plugin.Registry, configs []config.PluginSourceConfig) int {
+	loaded := 0
+	for _, cfg := range configs {
+		if !cfg.Enabled {
+			continue
+		}
+
+		if _, err := sm.GetSource(cfg.Name); err == nil {
+			sm.logger.Info("已存在同名音源，跳过音源插件",
+				logger.String("plugin", cfg.Name),
+			)
+			continue
+		}
+
+		source, err := sm.createPluginSource(ctx, registry, cfg)
+		if err != nil {
+			sm.logger.Error("加载音源插件失败",
+				logger.String("plugin", cfg.Name),
+				logger.ErrorField("error", err),
+			)
+			continue
+		}
+
+		if err := sm.RegisterSource(source); err != nil {
+			sm.logger.Error("注册音源插件失败",
+				logger.String("plugin", cfg.Name),
+				logger.ErrorField("error", err),
+			)
+			continue
+		}
+
+		loaded++
+		sm.logger.Info("音源插件加载成功",
+			logger.String("plugin", cfg.Name),
+			logger.String("version", source.Plugin().Version()),
+			logger.Int("priority", source.Plugin().GetPriority()),
+		)
+	}
+
+	return loaded
+}
+
+// createPluginSource 创建、初始化并启动音源插件
+func (sm *DefaultSourceManager) createPluginSource(ctx context.Context, registry plugin.Registry, cfg config.PluginSourceConfig) (*PluginSource, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("插件注册表不能为空")
+	}
+
+	instance, err := registry.Create(cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePlugin, ok := instance.(plugin.SourcePlugin)
+	if !ok {
+		return nil, fmt.Errorf("插件 %s 不是音源插件", cfg.Name)
+	}
+
+	pluginConfig := cfg.Config
+	if pluginConfig == nil {
+		pluginConfig = make(map[string]interface{})
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := sourcePlugin.Initialize(initCtx, pluginConfig, sm.logger); err != nil {
+		return nil, fmt.Errorf("初始化插件失败: %w", err)
+	}
+	if err := sourcePlugin.Start(initCtx); err != nil {
+		return nil, fmt.Errorf("启动插件失败: %w", err)
+	}
+
+	return NewPluginSource(sourcePlugin, sm.logger), nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/plugin"
+)
+
+// fakeSourcePlugin 按请求的音质标识返回播放链接的插件
+type fakeSourcePlugin struct {
+	*plugin.BaseSourcePlugin
+	requested string
+}
+
+func newFakeSourcePlugin(qualities ...string) *fakeSourcePlugin {
+	p := &fakeSourcePlugin{BaseSourcePlugin: plugin.NewBaseSourcePlugin("fake", "1.0.0", "测试插件")}
+	p.SetSupportedQualities(qualities)
+	p.SetEnabled(true)
+	p.Start(context.Background())
+	return p
+}
+
+func (p *fakeSourcePlugin) SearchMusic(ctx context.Context, query plugin.SearchQuery) ([]plugin.SearchResult, error) {
+	return nil, nil
+}
+
+func (p *fakeSourcePlugin) GetMusicURL(ctx context.Context, id string, quality string) (*plugin.MusicURL, error) {
+	p.requested = quality
+	return &plugin.MusicURL{URL: "http://example.com/" + id, Quality: quality}, nil
+}
+
+func (p *fakeSourcePlugin) GetMusicInfo(ctx context.Context, id string) (*model.MusicInfo, error) {
+	return nil, nil
+}
+
+func (p *fakeSourcePlugin) GetLyrics(ctx context.Context, id string) (*plugin.Lyrics, error) {
+	return nil, nil
+}
+
+func (p *fakeSourcePlugin) GetPicture(ctx context.Context, id string) (*plugin.Picture, error) {
+	return nil, nil
+}
+
+func TestPluginSourceQuality(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported []string
+		quality   string
+		requested string // 传给插件的音质标识，为空表示期望音质不满足
+		want      string
+	}{
+		{"别名", []string{"128k", "320k", "flac"}, "320", "320k", "320"},
+		{"无损别名", []string{"128k", "320k", "flac"}, "999", "flac", "999"},
+		{"192不升级为320", []string{"128k", "320k", "flac"}, "192", "", ""},
+		{"支持192", []string{"128k", "192k", "320k"}, "192", "192k", "192"},
+		{"插件原生标识", []string{"standard", "exhigh"}, "exhigh", "exhigh", "exhigh"},
+		{"未知音质原样传递", []string{"128k"}, "lossless", "lossless", "lossless"},
+		{"未声明音质列表", nil, "192", "192", "192"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newFakeSourcePlugin(tt.supported...)
+			source := NewPluginSource(p, testLogger(t))
+
+			musicURL, err := source.GetMusic(context.Background(), "1", tt.quality)
+			if tt.requested == "" {
+				if !errors.Is(err, ErrQualityMismatch) {
+					t.Fatalf("GetMusic(%s) 错误 = %v，期望 ErrQualityMismatch", tt.quality, err)
+				}
+				if p.requested != "" {
+					t.Errorf("音质不满足时不应请求插件，实际请求 %s", p.requested)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetMusic(%s)失败: %v", tt.quality, err)
+			}
+			if p.requested != tt.requested || musicURL.Quality != tt.want {
+				t.Errorf("GetMusic(%s) 请求 %s 返回 %s，期望请求 %s 返回 %s", tt.quality, p.requested, musicURL.Quality, tt.requested, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package repository 带缓存策略的缓存命名空间
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// CachePolicy 缓存策略
+type CachePolicy struct {
+	TTL         time.Duration // 新鲜期，期间直接使用缓存
+	StaleTTL    time.Duration // 新鲜期过后仍可使用的时长，期间返回旧值并在后台刷新
+	NegativeTTL time.Duration // 所有音源都明确答复未找到时缓存该结果的时长，0表示不缓存
+}
+
+// policyEntry 缓存条目，新鲜期截止时间随值一起保存
+type policyEntry[T any] struct {
+	Value      T      `json:"value"`
+	NotFound   string `json:"not_found,omitempty"`   // 否定缓存的错误信息
+	FreshUntil int64  `json:"fresh_until,omitempty"` // 新鲜期截止时间（Unix纳秒）
+}
+
+// notFoundError 否定缓存命中时返回的错误，保留原错误信息并可用errors.Is判断为ErrNotFound
+type notFoundError struct {
+	message string
+}
+
+// Error 返回原错误信息
+func (e *notFoundError) Error() string {
+	return e.message
+}
+
+// Unwrap 返回ErrNotFound
+func (e *notFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// PolicyCache 按缓存策略读写的缓存命名空间，支持过期后继续使用旧值与否定缓存
+type PolicyCache[T any] struct {
+	cache  *TypedCache[*policyEntry[T]]
+	policy CachePolicy
+	stats  *NamespaceStats
+}
+
+// NewPolicyCache 创建按缓存策略读写的缓存命名空间，条目以JSON编码存储
+func NewPolicyCache[T any](repo CacheRepository, namespace string, policy CachePolicy, metrics *CacheMetrics, log logger.Logger) *PolicyCache[T] {
+	return &PolicyCache[T]{
+		cache:  NewTypedCache(repo, namespace, JSONCodec[*policyEntry[T]]{}, metrics, log),
+		policy: policy,
+		stats:  metrics.Namespace(namespace),
+	}
+}
+
+// Get 获取缓存值及缓存状态：新鲜期内为HIT，新鲜期过后为STALE，不存在时为MISS
+// 命中否定缓存时状态为HIT并返回缓存的未找到错误
+func (c *PolicyCache[T]) Get(ctx context.Context, key string) (T, string, error) {
+	var zero T
+	entry, ok := c.cache.Get(ctx, key)
+	// 不带新鲜期的条目是旧版本写入的，视为未命中
+	if !ok || entry == nil || entry.FreshUntil == 0 {
+		return zero, model.CacheStatusMiss, nil
+	}
+
+	if entry.NotFound != "" {
+		c.stats.negative.Add(1)
+		return zero, model.CacheStatusHit, &notFoundError{message: entry.NotFound}
+	}
+	if time.Now().UnixNano() >= entry.FreshUntil {
+		c.stats.stale.Add(1)
+		return entry.Value, model.CacheStatusStale, nil
+	}
+	return entry.Value, model.CacheStatusHit, nil
+}
+
+// Set 设置缓存值，条目在新鲜期与可用旧值时长之和后过期
+func (c *PolicyCache[T]) Set(ctx context.Context, key string, value T) error {
+	if c.policy.TTL <= 0 {
+		return nil
+	}
+	entry := &policyEntry[T]{
+		Value:      value,
+		FreshUntil: time.Now().Add(c.policy.TTL).UnixNano(),
+	}
+	return c.cache.Set(ctx, key, entry, c.policy.TTL+c.policy.StaleTTL)
+}
+
+// SetNotFound 缓存未找到的结果，仅缓存包装了ErrNotFound的错误，未配置否定缓存时忽略
+func (c *PolicyCache[T]) SetNotFound(ctx context.Context, key string, err error) error {
+	if c.policy.NegativeTTL <= 0 || !errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	entry := &policyEntry[T]{
+		NotFound:   err.Error(),
+		FreshUntil: time.Now().Add(c.policy.NegativeTTL).UnixNano(),
+	}
+	return c.cache.Set(ctx, key, entry, c.policy.NegativeTTL)
+}
+
+// ExpirePolicyEntry 重新设置按缓存策略写入的条目的过期时间，新鲜期截止时间同步改为ttl之后，
+// 使条目在新的过期时间内按新鲜值返回而不触发后台刷新；不是策略条目时返回false且不做修改
+func ExpirePolicyEntry(ctx context.Context, repo CacheRepository, key string, ttl time.Duration) (bool, error) {
+	raw, err := repo.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return false, nil
+	}
+
+	// 按字段原样保留值，只改写新鲜期截止时间
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, nil
+	}
+	if _, ok := fields["fresh_until"]; !ok {
+		return false, nil
+	}
+	freshUntil, err := json.Marshal(time.Now().Add(ttl).UnixNano())
+	if err != nil {
+		return false, err
+	}
+	fields["fresh_until"] = freshUntil
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return false, fmt.Errorf("缓存数据编码失败: %w", err)
+	}
+	if err := repo.Set(ctx, key, string(data), ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
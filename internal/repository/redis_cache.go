@@ -0,0 +1,438 @@
+// Package repository Redis缓存仓库实现
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/resp"
+)
+
+// redisScanCount 遍历键时每批SCAN的数量
+const redisScanCount = 500
+
+// redisCacheRepository Redis缓存仓库实现，多个副本共享同一份缓存
+// 服务器不可达时回退到进程内的内存缓存，并在后台定期探测，恢复后切回Redis
+type redisCacheRepository struct {
+	client    *resp.Client
+	addr      string
+	prefix    string
+	interval  time.Duration
+	fallback  CacheRepository
+	available atomic.Bool
+	logger    logger.Logger
+
+	closeOnce sync.Once
+	done      chan struct{}
+
+	// 统计信息
+	stats struct {
+		hits      atomic.Int64
+		misses    atomic.Int64
+		sets      atomic.Int64
+		deletes   atomic.Int64
+		errors    atomic.Int64
+		fallbacks atomic.Int64
+	}
+}
+
+// NewRedisCacheRepository 创建Redis缓存仓库，启动时服务器不可达不会报错，而是先使用fallback
+func NewRedisCacheRepository(cfg *config.RedisConfig, fallback CacheRepository, log logger.Logger) CacheRepository {
+	r := &redisCacheRepository{
+		client: resp.NewClient(resp.Options{
+			Addr:         cfg.Addr,
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+		}),
+		addr:     cfg.Addr,
+		prefix:   cfg.KeyPrefix,
+		interval: cfg.HealthCheckInterval,
+		fallback: fallback,
+		logger:   log,
+		done:     make(chan struct{}),
+	}
+	r.available.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout+cfg.ReadTimeout)
+	defer cancel()
+	if err := r.client.Ping(ctx); err != nil {
+		r.markUnavailable(err)
+	} else {
+		log.Info("Redis缓存已连接",
+			logger.String("addr", cfg.Addr),
+			logger.Int("db", cfg.DB),
+		)
+	}
+	return r
+}
+
+// Get 获取缓存
+func (r *redisCacheRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	if !r.available.Load() {
+		return r.fallback.Get(ctx, key)
+	}
+
+	v, err := r.do(ctx, "GET", r.prefix+key)
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.Get(ctx, key)
+		}
+		return nil, err
+	}
+	if v.Null {
+		r.stats.misses.Add(1)
+		return nil, fmt.Errorf("缓存键不存在: %s", key)
+	}
+
+	r.stats.hits.Add(1)
+	return v.Str, nil
+}
+
+// Set 设置缓存，非字符串值序列化为JSON后存储，ttl不大于0时永不过期
+func (r *redisCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if !r.available.Load() {
+		return r.fallback.Set(ctx, key, value, ttl)
+	}
+
+	data, err := cacheValueBytes(value)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"SET", r.prefix + key, string(data)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	if _, err := r.do(ctx, args...); err != nil {
+		if r.unreachable(err) {
+			return r.fallback.Set(ctx, key, value, ttl)
+		}
+		return err
+	}
+
+	r.stats.sets.Add(1)
+	return nil
+}
+
+// Delete 删除缓存
+func (r *redisCacheRepository) Delete(ctx context.Context, key string) error {
+	if !r.available.Load() {
+		return r.fallback.Delete(ctx, key)
+	}
+
+	v, err := r.do(ctx, "DEL", r.prefix+key)
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.Delete(ctx, key)
+		}
+		return err
+	}
+	if v.Int == 0 {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+
+	r.stats.deletes.Add(1)
+	return nil
+}
+
+// Exists 检查缓存是否存在
+func (r *redisCacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	if !r.available.Load() {
+		return r.fallback.Exists(ctx, key)
+	}
+
+	v, err := r.do(ctx, "EXISTS", r.prefix+key)
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.Exists(ctx, key)
+		}
+		return false, err
+	}
+	return v.Int > 0, nil
+}
+
+// Clear 清空本服务的缓存，只删除带键前缀的键，不影响共用服务器的其他数据
+func (r *redisCacheRepository) Clear(ctx context.Context) error {
+	if err := r.fallback.Clear(ctx); err != nil {
+		return err
+	}
+	if !r.available.Load() {
+		return nil
+	}
+
+	count := 0
+	err := r.scan(ctx, escapeGlob(r.prefix)+"*", func(keys []string) error {
+		args := append([]string{"UNLINK"}, keys...)
+		if _, err := r.do(ctx, args...); err != nil {
+			return err
+		}
+		count += len(keys)
+		return nil
+	})
+	if err != nil {
+		if r.unreachable(err) {
+			return nil
+		}
+		return fmt.Errorf("清空Redis缓存失败: %w", err)
+	}
+
+	r.logger.Info("清空缓存",
+		logger.String("type", "redis"),
+		logger.Int("cleared_count", count),
+	)
+	return nil
+}
+
+// GetKeys 获取匹配模式的键，支持Redis的glob语法，返回的键不含键前缀
+func (r *redisCacheRepository) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	if !r.available.Load() {
+		return r.fallback.GetKeys(ctx, pattern)
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var keys []string
+	err := r.scan(ctx, escapeGlob(r.prefix)+pattern, func(batch []string) error {
+		for _, key := range batch {
+			keys = append(keys, strings.TrimPrefix(key, r.prefix))
+		}
+		return nil
+	})
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.GetKeys(ctx, pattern)
+		}
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetTTL 获取剩余过期时间，永不过期的键返回0
+func (r *redisCacheRepository) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	if !r.available.Load() {
+		return r.fallback.GetTTL(ctx, key)
+	}
+
+	v, err := r.do(ctx, "PTTL", r.prefix+key)
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.GetTTL(ctx, key)
+		}
+		return 0, err
+	}
+	switch {
+	case v.Int == -2:
+		return 0, fmt.Errorf("缓存键不存在: %s", key)
+	case v.Int < 0:
+		return 0, nil
+	default:
+		return time.Duration(v.Int) * time.Millisecond, nil
+	}
+}
+
+// Expire 设置过期时间
+func (r *redisCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if !r.available.Load() {
+		return r.fallback.Expire(ctx, key, ttl)
+	}
+
+	v, err := r.do(ctx, "PEXPIRE", r.prefix+key, strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	if err != nil {
+		if r.unreachable(err) {
+			return r.fallback.Expire(ctx, key, ttl)
+		}
+		return err
+	}
+	if v.Int == 0 {
+		return fmt.Errorf("缓存键不存在: %s", key)
+	}
+	return nil
+}
+
+// GetStats 获取缓存统计，回退期间附带内存缓存的统计
+func (r *redisCacheRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	hits, misses := r.stats.hits.Load(), r.stats.misses.Load()
+	hitRate := float64(0)
+	totalRequests := hits + misses
+	if totalRequests > 0 {
+		hitRate = float64(hits) / float64(totalRequests) * 100
+	}
+
+	pool := r.client.Stats()
+	stats := map[string]interface{}{
+		"type":           "redis",
+		"addr":           r.addr,
+		"available":      r.available.Load(),
+		"hits":           hits,
+		"misses":         misses,
+		"sets":           r.stats.sets.Load(),
+		"deletes":        r.stats.deletes.Load(),
+		"errors":         r.stats.errors.Load(),
+		"fallbacks":      r.stats.fallbacks.Load(),
+		"hit_rate":       hitRate,
+		"total_requests": totalRequests,
+		"pool_open":      pool.Open,
+		"pool_idle":      pool.Idle,
+	}
+
+	if r.available.Load() {
+		if v, err := r.do(ctx, "DBSIZE"); err == nil {
+			stats["db_size"] = v.Int
+		}
+	}
+	if !r.available.Load() {
+		if fallbackStats, err := r.fallback.GetStats(ctx); err == nil {
+			stats["fallback"] = fallbackStats
+		}
+	}
+	return stats, nil
+}
+
+// Close 停止后台探测，关闭连接池与回退缓存
+func (r *redisCacheRepository) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+	if closer, ok := r.fallback.(io.Closer); ok {
+		closer.Close()
+	}
+	return r.client.Close()
+}
+
+// do 执行命令，连接失败时切换到内存缓存；客户端取消请求与服务器的错误应答不影响可用状态
+func (r *redisCacheRepository) do(ctx context.Context, args ...string) (resp.Value, error) {
+	v, err := r.client.Do(ctx, args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return v, ctx.Err()
+		}
+		r.stats.errors.Add(1)
+		if r.unreachable(err) {
+			r.markUnavailable(err)
+		}
+	}
+	return v, err
+}
+
+// unreachable 判断错误是否表示服务器不可达
+func (r *redisCacheRepository) unreachable(err error) bool {
+	var reply resp.Error
+	if errors.As(err, &reply) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return !errors.Is(err, resp.ErrClosed)
+}
+
+// scan 按模式遍历键，每批调用一次fn
+func (r *redisCacheRepository) scan(ctx context.Context, match string, fn func(keys []string) error) error {
+	cursor := "0"
+	for {
+		v, err := r.do(ctx, "SCAN", cursor, "MATCH", match, "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			return err
+		}
+		if len(v.Array) != 2 {
+			return fmt.Errorf("%w: SCAN应答格式错误", resp.ErrProtocol)
+		}
+
+		var keys []string
+		for _, item := range v.Array[1].Array {
+			keys = append(keys, item.Str)
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		cursor = v.Array[0].String()
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// markUnavailable 标记服务器不可用并开始后台探测
+func (r *redisCacheRepository) markUnavailable(err error) {
+	if !r.available.CompareAndSwap(true, false) {
+		return
+	}
+	r.stats.fallbacks.Add(1)
+	r.logger.Warn("Redis缓存不可用，回退到内存缓存",
+		logger.String("addr", r.addr),
+		logger.ErrorField("error", err),
+	)
+	go r.probe()
+}
+
+// probe 定期探测服务器，恢复后切回Redis
+func (r *redisCacheRepository) probe() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+			err := r.client.Ping(ctx)
+			cancel()
+			if err != nil {
+				r.logger.Debug("Redis缓存仍不可用",
+					logger.String("addr", r.addr),
+					logger.ErrorField("error", err),
+				)
+				continue
+			}
+
+			r.available.Store(true)
+			r.logger.Info("Redis缓存已恢复",
+				logger.String("addr", r.addr),
+			)
+			return
+		}
+	}
+}
+
+// cacheValueBytes 获取缓存值的存储形式，字符串与字节切片原样存储，其他值序列化为JSON
+func cacheValueBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("序列化缓存值失败: %w", err)
+		}
+		return data, nil
+	}
+}
+
+// escapeGlob 转义glob特殊字符，使键前缀按字面匹配
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(`*?[]\`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/resp"
+)
+
+// testLogger 创建只输出致命错误的日志器
+func testLogger(t *testing.T) logger.Logger {
+	t.Helper()
+	cfg := logger.DefaultConfig()
+	cfg.Level = logger.FatalLevel
+	log, err := logger.NewLogger(cfg)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	return log
+}
+
+// newTestRedisCache 创建连接到addr的Redis缓存仓库，测试结束时关闭
+func newTestRedisCache(t *testing.T, addr, prefix string) *redisCacheRepository {
+	t.Helper()
+	log := testLogger(t)
+	cfg := &config.RedisConfig{
+		Addr:                addr,
+		KeyPrefix:           prefix,
+		PoolSize:            4,
+		DialTimeout:         200 * time.Millisecond,
+		ReadTimeout:         200 * time.Millisecond,
+		WriteTimeout:        200 * time.Millisecond,
+		HealthCheckInterval: 20 * time.Millisecond,
+	}
+	repo := NewRedisCacheRepository(cfg, NewMemoryCacheRepository(1<<20, time.Minute, log), log).(*redisCacheRepository)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// startRESPServer 启动RESP替身服务器，测试结束时关闭
+func startRESPServer(t *testing.T) *resp.Server {
+	t.Helper()
+	server := resp.NewTestServer()
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// unusedAddr 获取一个当前无人监听的本地地址
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRedisCacheGetSetDelete(t *testing.T) {
+	server := startRESPServer(t)
+	repo := newTestRedisCache(t, server.Addr(), "test:")
+	ctx := context.Background()
+
+	if err := repo.Set(ctx, "str", "你好", 0); err != nil {
+		t.Fatalf("Set失败: %v", err)
+	}
+	if err := repo.Set(ctx, "obj", map[string]int{"n": 1}, 0); err != nil {
+		t.Fatalf("Set失败: %v", err)
+	}
+
+	if got, err := repo.Get(ctx, "str"); err != nil || got != "你好" {
+		t.Errorf("Get(str) = %v, %v", got, err)
+	}
+	if got, err := repo.Get(ctx, "obj"); err != nil || got != `{"n":1}` {
+		t.Errorf("Get(obj) = %v, %v，期望JSON字符串", got, err)
+	}
+	if _, err := repo.Get(ctx, "missing"); err == nil {
+		t.Error("Get不存在的键应返回错误")
+	}
+
+	if ok, err := repo.Exists(ctx, "str"); err != nil || !ok {
+		t.Errorf("Exists(str) = %v, %v", ok, err)
+	}
+	if err := repo.Delete(ctx, "str"); err != nil {
+		t.Fatalf("Delete失败: %v", err)
+	}
+	if ok, _ := repo.Exists(ctx, "str"); ok {
+		t.Error("删除后键仍存在")
+	}
+	if err := repo.Delete(ctx, "str"); err == nil {
+		t.Error("删除不存在的键应返回错误")
+	}
+
+	stats, err := repo.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats失败: %v", err)
+	}
+	if stats["available"] != true || stats["hits"] != int64(2) || stats["misses"] != int64(1) || stats["db_size"] != int64(1) {
+		t.Errorf("统计 = %v", stats)
+	}
+}
+
+func TestRedisCacheTTLAndExpire(t *testing.T) {
+	server := startRESPServer(t)
+	repo := newTestRedisCache(t, server.Addr(), "test:")
+	ctx := context.Background()
+
+	repo.Set(ctx, "persistent", "v", 0)
+	if ttl, err := repo.GetTTL(ctx, "persistent"); err != nil || ttl != 0 {
+		t.Errorf("永不过期的键 GetTTL = %v, %v，期望 0", ttl, err)
+	}
+
+	repo.Set(ctx, "timed", "v", time.Minute)
+	if ttl, err := repo.GetTTL(ctx, "timed"); err != nil || ttl <= 59*time.Second || ttl > time.Minute {
+		t.Errorf("GetTTL = %v, %v，期望约1分钟", ttl, err)
+	}
+
+	if err := repo.Expire(ctx, "persistent", time.Hour); err != nil {
+		t.Fatalf("Expire失败: %v", err)
+	}
+	if ttl, err := repo.GetTTL(ctx, "persistent"); err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("Expire后 GetTTL = %v, %v，期望约1小时", ttl, err)
+	}
+
+	if err := repo.Expire(ctx, "missing", time.Hour); err == nil {
+		t.Error("Expire不存在的键应返回错误")
+	}
+	if _, err := repo.GetTTL(ctx, "missing"); err == nil {
+		t.Error("GetTTL不存在的键应返回错误")
+	}
+
+	if err := repo.Expire(ctx, "timed", 30*time.Millisecond); err != nil {
+		t.Fatalf("Expire失败: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := repo.Get(ctx, "timed"); err == nil {
+		t.Error("过期的键仍可读取")
+	}
+	if ok, _ := repo.Exists(ctx, "timed"); ok {
+		t.Error("过期的键仍存在")
+	}
+}
+
+func TestRedisCacheKeys(t *testing.T) {
+	server := startRESPServer(t)
+	repo := newTestRedisCache(t, server.Addr(), "test:")
+	ctx := context.Background()
+
+	for _, key := range []string{"unm:match:1", "unm:match:2", "unm:search:晴天", "other"} {
+		repo.Set(ctx, key, "v", 0)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"unm:match:*", []string{"unm:match:1", "unm:match:2"}},
+		{"unm:*", []string{"unm:match:1", "unm:match:2", "unm:search:晴天"}},
+		{"unm:match:?", []string{"unm:match:1", "unm:match:2"}},
+		{"*", []string{"other", "unm:match:1", "unm:match:2", "unm:search:晴天"}},
+		{"", []string{"other", "unm:match:1", "unm:match:2", "unm:search:晴天"}},
+		{"none:*", nil},
+	}
+	for _, tt := range tests {
+		keys, err := repo.GetKeys(ctx, tt.pattern)
+		if err != nil {
+			t.Fatalf("GetKeys(%q)失败: %v", tt.pattern, err)
+		}
+		sort.Strings(keys)
+		if !equalStrings(keys, tt.want) {
+			t.Errorf("GetKeys(%q) = %v，期望 %v", tt.pattern, keys, tt.want)
+		}
+	}
+}
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	server := startRESPServer(t)
+	ctx := context.Background()
+
+	// 前缀中的glob字符按字面匹配，不会匹配到其他前缀的键
+	app := newTestRedisCache(t, server.Addr(), "app[1]*:")
+	other := newTestRedisCache(t, server.Addr(), "app1x:")
+
+	app.Set(ctx, "k", "app", 0)
+	other.Set(ctx, "k", "other", 0)
+
+	raw := resp.NewClient(resp.Options{Addr: server.Addr()})
+	defer raw.Close()
+	if v, err := raw.Do(ctx, "GET", "app[1]*:k"); err != nil || v.Str != "app" {
+		t.Errorf("服务器中的键 = %v, %v，期望带前缀存储", v, err)
+	}
+
+	if got, _ := app.Get(ctx, "k"); got != "app" {
+		t.Errorf("app Get(k) = %v", got)
+	}
+	if got, _ := other.Get(ctx, "k"); got != "other" {
+		t.Errorf("other Get(k) = %v", got)
+	}
+	if keys, _ := app.GetKeys(ctx, "*"); !equalStrings(keys, []string{"k"}) {
+		t.Errorf("app GetKeys = %v，期望不含前缀且不含其他前缀的键", keys)
+	}
+
+	if err := app.Clear(ctx); err != nil {
+		t.Fatalf("Clear失败: %v", err)
+	}
+	if ok, _ := app.Exists(ctx, "k"); ok {
+		t.Error("Clear后键仍存在")
+	}
+	if got, err := other.Get(ctx, "k"); err != nil || got != "other" {
+		t.Errorf("Clear影响了其他前缀的键: %v, %v", got, err)
+	}
+}
+
+func TestRedisCacheFallbackWhenUnavailableAtStart(t *testing.T) {
+	repo := newTestRedisCache(t, unusedAddr(t), "test:")
+	ctx := context.Background()
+
+	if repo.available.Load() {
+		t.Fatal("服务器不可达时应回退到内存缓存")
+	}
+	if err := repo.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("回退期间Set失败: %v", err)
+	}
+	if got, err := repo.Get(ctx, "k"); err != nil || got != "v" {
+		t.Errorf("回退期间Get = %v, %v", got, err)
+	}
+	if keys, _ := repo.GetKeys(ctx, "*"); !equalStrings(keys, []string{"k"}) {
+		t.Errorf("回退期间GetKeys = %v", keys)
+	}
+	if ttl, err := repo.GetTTL(ctx, "k"); err != nil || ttl <= 0 {
+		t.Errorf("回退期间GetTTL = %v, %v", ttl, err)
+	}
+
+	stats, _ := repo.GetStats(ctx)
+	if stats["available"] != false || stats["fallbacks"] != int64(1) || stats["fallback"] == nil {
+		t.Errorf("统计 = %v", stats)
+	}
+}
+
+func TestRedisCacheFallbackAndRecovery(t *testing.T) {
+	server := startRESPServer(t)
+	addr := server.Addr()
+	repo := newTestRedisCache(t, addr, "test:")
+	ctx := context.Background()
+
+	if err := repo.Set(ctx, "shared", "redis", 0); err != nil {
+		t.Fatalf("Set失败: %v", err)
+	}
+
+	// 服务器停止后切换到内存缓存，读写不报错
+	server.Close()
+	if _, err := repo.Get(ctx, "shared"); err == nil {
+		t.Error("回退后内存缓存中不应有Redis中的键")
+	}
+	if repo.available.Load() {
+		t.Fatal("服务器停止后应回退到内存缓存")
+	}
+	if err := repo.Set(ctx, "local", "memory", 0); err != nil {
+		t.Fatalf("回退期间Set失败: %v", err)
+	}
+	if got, err := repo.Get(ctx, "local"); err != nil || got != "memory" {
+		t.Errorf("回退期间Get = %v, %v", got, err)
+	}
+
+	// 服务器在原地址恢复后，后台探测切回Redis
+	restarted := resp.NewServer("")
+	if err := restarted.Start(addr); err != nil {
+		t.Skipf("无法在原地址重启替身服务器: %v", err)
+	}
+	defer restarted.Close()
+	waitFor(t, "切回Redis", repo.available.Load)
+
+	if err := repo.Set(ctx, "shared", "restored", 0); err != nil {
+		t.Fatalf("恢复后Set失败: %v", err)
+	}
+	raw := resp.NewClient(resp.Options{Addr: addr})
+	defer raw.Close()
+	if v, err := raw.Do(ctx, "GET", "test:shared"); err != nil || v.Str != "restored" {
+		t.Errorf("恢复后服务器中的值 = %v, %v", v, err)
+	}
+}
+
+// equalStrings 比较两个字符串切片，nil与空切片视为相等
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/textnorm"
+)
+
+// ScoreWeights 搜索结果评分权重
+type ScoreWeights struct {
+	Title          float64 // 标题相似度权重
+	Artist         float64 // 艺术家匹配权重
+	Album          float64 // 专辑匹配权重
+	ExactTitle     float64 // 标题与查询完全一致的加分
+	ExactArtist    float64 // 标题完全一致且艺术家命中时的额外加分
+	VersionPenalty float64 // 结果是否为现场、伴奏等特殊版本与查询不一致时的扣分
+	SourcePriority float64 // 音源优先级加分上限，优先级最高的音源获得全部加分
+}
+
+// DefaultScoreWeights 默认评分权重
+var DefaultScoreWeights = ScoreWeights{
+	Title:          0.6,
+	Artist:         0.25,
+	Album:          0.05,
+	ExactTitle:     0.15,
+	ExactArtist:    0.05,
+	VersionPenalty: 0.15,
+	SourcePriority: 0.05,
+}
+
+// RelevanceScorer 按查询对搜索结果评分
+type RelevanceScorer struct {
+	weights ScoreWeights
+}
+
+// NewRelevanceScorer 创建评分器
+func NewRelevanceScorer(weights ScoreWeights) *RelevanceScorer {
+	return &RelevanceScorer{weights: weights}
+}
+
+// ScoreResults 为搜索结果评分并填充Score与ScoreDetail
+// sourceRanks为音源名称到优先级排名（0为最高）的映射
+func (s *RelevanceScorer) ScoreResults(keyword string, results []*model.SearchResult, sourceRanks map[string]int) {
+	query := textnorm.Normalize(keyword)
+	wantsVersion := textnorm.IsVersionTag(keyword)
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		detail := s.score(query, wantsVersion, result)
+		detail.SourceBoost = s.sourceBoost(result.Source, sourceRanks)
+		detail.Total = round(detail.TitleScore*s.weights.Title +
+			detail.ArtistScore*s.weights.Artist +
+			detail.AlbumScore*s.weights.Album +
+			detail.ExactBoost + detail.SourceBoost - detail.VersionPenalty)
+
+		result.Score = detail.Total
+		result.ScoreDetail = detail
+	}
+}
+
+// score 计算标题、艺术家、专辑三项匹配度
+func (s *RelevanceScorer) score(query string, wantsVersion bool, result *model.SearchResult) *model.ScoreDetail {
+	title := textnorm.Key(result.Name)
+	_, extras := textnorm.StripDecorations(result.Name)
+	detail := &model.ScoreDetail{Query: query, Title: title}
+
+	// 查询中命中的艺术家从查询中移除，剩余部分与标题比较
+	residual := query
+	for _, artist := range textnorm.SplitArtists(result.Artist) {
+		if artist != title && textnorm.ContainsPhrase(residual, artist) {
+			residual = textnorm.RemovePhrase(residual, artist)
+			detail.ArtistScore = 1
+		}
+	}
+
+	// 查询中出现的版本、合作者信息（如 "live"、"feat xxx"）同样移除
+	hasVersion := false
+	for _, extra := range extras {
+		if textnorm.IsVersionTag(extra) {
+			hasVersion = true
+		}
+		if normalized := textnorm.Normalize(extra); textnorm.ContainsPhrase(residual, normalized) {
+			residual = textnorm.RemovePhrase(residual, normalized)
+		}
+	}
+
+	album := textnorm.Key(result.Album)
+	if album != "" && album != title && textnorm.ContainsPhrase(residual, album) {
+		residual = textnorm.RemovePhrase(residual, album)
+		detail.AlbumScore = 1
+	}
+
+	if residual == "" {
+		// 查询只包含艺术家或专辑时，标题无从比较
+		residual = query
+	}
+	detail.TitleScore = round(textnorm.Similarity(title, residual))
+	if detail.TitleScore < 1 && textnorm.ContainsPhrase(residual, title) {
+		// 标题完整出现在查询中，按覆盖比例给分
+		covered := float64(len([]rune(title))) / float64(len([]rune(residual)))
+		detail.TitleScore = round(math.Max(detail.TitleScore, 0.5+0.5*covered))
+	}
+
+	if title != "" && title == residual {
+		detail.ExactBoost = s.weights.ExactTitle
+		if detail.ArtistScore == 1 {
+			detail.ExactBoost += s.weights.ExactArtist
+		}
+	}
+
+	// 查询要求特殊版本而结果为原版，或查询未要求而结果为特殊版本
+	if hasVersion != wantsVersion {
+		detail.VersionPenalty = s.weights.VersionPenalty
+	}
+
+	return detail
+}
+
+// sourceBoost 按音源优先级排名计算加分
+func (s *RelevanceScorer) sourceBoost(source string, sourceRanks map[string]int) float64 {
+	rank, ok := sourceRanks[source]
+	if !ok || len(sourceRanks) == 0 {
+		return 0
+	}
+	return round(s.weights.SourcePriority * (1 - float64(rank)/float64(len(sourceRanks))))
+}
+
+// rankResults 去重并按评分排序，评分相同时保留音源优先级高的结果
+// 同一平台同一ID，或归一化后标题、艺术家、专辑均相同的结果视为重复
+func rankResults(results []*model.SearchResult, sourceRanks map[string]int) []*model.SearchResult {
+	better := func(a, b *model.SearchResult) bool {
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		rankA, okA := sourceRanks[a.Source]
+		rankB, okB := sourceRanks[b.Source]
+		if okA != okB {
+			return okA
+		}
+		return rankA < rankB
+	}
+
+	unique := make([]*model.SearchResult, 0, len(results))
+	index := make(map[string]int, len(results)*2)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		keys := []string{"meta:" + strings.Join([]string{
+			textnorm.Normalize(result.Name),
+			strings.Join(textnorm.SplitArtists(result.Artist), ","),
+			textnorm.Normalize(result.Album),
+		}, "|")}
+		if result.ID != "" {
+			keys = append(keys, "id:"+result.Platform+":"+result.ID)
+		}
+
+		pos := -1
+		for _, key := range keys {
+			if i, ok := index[key]; ok {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			pos = len(unique)
+			unique = append(unique, result)
+		} else if better(result, unique[pos]) {
+			unique[pos] = result
+		}
+		for _, key := range keys {
+			index[key] = pos
+		}
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		return better(unique[i], unique[j])
+	})
+	return unique
+}
+
+// round 保留四位小数，便于比较与展示
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,69 @@
+// Package repository 音源能力探测
+package repository
+
+import (
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+)
+
+// AsLyricProvider 获取音源的歌词能力
+// 按原始音源实现判断能力，返回的提供者仍经过包装器（如熔断器）
+func AsLyricProvider(source MusicSource) (LyricProvider, bool) {
+	if _, ok := UnwrapSource(source).(LyricProvider); !ok {
+		return nil, false
+	}
+	if !supports(source, model.SourceCapabilityLyric) {
+		return nil, false
+	}
+	provider, ok := source.(LyricProvider)
+	return provider, ok
+}
+
+// AsPictureProvider 获取音源的专辑图能力
+// 按原始音源实现判断能力，返回的提供者仍经过包装器（如熔断器）
+func AsPictureProvider(source MusicSource) (PictureProvider, bool) {
+	if _, ok := UnwrapSource(source).(PictureProvider); !ok {
+		return nil, false
+	}
+	if !supports(source, model.SourceCapabilityPicture) {
+		return nil, false
+	}
+	provider, ok := source.(PictureProvider)
+	return provider, ok
+}
+
+// SupportedQualities 获取音源声明的音质列表，未声明时返回nil
+func SupportedQualities(source MusicSource) []string {
+	if lister, ok := UnwrapSource(source).(QualityLister); ok {
+		return lister.GetSupportedQualities()
+	}
+	return nil
+}
+
+// SourceCapabilities 获取音源支持的能力列表
+func SourceCapabilities(source MusicSource) []string {
+	capabilities := make([]string, 0, 5)
+	for _, capability := range []string{
+		model.SourceCapabilityMusic,
+		model.SourceCapabilitySearch,
+		model.SourceCapabilityInfo,
+	} {
+		if supports(source, capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	if _, ok := AsLyricProvider(source); ok {
+		capabilities = append(capabilities, model.SourceCapabilityLyric)
+	}
+	if _, ok := AsPictureProvider(source); ok {
+		capabilities = append(capabilities, model.SourceCapabilityPicture)
+	}
+	return capabilities
+}
+
+// supports 检查音源是否声明支持指定能力，未实现CapabilityChecker的音源视为支持
+func supports(source MusicSource, capability string) bool {
+	if checker, ok := UnwrapSource(source).(CapabilityChecker); ok {
+		return checker.SupportsCapability(capability)
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/repository/sources"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/metrics"
 )
 
 // DefaultSourceManager 默认音源管理器实现
@@ -23,6 +25,7 @@ type DefaultSourceManager struct {
 	config       *model.SourcesConfigModel // 音源配置
 	infoProvider *MusicInfoProvider     // 音乐信息提供者
 	infoResolver *MusicInfoResolver     // 音乐信息解析器
+	scorer       *RelevanceScorer       // 搜索结果评分器
 }
 
 // NewDefaultSourceManager 创建默认音源管理器
@@ -34,6 +37,7 @@ func NewDefaultSourceManager(httpClient HTTPClient, config *model.SourcesConfigM
 		config:       config,
 		infoProvider: NewMusicInfoProvider(cache, log),
 		infoResolver: NewMusicInfoResolver(&config.MusicInfoResolver, &config.GDStudio, log),
+		scorer:       NewRelevanceScorer(DefaultScoreWeights),
 	}
 
 	// 初始化所有音源
@@ -94,11 +98,104 @@ func (sm *DefaultSourceManager) initializeSources() {
 		}
 	}
 
+	// 初始化声明式HTTP音源
+	if sm.config != nil {
+		for i := range sm.config.HTTPSources {
+			httpConfig := sm.config.HTTPSources[i]
+			if !httpConfig.Enabled {
+				continue
+			}
+			httpSource, err := sources.NewHTTPSource(newHTTPSourceConfig(httpConfig), timeout, sm.logger)
+			if err != nil {
+				sm.logger.Error("创建HTTP音源失败",
+					logger.String("name", httpConfig.Name),
+					logger.ErrorField("error", err),
+				)
+				continue
+			}
+			if err := sm.RegisterSource(httpSource); err != nil {
+				sm.logger.Error("注册HTTP音源失败",
+					logger.String("name", httpConfig.Name),
+					logger.ErrorField("error", err),
+				)
+			} else {
+				sm.logger.Info("HTTP音源注册成功",
+					logger.String("name", httpConfig.Name),
+					logger.String("base_url", httpConfig.BaseURL),
+				)
+			}
+		}
+	}
+
+	// 初始化本地曲库音源
+	if sm.config != nil && sm.config.Local.Enabled {
+		localConfig := &config.LocalSourceConfig{
+			Enabled:      sm.config.Local.Enabled,
+			Directory:    sm.config.Local.Directory,
+			PublicURL:    sm.config.Local.PublicURL,
+			Extensions:   sm.config.Local.Extensions,
+			Priority:     sm.config.Local.Priority,
+			Watch:        sm.config.Local.Watch,
+			ReindexDelay: sm.config.Local.ReindexDelay,
+		}
+		localSource, err := sources.NewLocalSource(localConfig, sm.logger)
+		if err != nil {
+			sm.logger.Error("创建本地曲库音源失败",
+				logger.String("directory", sm.config.Local.Directory),
+				logger.ErrorField("error", err),
+			)
+		} else if err := sm.RegisterSource(localSource); err != nil {
+			localSource.Close()
+			sm.logger.Error("注册本地曲库音源失败",
+				logger.ErrorField("error", err),
+			)
+		} else {
+			sm.logger.Info("本地曲库音源注册成功",
+				logger.String("directory", sm.config.Local.Directory),
+				logger.Int("tracks", localSource.TrackCount()),
+			)
+		}
+	}
+
 	sm.logger.Info("第三方API音源初始化完成",
 		logger.Int("count", len(sm.sources)),
 	)
 }
 
+// newHTTPSourceConfig 将声明式HTTP音源配置模型转换为音源配置
+func newHTTPSourceConfig(m model.HTTPSourceConfigModel) *config.HTTPSourceConfig {
+	operations := make(map[string]config.HTTPSourceOperationConfig, len(m.Operations))
+	for name, op := range m.Operations {
+		operations[name] = config.HTTPSourceOperationConfig{
+			Method:  op.Method,
+			URL:     op.URL,
+			Headers: op.Headers,
+			Body:    op.Body,
+			Results: op.Results,
+			Fields:  op.Fields,
+			Success: config.HTTPSourceSuccessConfig{
+				Status: op.Success.Status,
+				Path:   op.Success.Path,
+				Equals: op.Success.Equals,
+			},
+		}
+	}
+
+	return &config.HTTPSourceConfig{
+		Name:        m.Name,
+		Enabled:     m.Enabled,
+		Priority:    m.Priority,
+		BaseURL:     m.BaseURL,
+		APIKey:      m.APIKey,
+		UserAgent:   m.UserAgent,
+		Timeout:     m.Timeout,
+		Headers:     m.Headers,
+		Qualities:   m.Qualities,
+		FixEncoding: m.FixEncoding,
+		Operations:  operations,
+	}
+}
+
 // RegisterSource 注册音源
 func (sm *DefaultSourceManager) RegisterSource(source MusicSource) error {
 	if source == nil {
@@ -116,6 +213,11 @@ func (sm *DefaultSourceManager) RegisterSource(source MusicSource) error {
 	if _, exists := sm.sources[name]; exists {
 		return fmt.Errorf("音源 %s 已存在", name)
 	}
+
+	// 启用熔断器时包装音源
+	if sm.config != nil && sm.config.CircuitBreaker.Enabled && breakerOf(source) == nil {
+		source = NewCircuitBreakerSource(source, NewCircuitBreaker(name, sm.config.CircuitBreaker, sm.logger))
+	}
 	
 	sm.sources[name] = source
 	sm.logger.Info("音源注册成功", logger.String("source", name))
@@ -238,91 +340,414 @@ func (sm *DefaultSourceManager) MatchMusic(ctx context.Context, id string, sourc
 	if len(sources) == 0 {
 		return nil, fmt.Errorf("没有可用的音源")
 	}
+
+	// 跳过熔断器已打开的音源
+	sources, skipped := sm.filterBrokenSources(sources)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("所有音源熔断器均已打开，音源暂不可用")
+	}
 	
 	// 设置默认音质
 	if quality == "" {
-		quality = "320"
+		quality = model.DefaultQuality
 	}
 	
+	// 解析匹配策略与音质协商策略
+	strategy := sm.resolveMatchStrategy(ctx)
+	policy := model.QualityPolicyFromContext(ctx)
+	ladder := model.QualityLadder(quality, policy)
+
 	sm.logger.Info("开始音乐匹配",
 		logger.String("id", id),
+		logger.String("platform", model.PlatformFromContext(ctx)),
 		logger.String("quality", quality),
+		logger.String("quality_policy", policy),
+		logger.Any("quality_ladder", ladder),
+		logger.String("strategy", strategy),
 		logger.Int("source_count", len(sources)),
 	)
 	
-	// 依次尝试每个音源
-	for _, source := range sources {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	// 音源实际返回的音质需满足协商策略
+	accept := func(musicURL *model.MusicURL) error {
+		if !model.AcceptsQuality(musicURL.Quality, quality, policy) {
+			return fmt.Errorf("%w: 期望 %s（%s），实际 %s", ErrQualityMismatch, quality, policy, musicURL.Quality)
 		}
+		return nil
+	}
+	
+	// 沿音质阶梯逐级尝试，每一级按匹配策略尝试声明支持该音质的音源
+	// missed记录已尝试的音质是否都由音源明确答复没有该曲目，有音源因熔断被跳过时不能断定未找到
+	var (
+		source   MusicSource
+		musicURL *model.MusicURL
+		err      error
+		tried    bool
+		missed   = !skipped
+	)
+	for _, rung := range ladder {
+		candidates := sourcesSupportingQuality(sources, rung)
+		if len(candidates) == 0 {
+			continue
+		}
+		tried = true
 		
-		start := time.Now()
-		musicURL, err := source.GetMusic(ctx, id, quality)
-		duration := time.Since(start)
+		switch strategy {
+		case model.MatchStrategyParallel:
+			source, musicURL, err = sm.matchConcurrently(ctx, id, candidates, rung, strategy, 0, accept)
+		case model.MatchStrategyHedged:
+			source, musicURL, err = sm.matchConcurrently(ctx, id, candidates, rung, strategy, sm.hedgeDelay(), accept)
+		default:
+			source, musicURL, err = sm.matchSequentially(ctx, id, candidates, rung, strategy, accept)
+		}
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ErrNotFound) {
+			missed = false
+		}
 		
-		if err != nil {
-			sm.logger.Warn("音源匹配失败",
-				logger.String("source", source.GetName()),
+		sm.logger.Info("当前音质无可用音源，尝试下一级音质",
+			logger.String("id", id),
+			logger.String("quality", rung),
+			logger.String("quality_policy", policy),
+		)
+	}
+	if err != nil || musicURL == nil {
+		if tried && missed {
+			return nil, fmt.Errorf("%w音乐ID: %s（期望音质 %s，协商策略 %s）", ErrNotFound, id, quality, policy)
+		}
+		return nil, fmt.Errorf("所有音源都无法匹配音乐ID: %s（期望音质 %s，协商策略 %s）", id, quality, policy)
+	}
+	
+	// 构建响应，实际音质归一化为支持的音质标识
+	delivered := musicURL.Quality
+	if normalized := model.NormalizeQuality(delivered); normalized != "" {
+		delivered = normalized
+	}
+	format := musicURL.Format
+	if info := model.GetQualityInfo(delivered); format == "" && info != nil {
+		format = info.Format
+	}
+	
+	response := &model.MatchResponse{
+		ID:               id,
+		URL:              musicURL.URL,
+		ProxyURL:         musicURL.ProxyURL,
+		Quality:          delivered,
+		RequestedQuality: quality,
+		QualityPolicy:    policy,
+		Format:           format,
+		Size:             musicURL.Size,
+		MD5:              musicURL.MD5,
+		Source:           source.GetName(),
+		Platform:         model.PlatformFromContext(ctx),
+	}
+
+	// 获取音乐信息（优先使用音乐信息解析器）
+	if musicURL.Info != nil {
+		response.Info = musicURL.Info
+	} else if sm.infoResolver != nil {
+		if info, err := sm.infoResolver.ResolveMusicInfo(ctx, id); err == nil {
+			response.Info = info
+			sm.logger.Info("使用音乐信息解析器获取到真实信息",
+				logger.String("id", id),
+				logger.String("name", info.Name),
+				logger.String("artist", info.Artist),
+			)
+		} else {
+			sm.logger.Warn("音乐信息解析器获取失败，尝试其他方式",
 				logger.String("id", id),
-				logger.String("duration", duration.String()),
 				logger.ErrorField("error", err),
 			)
+		}
+	}
+
+	// 如果解析器失败，尝试其他方式
+	if response.Info == nil {
+		if sm.infoProvider != nil {
+			if info, err := sm.infoProvider.GetMusicInfo(ctx, id, []MusicSource{source}); err == nil {
+				response.Info = info
+			}
+		} else if info, err := source.GetMusicInfo(ctx, id); err == nil {
+			response.Info = info
+		}
+	}
+
+	return response, nil
+}
+
+// matchAttempt 单个音源的匹配尝试结果
+type matchAttempt struct {
+	source   MusicSource
+	musicURL *model.MusicURL
+	err      error
+	duration time.Duration
+}
+
+// succeeded 检查匹配尝试是否获得有效链接
+func (a *matchAttempt) succeeded() bool {
+	return a.err == nil && a.musicURL != nil && a.musicURL.URL != ""
+}
+
+// definitive 检查失败的尝试是否为音源明确答复没有可用链接，而非请求失败或被熔断器拒绝
+func (a *matchAttempt) definitive() bool {
+	if a.err == nil {
+		return !a.succeeded()
+	}
+	return errors.Is(a.err, sources.ErrNoMusicURL) || errors.Is(a.err, ErrQualityMismatch)
+}
+
+// ErrQualityMismatch 音源返回的音质不满足协商策略
+var ErrQualityMismatch = errors.New("音质不满足要求")
+
+// ErrNotFound 所有音源都明确答复不存在所请求的资源，区别于请求失败，该结果可以被缓存
+var ErrNotFound = errors.New("所有音源均未找到")
+
+// matchFailure 生成所有音源都匹配失败的错误，所有音源都明确答复没有该曲目时包装ErrNotFound
+func matchFailure(id string, missed bool) error {
+	if missed {
+		return fmt.Errorf("%w音乐ID: %s", ErrNotFound, id)
+	}
+	return fmt.Errorf("所有音源都无法匹配音乐ID: %s", id)
+}
+
+// sourcesSupportingQuality 过滤出声明支持指定音质的音源，未声明音质列表的音源视为支持
+func sourcesSupportingQuality(sources []MusicSource, quality string) []MusicSource {
+	supported := make([]MusicSource, 0, len(sources))
+	for _, source := range sources {
+		qualities := SupportedQualities(source)
+		if len(qualities) == 0 {
+			supported = append(supported, source)
 			continue
 		}
-		
-		if musicURL != nil && musicURL.URL != "" {
-			sm.logger.Info("音源匹配成功",
-				logger.String("source", source.GetName()),
+		for _, q := range qualities {
+			if q == quality {
+				supported = append(supported, source)
+				break
+			}
+		}
+	}
+	return supported
+}
+
+// fetchMusic 从音源获取播放链接，并检查实际音质是否满足要求
+func fetchMusic(ctx context.Context, source MusicSource, id, quality string, accept func(*model.MusicURL) error) (*model.MusicURL, error) {
+	musicURL, err := source.GetMusic(ctx, id, quality)
+	if err == nil && musicURL != nil && musicURL.URL != "" && accept != nil {
+		err = accept(musicURL)
+	}
+	return musicURL, err
+}
+
+// resolveMatchStrategy 解析匹配策略，请求指定的策略优先于配置
+func (sm *DefaultSourceManager) resolveMatchStrategy(ctx context.Context) string {
+	if strategy := model.MatchStrategyFromContext(ctx); model.IsValidMatchStrategy(strategy) {
+		return strategy
+	}
+	if sm.config != nil && model.IsValidMatchStrategy(sm.config.MatchStrategy) {
+		return sm.config.MatchStrategy
+	}
+	return model.DefaultMatchStrategy
+}
+
+// hedgeDelay 获取对冲策略的启动延迟
+func (sm *DefaultSourceManager) hedgeDelay() time.Duration {
+	if sm.config != nil && sm.config.HedgeDelay > 0 {
+		return sm.config.HedgeDelay
+	}
+	return 500 * time.Millisecond
+}
+
+// matchSequentially 依次尝试每个音源，直到获得有效链接
+func (sm *DefaultSourceManager) matchSequentially(ctx context.Context, id string, sources []MusicSource, quality, strategy string, accept func(*model.MusicURL) error) (MusicSource, *model.MusicURL, error) {
+	missed := true
+	for _, source := range sources {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+
+		start := time.Now()
+		musicURL, err := fetchMusic(ctx, source, id, quality, accept)
+		attempt := &matchAttempt{source: source, musicURL: musicURL, err: err, duration: time.Since(start)}
+
+		if attempt.succeeded() {
+			sm.recordMatchAttempt(id, strategy, attempt, "won")
+			return source, musicURL, nil
+		}
+		sm.recordMatchAttempt(id, strategy, attempt, failureOutcome(attempt))
+		missed = missed && attempt.definitive()
+	}
+
+	return nil, nil, matchFailure(id, missed)
+}
+
+// matchConcurrently 并发尝试音源，首个有效链接胜出，其余请求通过上下文取消
+// delay为0时同时启动所有音源（并行竞速），否则每隔delay启动下一个音源（对冲），
+// 当前音源失败时立即启动下一个音源
+func (sm *DefaultSourceManager) matchConcurrently(ctx context.Context, id string, sources []MusicSource, quality, strategy string, delay time.Duration, accept func(*model.MusicURL) error) (MusicSource, *model.MusicURL, error) {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *matchAttempt, len(sources))
+	launched, pending := 0, 0
+	missed := true
+
+	launchNext := func() {
+		src := sources[launched]
+		launched++
+		pending++
+		go func() {
+			start := time.Now()
+			musicURL, err := fetchMusic(raceCtx, src, id, quality, accept)
+			results <- &matchAttempt{source: src, musicURL: musicURL, err: err, duration: time.Since(start)}
+		}()
+	}
+
+	// 并行竞速策略一次性启动所有音源
+	if delay <= 0 {
+		for launched < len(sources) {
+			launchNext()
+		}
+	}
+
+	for {
+		if pending == 0 {
+			if launched >= len(sources) {
+				break
+			}
+			launchNext()
+			continue
+		}
+
+		var timer *time.Timer
+		var hedgeC <-chan time.Time
+		if launched < len(sources) {
+			timer = time.NewTimer(delay)
+			hedgeC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			cancel()
+			go sm.drainMatchAttempts(id, strategy, results, pending)
+			return nil, nil, ctx.Err()
+
+		case <-hedgeC:
+			sm.logger.Debug("对冲启动下一个音源",
 				logger.String("id", id),
-				logger.String("duration", duration.String()),
+				logger.String("source", sources[launched].GetName()),
+				logger.Duration("delay", delay),
 			)
-			
-			// 构建响应
-			response := &model.MatchResponse{
-				ID:       id,
-				URL:      musicURL.URL,
-				ProxyURL: musicURL.ProxyURL,
-				Quality:  musicURL.Quality,
-				Source:   source.GetName(),
+			launchNext()
+
+		case attempt := <-results:
+			if timer != nil {
+				timer.Stop()
 			}
+			pending--
 
-			// 获取音乐信息（优先使用音乐信息解析器）
-			if musicURL.Info != nil {
-				response.Info = musicURL.Info
-			} else if sm.infoResolver != nil {
-				if info, err := sm.infoResolver.ResolveMusicInfo(ctx, id); err == nil {
-					response.Info = info
-					sm.logger.Info("使用音乐信息解析器获取到真实信息",
-						logger.String("id", id),
-						logger.String("name", info.Name),
-						logger.String("artist", info.Artist),
-					)
-				} else {
-					sm.logger.Warn("音乐信息解析器获取失败，尝试其他方式",
-						logger.String("id", id),
-						logger.ErrorField("error", err),
-					)
+			if attempt.succeeded() {
+				sm.recordMatchAttempt(id, strategy, attempt, "won")
+				// 取消其余请求并在后台记录落败的尝试
+				cancel()
+				if pending > 0 {
+					go sm.drainMatchAttempts(id, strategy, results, pending)
 				}
+				return attempt.source, attempt.musicURL, nil
 			}
 
-			// 如果解析器失败，尝试其他方式
-			if response.Info == nil {
-				if sm.infoProvider != nil {
-					if info, err := sm.infoProvider.GetMusicInfo(ctx, id, []MusicSource{source}); err == nil {
-						response.Info = info
-					}
-				} else if info, err := source.GetMusicInfo(ctx, id); err == nil {
-					response.Info = info
-				}
+			sm.recordMatchAttempt(id, strategy, attempt, failureOutcome(attempt))
+			missed = missed && attempt.definitive()
+			if launched < len(sources) {
+				launchNext()
 			}
-			
-			return response, nil
 		}
 	}
-	
-	return nil, fmt.Errorf("所有音源都无法匹配音乐ID: %s", id)
+
+	return nil, nil, matchFailure(id, missed)
+}
+
+// drainMatchAttempts 收集胜出后仍在进行的尝试结果并记录指标
+func (sm *DefaultSourceManager) drainMatchAttempts(id, strategy string, results <-chan *matchAttempt, pending int) {
+	for i := 0; i < pending; i++ {
+		attempt := <-results
+		switch {
+		case attempt.succeeded():
+			sm.recordMatchAttempt(id, strategy, attempt, "lost")
+		case errors.Is(attempt.err, context.Canceled):
+			sm.recordMatchAttempt(id, strategy, attempt, "cancelled")
+		default:
+			sm.recordMatchAttempt(id, strategy, attempt, failureOutcome(attempt))
+		}
+	}
+}
+
+// failureOutcome 区分失败的尝试是否被熔断器拒绝或音质不满足要求
+func failureOutcome(attempt *matchAttempt) string {
+	if errors.Is(attempt.err, ErrCircuitOpen) {
+		return "rejected"
+	}
+	if errors.Is(attempt.err, ErrQualityMismatch) {
+		return "quality_mismatch"
+	}
+	return "failed"
+}
+
+// filterBrokenSources 过滤掉熔断器当前不放行请求的音源，skipped表示是否有音源被过滤
+func (sm *DefaultSourceManager) filterBrokenSources(sources []MusicSource) (available []MusicSource, skipped bool) {
+	available = make([]MusicSource, 0, len(sources))
+	for _, source := range sources {
+		if breaker := breakerOf(source); breaker != nil && !breaker.Ready() {
+			sm.logger.Info("音源熔断器已打开，跳过该音源",
+				logger.String("source", source.GetName()),
+				logger.String("state", breaker.State()),
+			)
+			skipped = true
+			continue
+		}
+		available = append(available, source)
+	}
+	return available, skipped
+}
+
+// recordMatchAttempt 记录单个匹配尝试的日志与指标
+func (sm *DefaultSourceManager) recordMatchAttempt(id, strategy string, attempt *matchAttempt, outcome string) {
+	metrics.RecordMatchAttempt(strategy, attempt.source.GetName(), outcome, attempt.duration)
+
+	if outcome == "won" {
+		sm.logger.Info("音源匹配成功",
+			logger.String("source", attempt.source.GetName()),
+			logger.String("id", id),
+			logger.String("strategy", strategy),
+			logger.String("duration", attempt.duration.String()),
+		)
+		return
+	}
+
+	fields := []logger.Field{
+		logger.String("source", attempt.source.GetName()),
+		logger.String("id", id),
+		logger.String("strategy", strategy),
+		logger.String("outcome", outcome),
+		logger.String("duration", attempt.duration.String()),
+	}
+	if attempt.err != nil {
+		fields = append(fields, logger.ErrorField("error", attempt.err))
+	}
+
+	if outcome == "failed" || outcome == "quality_mismatch" {
+		sm.logger.Warn("音源匹配失败", fields...)
+	} else {
+		sm.logger.Debug("音源匹配落败", fields...)
+	}
 }
 
 // SearchMusic 使用多个音源搜索音乐
@@ -339,9 +764,24 @@ func (sm *DefaultSourceManager) SearchMusic(ctx context.Context, keyword string,
 		sources = sm.GetEnabledSources()
 	}
 	
+	// 跳过未声明搜索能力的音源
+	searchable := make([]MusicSource, 0, len(sources))
+	for _, source := range sources {
+		if supports(source, model.SourceCapabilitySearch) {
+			searchable = append(searchable, source)
+		}
+	}
+	sources = searchable
+
 	if len(sources) == 0 {
 		return nil, fmt.Errorf("没有可用的音源")
 	}
+
+	// 跳过熔断器已打开的音源
+	sources, _ = sm.filterBrokenSources(sources)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("所有音源熔断器均已打开，音源暂不可用")
+	}
 	
 	sm.logger.Info("开始音乐搜索",
 		logger.String("keyword", keyword),
@@ -383,25 +823,171 @@ func (sm *DefaultSourceManager) SearchMusic(ctx context.Context, keyword string,
 	}
 	
 	// 收集结果
+	var (
+		failed  int
+		lastErr error
+	)
 	for i := 0; i < len(sources); i++ {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case results := <-resultChan:
 			allResults = append(allResults, results...)
-		case <-errorChan:
+		case err := <-errorChan:
 			// 忽略单个音源的错误，继续处理其他音源
+			failed++
+			lastErr = err
 		}
 	}
+
+	// 所有音源都失败时返回错误，以免把上游故障当作没有搜索结果
+	if failed == len(sources) {
+		return nil, fmt.Errorf("所有音源搜索均失败: %w", lastErr)
+	}
 	
-	// 去重和排序
-	uniqueResults := sm.deduplicateResults(allResults)
-	sm.sortResultsByScore(uniqueResults)
+	// 评分、去重和排序，音源按搜索时的优先级顺序排名
+	sourceRanks := make(map[string]int, len(sources))
+	for i, source := range sources {
+		sourceRanks[source.GetName()] = i
+	}
+	sm.scorer.ScoreResults(keyword, allResults, sourceRanks)
 	
-	return uniqueResults, nil
+	return rankResults(allResults, sourceRanks), nil
+}
+
+// GetLyric 在支持歌词的音源间获取歌词，指定的音源优先，失败时回退到其他音源
+func (sm *DefaultSourceManager) GetLyric(ctx context.Context, lyricID string, sourceNames []string) (*model.LyricResult, error) {
+	if lyricID == "" {
+		return nil, fmt.Errorf("歌词ID不能为空")
+	}
+
+	sources, skipped := sm.capableSources(sourceNames, func(source MusicSource) bool {
+		_, ok := AsLyricProvider(source)
+		return ok
+	})
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("没有支持获取歌词的可用音源")
+	}
+
+	// missed记录是否所有音源都明确答复没有歌词，有音源因熔断被跳过或拒绝时不能断定未找到
+	var lastErr error
+	missed := !skipped
+	for _, source := range sources {
+		provider, _ := AsLyricProvider(source)
+
+		start := time.Now()
+		lyric, tlyric, err := provider.GetLyric(ctx, lyricID)
+		if err == nil && lyric == "" {
+			err = fmt.Errorf("音源 %s 未找到歌词", source.GetName())
+		} else if err != nil {
+			missed = false
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sm.logger.Warn("音源获取歌词失败，尝试下一个音源",
+				logger.String("source", source.GetName()),
+				logger.String("lyric_id", lyricID),
+				logger.String("duration", time.Since(start).String()),
+				logger.ErrorField("error", err),
+			)
+			lastErr = err
+			continue
+		}
+
+		sm.logger.Info("音源获取歌词成功",
+			logger.String("source", source.GetName()),
+			logger.String("lyric_id", lyricID),
+			logger.String("duration", time.Since(start).String()),
+		)
+		return &model.LyricResult{
+			Lyric:  lyric,
+			TLyric: tlyric,
+			Source: source.GetName(),
+		}, nil
+	}
+
+	if missed {
+		return nil, fmt.Errorf("%w歌词: %s", ErrNotFound, lyricID)
+	}
+	return nil, fmt.Errorf("所有音源都无法获取歌词: %s, 最后错误: %w", lyricID, lastErr)
+}
+
+// GetPicture 在支持专辑图的音源间获取专辑图，指定的音源优先，失败时回退到其他音源
+func (sm *DefaultSourceManager) GetPicture(ctx context.Context, picID string, size string, sourceNames []string) (*model.PictureResult, error) {
+	if picID == "" {
+		return nil, fmt.Errorf("专辑图ID不能为空")
+	}
+
+	sources, _ := sm.capableSources(sourceNames, func(source MusicSource) bool {
+		_, ok := AsPictureProvider(source)
+		return ok
+	})
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("没有支持获取专辑图的可用音源")
+	}
+
+	var lastErr error
+	for _, source := range sources {
+		provider, _ := AsPictureProvider(source)
+
+		start := time.Now()
+		picURL, err := provider.GetPicture(ctx, picID, size)
+		if err == nil && picURL == "" {
+			err = fmt.Errorf("音源 %s 未找到专辑图", source.GetName())
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sm.logger.Warn("音源获取专辑图失败，尝试下一个音源",
+				logger.String("source", source.GetName()),
+				logger.String("pic_id", picID),
+				logger.String("duration", time.Since(start).String()),
+				logger.ErrorField("error", err),
+			)
+			lastErr = err
+			continue
+		}
+
+		sm.logger.Info("音源获取专辑图成功",
+			logger.String("source", source.GetName()),
+			logger.String("pic_id", picID),
+			logger.String("duration", time.Since(start).String()),
+		)
+		return &model.PictureResult{
+			URL:    picURL,
+			Source: source.GetName(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("所有音源都无法获取专辑图: %s, 最后错误: %w", picID, lastErr)
 }
 
+// capableSources 获取具备指定能力且熔断器放行的启用音源，skipped表示是否有音源因熔断被跳过
+// 指定的音源按给定顺序排在最前，其余音源按优先级作为回退
+func (sm *DefaultSourceManager) capableSources(sourceNames []string, capable func(MusicSource) bool) (available []MusicSource, skipped bool) {
+	candidates := sm.GetSourcesByNames(sourceNames)
+	seen := make(map[string]bool, len(candidates))
+	for _, source := range candidates {
+		seen[source.GetName()] = true
+	}
+	for _, source := range sm.GetEnabledSources() {
+		if !seen[source.GetName()] {
+			candidates = append(candidates, source)
+		}
+	}
+
+	sources := make([]MusicSource, 0, len(candidates))
+	for _, source := range candidates {
+		if capable(source) {
+			sources = append(sources, source)
+		}
+	}
 
+	return sm.filterBrokenSources(sources)
+}
 
 // GetSourcesStatus 获取音源状态
 func (sm *DefaultSourceManager) GetSourcesStatus(ctx context.Context) ([]*model.SourceStatus, error) {
@@ -414,10 +1000,12 @@ func (sm *DefaultSourceManager) GetSourcesStatus(ctx context.Context) ([]*model.
 	for _, source := range sources {
 		go func(src MusicSource) {
 			status := &model.SourceStatus{
-				Name:      src.GetName(),
-				Enabled:   src.IsEnabled(),
-				Available: false,
-				LastCheck: time.Now(),
+				Name:         src.GetName(),
+				Enabled:      src.IsEnabled(),
+				Available:    false,
+				LastCheck:    time.Now(),
+				Capabilities: SourceCapabilities(src),
+				Qualities:    SupportedQualities(src),
 			}
 			
 			if src.IsEnabled() {
@@ -431,6 +1019,10 @@ func (sm *DefaultSourceManager) GetSourcesStatus(ctx context.Context) ([]*model.
 					status.Available = true
 				}
 			}
+
+			if breaker := breakerOf(src); breaker != nil {
+				status.CircuitBreaker = breaker.Status()
+			}
 			
 			statusChan <- status
 		}(source)
@@ -449,6 +1041,47 @@ func (sm *DefaultSourceManager) GetSourcesStatus(ctx context.Context) ([]*model.
 	return statuses, nil
 }
 
+// OpenCircuitBreaker 强制打开指定音源的熔断器
+func (sm *DefaultSourceManager) OpenCircuitBreaker(name string) (*model.CircuitBreakerStatus, error) {
+	breaker, err := sm.getCircuitBreaker(name)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker.ForceOpen()
+	sm.logger.Warn("音源熔断器已被强制打开", logger.String("source", name))
+
+	return breaker.Status(), nil
+}
+
+// ResetCircuitBreaker 重置指定音源的熔断器
+func (sm *DefaultSourceManager) ResetCircuitBreaker(name string) (*model.CircuitBreakerStatus, error) {
+	breaker, err := sm.getCircuitBreaker(name)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker.Reset()
+	sm.logger.Info("音源熔断器已重置", logger.String("source", name))
+
+	return breaker.Status(), nil
+}
+
+// getCircuitBreaker 获取指定音源的熔断器
+func (sm *DefaultSourceManager) getCircuitBreaker(name string) (*CircuitBreaker, error) {
+	source, err := sm.GetSource(name)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := breakerOf(source)
+	if breaker == nil {
+		return nil, fmt.Errorf("音源 %s 未启用熔断器", name)
+	}
+
+	return breaker, nil
+}
+
 // RefreshSources 刷新音源配置
 func (sm *DefaultSourceManager) RefreshSources() error {
 	sm.mu.Lock()
@@ -468,34 +1101,3 @@ func (sm *DefaultSourceManager) RefreshSources() error {
 	sm.logger.Info("音源配置刷新完成")
 	return nil
 }
-
-// deduplicateResults 去重搜索结果
-func (sm *DefaultSourceManager) deduplicateResults(results []*model.SearchResult) []*model.SearchResult {
-	seen := make(map[string]*model.SearchResult)
-	
-	for _, result := range results {
-		key := fmt.Sprintf("%s-%s-%s", result.Name, result.Artist, result.Album)
-		if existing, exists := seen[key]; exists {
-			// 保留评分更高的结果
-			if result.Score > existing.Score {
-				seen[key] = result
-			}
-		} else {
-			seen[key] = result
-		}
-	}
-	
-	unique := make([]*model.SearchResult, 0, len(seen))
-	for _, result := range seen {
-		unique = append(unique, result)
-	}
-	
-	return unique
-}
-
-// sortResultsByScore 按评分排序搜索结果
-func (sm *DefaultSourceManager) sortResultsByScore(results []*model.SearchResult) {
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-}
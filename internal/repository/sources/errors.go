@@ -0,0 +1,6 @@
+package sources
+
+import "errors"
+
+// ErrNoMusicURL 音源正常响应但没有该曲目的播放链接，区别于请求失败
+var ErrNoMusicURL = errors.New("未获取到有效的音乐链接")
@@ -146,7 +146,7 @@ func (g *GDStudioSource) searchMusicWithLimit(ctx context.Context, keyword strin
 	searchURL := g.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease") // 使用稳定的音乐源
+	params.Set("source", model.PlatformFromContext(ctx)) // 请求指定的音乐平台
 	params.Set("name", keyword)
 	if limit > 0 {
 		params.Set("count", strconv.Itoa(limit))
@@ -157,6 +157,7 @@ func (g *GDStudioSource) searchMusicWithLimit(ctx context.Context, keyword strin
 
 	g.logger.Info("GDStudio搜索音乐",
 		logger.String("keyword", keyword),
+		logger.String("platform", model.PlatformFromContext(ctx)),
 		logger.String("url", fullURL),
 	)
 
@@ -209,6 +210,7 @@ func (g *GDStudioSource) searchMusicWithLimit(ctx context.Context, keyword strin
 			Album:    encoding.FixChineseEncoding(item.Album),
 			Duration: 0, // GDStudio API没有提供时长信息
 			Source:   g.name,
+			Platform: model.PlatformFromContext(ctx),
 		}
 		results = append(results, result)
 	}
@@ -241,7 +243,7 @@ func (g *GDStudioSource) GetMusic(ctx context.Context, id string, quality string
 	matchURL := g.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "url")
-	params.Set("source", "netease") // 使用稳定的音乐源
+	params.Set("source", model.PlatformFromContext(ctx)) // 请求指定的音乐平台
 	params.Set("id", id)
 	if quality != "" {
 		params.Set("br", quality)
@@ -253,6 +255,7 @@ func (g *GDStudioSource) GetMusic(ctx context.Context, id string, quality string
 
 	g.logger.Info("GDStudio获取音乐",
 		logger.String("id", id),
+		logger.String("platform", model.PlatformFromContext(ctx)),
 		logger.String("quality", quality),
 		logger.String("url", fullURL),
 	)
@@ -288,7 +291,7 @@ func (g *GDStudioSource) GetMusic(ctx context.Context, id string, quality string
 
 	// 检查是否获取到有效URL
 	if urlResp.URL == "" {
-		return nil, fmt.Errorf("未获取到有效的音乐链接")
+		return nil, ErrNoMusicURL
 	}
 
 	// 构建结果
@@ -323,7 +326,7 @@ func (g *GDStudioSource) GetPicture(ctx context.Context, picID string, size stri
 	picURL := g.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "pic")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("id", picID)
 	if size != "" {
 		params.Set("size", size)
@@ -376,7 +379,7 @@ func (g *GDStudioSource) GetLyric(ctx context.Context, lyricID string) (string,
 	lyricURL := g.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "lyric")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("id", lyricID)
 
 	fullURL := lyricURL + "?" + params.Encode()
@@ -416,7 +419,7 @@ func (g *GDStudioSource) getMusicInfoBySearch(ctx context.Context, id string) (*
 	searchURL := g.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("name", id) // 使用ID作为关键词搜索
 	params.Set("count", "20") // 搜索更多结果
 	params.Set("pages", "1")
@@ -478,6 +481,7 @@ func (g *GDStudioSource) getMusicInfoBySearch(ctx context.Context, id string) (*
 				Album:    encoding.FixChineseEncoding(item.Album),
 				Duration: 0, // GDStudio API没有提供时长信息
 				PicURL:   picURL,
+				Platform: model.PlatformFromContext(ctx),
 			}, nil
 		}
 	}
@@ -508,6 +512,7 @@ func (g *GDStudioSource) GetMusicInfo(ctx context.Context, id string) (*model.Mu
 		Artist:   "未知艺术家",
 		Album:    "未知专辑",
 		Duration: 0,
+		Platform: model.PlatformFromContext(ctx),
 	}, nil
 }
 
@@ -524,6 +529,11 @@ func (g *GDStudioSource) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// GetSupportedQualities 获取支持的音质列表
+func (g *GDStudioSource) GetSupportedQualities() []string {
+	return model.GetValidQualities()
+}
+
 // GetConfig 获取音源配置
 func (g *GDStudioSource) GetConfig() *model.SourceConfig {
 	return &model.SourceConfig{
@@ -0,0 +1,667 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/encoding"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/jsonpath"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// 声明式HTTP音源支持的操作
+const (
+	HTTPOperationSearch = "search" // 搜索
+	HTTPOperationURL    = "url"    // 获取播放链接
+	HTTPOperationInfo   = "info"   // 获取音乐信息
+	HTTPOperationLyric  = "lyric"  // 获取歌词
+	HTTPOperationPic    = "pic"    // 获取专辑图
+	HTTPOperationHealth = "health" // 健康检查
+)
+
+// HTTPSourceOperations 声明式HTTP音源支持的操作列表
+var HTTPSourceOperations = []string{
+	HTTPOperationSearch,
+	HTTPOperationURL,
+	HTTPOperationInfo,
+	HTTPOperationLyric,
+	HTTPOperationPic,
+	HTTPOperationHealth,
+}
+
+// HTTPSource 声明式HTTP音源实现
+// 请求由配置中的模板生成，响应通过JSON路径映射为模型字段
+type HTTPSource struct {
+	client     *http.Client
+	config     *config.HTTPSourceConfig
+	logger     logger.Logger
+	name       string
+	enabled    bool
+	decoder    *encoding.HTTPResponseDecoder
+	headers    map[string]*template.Template
+	operations map[string]*httpOperation
+}
+
+// httpOperation 编译后的操作配置
+type httpOperation struct {
+	name    string
+	method  string
+	url     *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+	config  config.HTTPSourceOperationConfig
+}
+
+// httpTemplateData 模板变量
+type httpTemplateData struct {
+	BaseURL   string
+	APIKey    string
+	UserAgent string
+	Platform  string
+	Keyword   string
+	ID        string
+	Quality   string
+	Size      string
+	Limit     int
+}
+
+// NewHTTPSource 创建声明式HTTP音源实例
+func NewHTTPSource(cfg *config.HTTPSourceConfig, timeout time.Duration, logger logger.Logger) (*HTTPSource, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("HTTP音源配置不能为空")
+	}
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("HTTP音源名称不能为空")
+	}
+
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	headers, err := compileTemplates(cfg.Name+".headers", cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := make(map[string]*httpOperation, len(cfg.Operations))
+	for name, opConfig := range cfg.Operations {
+		op, err := compileOperation(cfg.Name, name, opConfig)
+		if err != nil {
+			return nil, err
+		}
+		operations[name] = op
+	}
+
+	if _, ok := operations[HTTPOperationURL]; !ok {
+		return nil, fmt.Errorf("HTTP音源 %s 缺少url操作配置", cfg.Name)
+	}
+
+	return &HTTPSource{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		config:     cfg,
+		logger:     logger,
+		name:       cfg.Name,
+		enabled:    cfg.Enabled,
+		decoder:    encoding.NewHTTPResponseDecoder(),
+		headers:    headers,
+		operations: operations,
+	}, nil
+}
+
+// compileOperation 编译单个操作的模板
+func compileOperation(sourceName, name string, cfg config.HTTPSourceOperationConfig) (*httpOperation, error) {
+	prefix := sourceName + "." + name
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("HTTP音源 %s 的 %s 操作缺少url", sourceName, name)
+	}
+
+	method := strings.ToUpper(cfg.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	urlTemplate, err := template.New(prefix + ".url").Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 的url模板失败: %w", prefix, err)
+	}
+
+	var bodyTemplate *template.Template
+	if cfg.Body != "" {
+		bodyTemplate, err = template.New(prefix + ".body").Parse(cfg.Body)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 的body模板失败: %w", prefix, err)
+		}
+	}
+
+	headers, err := compileTemplates(prefix+".headers", cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpOperation{
+		name:    name,
+		method:  method,
+		url:     urlTemplate,
+		body:    bodyTemplate,
+		headers: headers,
+		config:  cfg,
+	}, nil
+}
+
+// compileTemplates 编译请求头模板
+func compileTemplates(prefix string, values map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(values))
+	for key, value := range values {
+		tmpl, err := template.New(prefix + "." + key).Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s.%s 模板失败: %w", prefix, key, err)
+		}
+		templates[key] = tmpl
+	}
+	return templates, nil
+}
+
+// GetName 获取音源名称
+func (h *HTTPSource) GetName() string {
+	return h.name
+}
+
+// IsEnabled 检查音源是否启用
+func (h *HTTPSource) IsEnabled() bool {
+	return h.enabled
+}
+
+// SetEnabled 设置音源启用状态
+func (h *HTTPSource) SetEnabled(enabled bool) {
+	h.enabled = enabled
+}
+
+// SupportsCapability 检查是否配置了能力对应的操作
+func (h *HTTPSource) SupportsCapability(capability string) bool {
+	switch capability {
+	case model.SourceCapabilityMusic:
+		return h.hasOperation(HTTPOperationURL)
+	case model.SourceCapabilitySearch:
+		return h.hasOperation(HTTPOperationSearch)
+	case model.SourceCapabilityInfo:
+		return h.hasOperation(HTTPOperationInfo) || h.hasOperation(HTTPOperationSearch)
+	case model.SourceCapabilityLyric:
+		return h.hasOperation(HTTPOperationLyric)
+	case model.SourceCapabilityPicture:
+		return h.hasOperation(HTTPOperationPic)
+	default:
+		return false
+	}
+}
+
+// hasOperation 检查是否配置了指定操作
+func (h *HTTPSource) hasOperation(name string) bool {
+	_, ok := h.operations[name]
+	return ok
+}
+
+// SearchMusic 搜索音乐
+func (h *HTTPSource) SearchMusic(ctx context.Context, keyword string) ([]*model.SearchResult, error) {
+	if !h.IsEnabled() {
+		return nil, fmt.Errorf("HTTP音源 %s 已禁用", h.name)
+	}
+
+	if keyword == "" {
+		return nil, fmt.Errorf("搜索关键词不能为空")
+	}
+
+	doc, op, err := h.call(ctx, HTTPOperationSearch, httpTemplateData{Keyword: keyword, Limit: 10})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := h.results(doc, op)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := model.PlatformFromContext(ctx)
+	results := make([]*model.SearchResult, 0, len(items))
+	for _, item := range items {
+		id := h.field(item, op, "id")
+		if id == "" {
+			continue
+		}
+		results = append(results, &model.SearchResult{
+			ID:       id,
+			Name:     h.field(item, op, "name"),
+			Artist:   h.field(item, op, "artist"),
+			Album:    h.field(item, op, "album"),
+			Duration: h.intField(item, op, "duration"),
+			Source:   h.name,
+			Platform: platform,
+		})
+	}
+
+	h.logger.Info("HTTP音源搜索完成",
+		logger.String("source", h.name),
+		logger.String("keyword", keyword),
+		logger.Int("results", len(results)),
+	)
+
+	return results, nil
+}
+
+// GetMusic 获取音乐播放链接
+func (h *HTTPSource) GetMusic(ctx context.Context, id string, quality string) (*model.MusicURL, error) {
+	if !h.IsEnabled() {
+		return nil, fmt.Errorf("HTTP音源 %s 已禁用", h.name)
+	}
+
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
+	}
+
+	if quality == "" {
+		quality = "320"
+	}
+
+	doc, op, err := h.call(ctx, HTTPOperationURL, httpTemplateData{ID: id, Quality: quality})
+	if err != nil {
+		return nil, err
+	}
+
+	musicURL := h.field(doc, op, "url")
+	if musicURL == "" {
+		return nil, ErrNoMusicURL
+	}
+
+	result := &model.MusicURL{
+		URL:     musicURL,
+		Quality: h.field(doc, op, "quality"),
+		Size:    h.intField(doc, op, "size"),
+		Format:  h.field(doc, op, "format"),
+		Source:  h.name,
+	}
+	if result.Quality == "" {
+		result.Quality = quality
+	}
+
+	h.logger.Info("HTTP音源获取音乐成功",
+		logger.String("source", h.name),
+		logger.String("id", id),
+		logger.String("quality", result.Quality),
+	)
+
+	return result, nil
+}
+
+// GetMusicInfo 获取音乐详细信息
+// 未配置info操作时，通过search操作以ID为关键词查找
+func (h *HTTPSource) GetMusicInfo(ctx context.Context, id string) (*model.MusicInfo, error) {
+	if !h.IsEnabled() {
+		return nil, fmt.Errorf("HTTP音源 %s 已禁用", h.name)
+	}
+
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
+	}
+
+	operation := HTTPOperationInfo
+	if !h.hasOperation(HTTPOperationInfo) {
+		operation = HTTPOperationSearch
+	}
+
+	doc, op, err := h.call(ctx, operation, httpTemplateData{ID: id, Keyword: id, Limit: 20})
+	if err != nil {
+		return nil, err
+	}
+
+	items := []interface{}{doc}
+	if op.config.Results != "" {
+		if items, err = h.results(doc, op); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range items {
+		itemID := h.field(item, op, "id")
+		if itemID != "" && itemID != id {
+			continue
+		}
+		if itemID == "" && operation == HTTPOperationSearch {
+			continue
+		}
+		return &model.MusicInfo{
+			ID:       id,
+			Name:     h.field(item, op, "name"),
+			Artist:   h.field(item, op, "artist"),
+			Album:    h.field(item, op, "album"),
+			Duration: h.intField(item, op, "duration"),
+			PicURL:   h.field(item, op, "pic_url"),
+			Platform: model.PlatformFromContext(ctx),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("未找到ID为 %s 的音乐信息", id)
+}
+
+// GetLyric 获取歌词
+func (h *HTTPSource) GetLyric(ctx context.Context, lyricID string) (string, string, error) {
+	if !h.IsEnabled() {
+		return "", "", fmt.Errorf("HTTP音源 %s 已禁用", h.name)
+	}
+
+	if lyricID == "" {
+		return "", "", fmt.Errorf("歌词ID不能为空")
+	}
+
+	doc, op, err := h.call(ctx, HTTPOperationLyric, httpTemplateData{ID: lyricID})
+	if err != nil {
+		return "", "", err
+	}
+
+	return h.field(doc, op, "lyric"), h.field(doc, op, "tlyric"), nil
+}
+
+// GetPicture 获取专辑图
+func (h *HTTPSource) GetPicture(ctx context.Context, picID string, size string) (string, error) {
+	if !h.IsEnabled() {
+		return "", fmt.Errorf("HTTP音源 %s 已禁用", h.name)
+	}
+
+	if picID == "" {
+		return "", fmt.Errorf("专辑图ID不能为空")
+	}
+
+	if size == "" {
+		size = "300"
+	}
+
+	doc, op, err := h.call(ctx, HTTPOperationPic, httpTemplateData{ID: picID, Size: size})
+	if err != nil {
+		return "", err
+	}
+
+	return h.field(doc, op, "url"), nil
+}
+
+// HealthCheck 健康检查
+// 配置了health操作时按其成功判定，否则请求BaseURL并要求状态码小于500
+func (h *HTTPSource) HealthCheck(ctx context.Context) error {
+	if !h.IsEnabled() {
+		return fmt.Errorf("音源已禁用")
+	}
+
+	if h.hasOperation(HTTPOperationHealth) {
+		if _, _, err := h.call(ctx, HTTPOperationHealth, httpTemplateData{}); err != nil {
+			return fmt.Errorf("HTTP音源 %s 不可用: %w", h.name, err)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.config.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	if h.config.UserAgent != "" {
+		req.Header.Set("User-Agent", h.config.UserAgent)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP音源 %s 不可用: %w", h.name, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("HTTP音源 %s 不可用，状态码: %d", h.name, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// GetSupportedQualities 获取支持的音质列表，未配置时使用全部音质
+func (h *HTTPSource) GetSupportedQualities() []string {
+	if len(h.config.Qualities) > 0 {
+		return h.config.Qualities
+	}
+	return model.GetValidQualities()
+}
+
+// GetConfig 获取音源配置
+func (h *HTTPSource) GetConfig() *model.SourceConfig {
+	return &model.SourceConfig{
+		Name:      h.name,
+		Enabled:   h.enabled,
+		Priority:  h.config.Priority,
+		Timeout:   h.client.Timeout,
+		APIKey:    h.config.APIKey,
+		UserAgent: h.config.UserAgent,
+	}
+}
+
+// UpdateConfig 更新音源配置，请求模板在创建时确定，这里只更新启用状态
+func (h *HTTPSource) UpdateConfig(config *model.SourceConfig) error {
+	if config == nil {
+		return fmt.Errorf("配置不能为空")
+	}
+
+	h.enabled = config.Enabled
+
+	h.logger.Info("HTTP音源配置已更新",
+		logger.String("name", h.name),
+		logger.Bool("enabled", config.Enabled),
+	)
+
+	return nil
+}
+
+// call 执行操作请求并返回解析后的JSON文档
+func (h *HTTPSource) call(ctx context.Context, operation string, data httpTemplateData) (interface{}, *httpOperation, error) {
+	op, ok := h.operations[operation]
+	if !ok {
+		return nil, nil, fmt.Errorf("HTTP音源 %s 未配置 %s 操作", h.name, operation)
+	}
+
+	data.BaseURL = strings.TrimRight(h.config.BaseURL, "/")
+	data.APIKey = h.config.APIKey
+	data.UserAgent = h.config.UserAgent
+	data.Platform = model.PlatformFromContext(ctx)
+
+	requestURL, err := render(op.url, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body io.Reader
+	if op.body != nil {
+		rendered, err := render(op.body, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = strings.NewReader(rendered)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, op.method, requestURL, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	if h.config.UserAgent != "" {
+		req.Header.Set("User-Agent", h.config.UserAgent)
+	}
+	if err := setHeaders(req, h.headers, data); err != nil {
+		return nil, nil, err
+	}
+	if err := setHeaders(req, op.headers, data); err != nil {
+		return nil, nil, err
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	h.logger.Debug("HTTP音源发起请求",
+		logger.String("source", h.name),
+		logger.String("operation", operation),
+		logger.String("method", op.method),
+		logger.String("url", requestURL),
+	)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !statusAllowed(resp.StatusCode, op.config.Success.Status) {
+		return nil, nil, fmt.Errorf("请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	raw, err := h.decoder.DecodeResponse(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解码响应失败: %w", err)
+	}
+
+	var doc interface{}
+	if len(bytes.TrimSpace(raw)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+
+	if err := checkSuccess(doc, op.config.Success); err != nil {
+		return nil, nil, err
+	}
+
+	return doc, op, nil
+}
+
+// results 按results路径取出结果列表
+func (h *HTTPSource) results(doc interface{}, op *httpOperation) ([]interface{}, error) {
+	value, ok, err := jsonpath.Lookup(doc, op.config.Results)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP音源 %s 的results配置无效: %w", h.name, err)
+	}
+	if !ok || value == nil {
+		return []interface{}{}, nil
+	}
+	if items, ok := value.([]interface{}); ok {
+		return items, nil
+	}
+	return []interface{}{value}, nil
+}
+
+// field 按字段映射读取字符串值
+func (h *HTTPSource) field(doc interface{}, op *httpOperation, name string) string {
+	path, ok := op.config.Fields[name]
+	if !ok || path == "" {
+		return ""
+	}
+
+	value, found, err := jsonpath.String(doc, path)
+	if err != nil {
+		h.logger.Warn("HTTP音源字段映射无效",
+			logger.String("source", h.name),
+			logger.String("field", name),
+			logger.ErrorField("error", err),
+		)
+		return ""
+	}
+	if !found {
+		return ""
+	}
+
+	if h.config.FixEncoding {
+		value = encoding.FixChineseEncoding(value)
+	}
+	return value
+}
+
+// intField 按字段映射读取整数值
+func (h *HTTPSource) intField(doc interface{}, op *httpOperation, name string) int64 {
+	path, ok := op.config.Fields[name]
+	if !ok || path == "" {
+		return 0
+	}
+
+	value, found, err := jsonpath.Lookup(doc, path)
+	if err != nil || !found {
+		return 0
+	}
+	return jsonpath.ToInt64(value)
+}
+
+// render 渲染模板
+func render(tmpl *template.Template, data httpTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染模板 %s 失败: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// setHeaders 渲染并设置请求头，渲染结果为空的请求头会被跳过
+func setHeaders(req *http.Request, headers map[string]*template.Template, data httpTemplateData) error {
+	for key, tmpl := range headers {
+		value, err := render(tmpl, data)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	return nil
+}
+
+// statusAllowed 检查状态码是否在允许列表中，列表为空时仅允许200
+func statusAllowed(status int, allowed []int) bool {
+	if len(allowed) == 0 {
+		return status == http.StatusOK
+	}
+	for _, code := range allowed {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSuccess 按成功判定配置校验响应
+func checkSuccess(doc interface{}, success config.HTTPSourceSuccessConfig) error {
+	if success.Path == "" {
+		return nil
+	}
+
+	value, found, err := jsonpath.String(doc, success.Path)
+	if err != nil {
+		return fmt.Errorf("成功判定路径无效: %w", err)
+	}
+
+	if success.Equals == "" {
+		if !found || value == "" {
+			return fmt.Errorf("响应未通过成功判定: %s 为空", success.Path)
+		}
+		return nil
+	}
+
+	if value != success.Equals {
+		return fmt.Errorf("响应未通过成功判定: %s 为 %s，期望 %s", success.Path, strconv.Quote(value), strconv.Quote(success.Equals))
+	}
+	return nil
+}
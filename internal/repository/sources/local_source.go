@@ -0,0 +1,619 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/audiotag"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// LocalSourceName 本地曲库音源名称
+const LocalSourceName = "local"
+
+// localSearchLimit 本地曲库单次搜索返回的最大结果数
+const localSearchLimit = 50
+
+// localAudioMIMETypes 音频格式对应的MIME类型
+var localAudioMIMETypes = map[string]string{
+	audiotag.FormatMP3:  "audio/mpeg",
+	audiotag.FormatFLAC: "audio/flac",
+	audiotag.FormatOGG:  "audio/ogg",
+	audiotag.FormatOpus: "audio/ogg",
+	audiotag.FormatM4A:  "audio/mp4",
+}
+
+// LocalSource 本地曲库音源实现
+// 索引配置目录下的音频文件标签，播放链接与封面由代理自身的 /api/v1/local 接口提供
+type LocalSource struct {
+	config  *config.LocalSourceConfig
+	logger  logger.Logger
+	name    string
+	enabled bool
+
+	mu       sync.RWMutex
+	tracks   map[string]*localTrack
+	indexMu  sync.Mutex
+	watcher  *fsnotify.Watcher
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// localTrack 本地曲目索引项
+type localTrack struct {
+	id       string
+	path     string
+	relPath  string
+	size     int64
+	modTime  time.Time
+	tags     *audiotag.Tags
+	hasCover bool
+	search   string // 小写的标题、艺术家、专辑与文件名，用于搜索
+}
+
+// NewLocalSource 创建本地曲库音源实例并完成首次索引
+func NewLocalSource(cfg *config.LocalSourceConfig, log logger.Logger) (*LocalSource, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("本地曲库配置不能为空")
+	}
+
+	root, err := filepath.Abs(cfg.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("解析曲库目录失败: %w", err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("曲库目录不可用: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("曲库路径不是目录: %s", root)
+	}
+
+	localConfig := *cfg
+	localConfig.Directory = root
+	localConfig.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
+	source := &LocalSource{
+		config:  &localConfig,
+		logger:  log,
+		name:    LocalSourceName,
+		enabled: cfg.Enabled,
+		tracks:  make(map[string]*localTrack),
+		stopCh:  make(chan struct{}),
+	}
+
+	if err := source.Reindex(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Watch {
+		if err := source.watch(); err != nil {
+			source.logger.Warn("监听曲库目录失败，目录变化将不会自动重建索引",
+				logger.String("directory", root),
+				logger.ErrorField("error", err),
+			)
+		}
+	}
+
+	return source, nil
+}
+
+// GetName 获取音源名称
+func (l *LocalSource) GetName() string {
+	return l.name
+}
+
+// IsEnabled 检查音源是否启用
+func (l *LocalSource) IsEnabled() bool {
+	return l.enabled
+}
+
+// SetEnabled 设置音源启用状态
+func (l *LocalSource) SetEnabled(enabled bool) {
+	l.enabled = enabled
+}
+
+// TrackCount 获取已索引的曲目数量
+func (l *LocalSource) TrackCount() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.tracks)
+}
+
+// SearchMusic 按标题、艺术家、专辑与文件名搜索本地曲目，关键词按空白拆分后需全部命中
+func (l *LocalSource) SearchMusic(ctx context.Context, keyword string) ([]*model.SearchResult, error) {
+	if !l.IsEnabled() {
+		return nil, fmt.Errorf("本地曲库音源已禁用")
+	}
+
+	terms := strings.Fields(strings.ToLower(keyword))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("搜索关键词不能为空")
+	}
+
+	l.mu.RLock()
+	matched := make([]*localTrack, 0)
+	for _, track := range l.tracks {
+		if matchesAll(track.search, terms) {
+			matched = append(matched, track)
+		}
+	}
+	l.mu.RUnlock()
+
+	// 标题完全一致的曲目优先，其余按相对路径排序保证结果稳定
+	lowerKeyword := strings.ToLower(strings.TrimSpace(keyword))
+	sort.Slice(matched, func(i, j int) bool {
+		exactI := strings.ToLower(matched[i].tags.Title) == lowerKeyword
+		exactJ := strings.ToLower(matched[j].tags.Title) == lowerKeyword
+		if exactI != exactJ {
+			return exactI
+		}
+		return matched[i].relPath < matched[j].relPath
+	})
+	if len(matched) > localSearchLimit {
+		matched = matched[:localSearchLimit]
+	}
+
+	platform := model.PlatformFromContext(ctx)
+	results := make([]*model.SearchResult, 0, len(matched))
+	for _, track := range matched {
+		results = append(results, &model.SearchResult{
+			ID:       track.id,
+			Name:     track.tags.Title,
+			Artist:   track.tags.Artist,
+			Album:    track.tags.Album,
+			Duration: int64(track.tags.Duration.Seconds()),
+			Source:   l.name,
+			Platform: platform,
+		})
+	}
+
+	return results, nil
+}
+
+// GetMusic 获取本地曲目的播放链接，音质由文件本身决定
+func (l *LocalSource) GetMusic(ctx context.Context, id string, quality string) (*model.MusicURL, error) {
+	if !l.IsEnabled() {
+		return nil, fmt.Errorf("本地曲库音源已禁用")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
+	}
+
+	// 曲库中没有该曲目属于正常答复，其他平台的ID总是落在这里
+	track, err := l.track(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 本地曲库未找到ID为 %s 的曲目", ErrNoMusicURL, id)
+	}
+
+	return &model.MusicURL{
+		URL:     l.publicURL(track.id, "file"),
+		Quality: localQuality(track.tags),
+		Size:    track.size,
+		Format:  track.tags.Format,
+		Source:  l.name,
+		Info:    l.musicInfo(ctx, track),
+	}, nil
+}
+
+// GetMusicInfo 获取本地曲目信息
+func (l *LocalSource) GetMusicInfo(ctx context.Context, id string) (*model.MusicInfo, error) {
+	if !l.IsEnabled() {
+		return nil, fmt.Errorf("本地曲库音源已禁用")
+	}
+
+	track, err := l.track(id)
+	if err != nil {
+		return nil, err
+	}
+	return l.musicInfo(ctx, track), nil
+}
+
+// GetPicture 获取内嵌封面链接，picID即曲目ID，封面按原图提供
+func (l *LocalSource) GetPicture(ctx context.Context, picID string, size string) (string, error) {
+	if !l.IsEnabled() {
+		return "", fmt.Errorf("本地曲库音源已禁用")
+	}
+
+	track, err := l.track(picID)
+	if err != nil {
+		return "", err
+	}
+	if !track.hasCover {
+		return "", nil
+	}
+	return l.publicURL(track.id, "cover"), nil
+}
+
+// TrackFile 获取曲目文件
+func (l *LocalSource) TrackFile(id string) (*model.LocalTrackFile, error) {
+	track, err := l.track(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LocalTrackFile{
+		Path:     track.path,
+		Format:   track.tags.Format,
+		MIMEType: localAudioMIMETypes[track.tags.Format],
+	}, nil
+}
+
+// TrackCover 读取曲目内嵌封面
+func (l *LocalSource) TrackCover(id string) (*model.CoverImage, error) {
+	track, err := l.track(id)
+	if err != nil {
+		return nil, err
+	}
+	if !track.hasCover {
+		return nil, fmt.Errorf("曲目 %s 未找到内嵌封面", id)
+	}
+
+	tags, err := audiotag.ReadFile(track.path, audiotag.Options{WithPicture: true})
+	if err != nil {
+		return nil, fmt.Errorf("读取曲目封面失败: %w", err)
+	}
+	if tags.Picture == nil {
+		return nil, fmt.Errorf("曲目 %s 未找到内嵌封面", id)
+	}
+
+	return &model.CoverImage{
+		MIMEType: tags.Picture.MIMEType,
+		Data:     tags.Picture.Data,
+	}, nil
+}
+
+// HealthCheck 健康检查
+func (l *LocalSource) HealthCheck(ctx context.Context) error {
+	if !l.IsEnabled() {
+		return fmt.Errorf("音源已禁用")
+	}
+
+	if _, err := os.Stat(l.config.Directory); err != nil {
+		return fmt.Errorf("曲库目录不可用: %w", err)
+	}
+	return nil
+}
+
+// GetSupportedQualities 获取支持的音质列表
+func (l *LocalSource) GetSupportedQualities() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, track := range l.tracks {
+		seen[localQuality(track.tags)] = true
+	}
+
+	qualities := make([]string, 0, len(seen))
+	for _, quality := range model.GetValidQualities() {
+		if seen[quality] {
+			qualities = append(qualities, quality)
+		}
+	}
+	return qualities
+}
+
+// GetConfig 获取音源配置
+func (l *LocalSource) GetConfig() *model.SourceConfig {
+	return &model.SourceConfig{
+		Name:     l.name,
+		Enabled:  l.enabled,
+		Priority: l.config.Priority,
+	}
+}
+
+// UpdateConfig 更新音源配置
+func (l *LocalSource) UpdateConfig(config *model.SourceConfig) error {
+	if config == nil {
+		return fmt.Errorf("配置不能为空")
+	}
+
+	l.enabled = config.Enabled
+
+	l.logger.Info("本地曲库音源配置已更新",
+		logger.Bool("enabled", config.Enabled),
+	)
+
+	return nil
+}
+
+// Close 停止目录监听
+func (l *LocalSource) Close() error {
+	var err error
+	l.stopOnce.Do(func() {
+		close(l.stopCh)
+		if l.watcher != nil {
+			err = l.watcher.Close()
+		}
+	})
+	return err
+}
+
+// Reindex 重建曲库索引，未变化的文件沿用已有索引
+func (l *LocalSource) Reindex() error {
+	l.indexMu.Lock()
+	defer l.indexMu.Unlock()
+
+	start := time.Now()
+
+	l.mu.RLock()
+	previous := make(map[string]*localTrack, len(l.tracks))
+	for _, track := range l.tracks {
+		previous[track.path] = track
+	}
+	l.mu.RUnlock()
+
+	extensions := make(map[string]bool, len(l.config.Extensions))
+	for _, ext := range l.config.Extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions[ext] = true
+	}
+
+	tracks := make(map[string]*localTrack, len(previous))
+	parsed, failed := 0, 0
+	err := filepath.WalkDir(l.config.Directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			l.logger.Warn("访问曲库路径失败", logger.String("path", path), logger.ErrorField("error", err))
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if track, ok := previous[path]; ok && track.size == info.Size() && track.modTime.Equal(info.ModTime()) {
+			tracks[track.id] = track
+			return nil
+		}
+
+		track, err := l.indexFile(path, info)
+		if err != nil {
+			failed++
+			l.logger.Debug("读取音频标签失败",
+				logger.String("path", path),
+				logger.ErrorField("error", err),
+			)
+			return nil
+		}
+		parsed++
+		tracks[track.id] = track
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("索引曲库目录失败: %w", err)
+	}
+
+	l.mu.Lock()
+	l.tracks = tracks
+	l.mu.Unlock()
+
+	l.logger.Info("本地曲库索引完成",
+		logger.String("directory", l.config.Directory),
+		logger.Int("tracks", len(tracks)),
+		logger.Int("parsed", parsed),
+		logger.Int("failed", failed),
+		logger.String("duration", time.Since(start).String()),
+	)
+
+	return nil
+}
+
+// indexFile 读取单个音频文件的标签
+func (l *LocalSource) indexFile(path string, info fs.FileInfo) (*localTrack, error) {
+	tags, err := audiotag.ReadFile(path, audiotag.Options{WithPicture: true})
+	if err != nil {
+		return nil, err
+	}
+
+	relPath, err := filepath.Rel(l.config.Directory, path)
+	if err != nil {
+		relPath = path
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	// 标签缺失时从"艺术家 - 标题"形式的文件名推断
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if tags.Title == "" {
+		tags.Title = base
+		if parts := strings.SplitN(base, " - ", 2); len(parts) == 2 {
+			if tags.Artist == "" {
+				tags.Artist = strings.TrimSpace(parts[0])
+			}
+			tags.Title = strings.TrimSpace(parts[1])
+		}
+	}
+
+	hasCover := tags.Picture != nil
+	tags.Picture = nil // 封面按需读取，不常驻内存
+
+	sum := sha1.Sum([]byte(relPath))
+	return &localTrack{
+		id:       hex.EncodeToString(sum[:8]),
+		path:     path,
+		relPath:  relPath,
+		size:     info.Size(),
+		modTime:  info.ModTime(),
+		tags:     tags,
+		hasCover: hasCover,
+		search:   strings.ToLower(strings.Join([]string{tags.Title, tags.Artist, tags.Album, base}, " ")),
+	}, nil
+}
+
+// watch 监听曲库目录变化，变化停止一段时间后重建索引
+func (l *LocalSource) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	l.watcher = watcher
+
+	if err := l.addWatchDirs(l.config.Directory); err != nil {
+		watcher.Close()
+		l.watcher = nil
+		return err
+	}
+
+	delay := l.config.ReindexDelay
+	if delay <= 0 {
+		delay = 2 * time.Second
+	}
+
+	go func() {
+		var timer *time.Timer
+		var timerC <-chan time.Time
+
+		for {
+			select {
+			case <-l.stopCh:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := l.addWatchDirs(event.Name); err != nil {
+							l.logger.Warn("监听新建目录失败",
+								logger.String("path", event.Name),
+								logger.ErrorField("error", err),
+							)
+						}
+					}
+				}
+				if timer == nil {
+					timer = time.NewTimer(delay)
+				} else {
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(delay)
+				}
+				timerC = timer.C
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				l.logger.Warn("曲库目录监听错误", logger.ErrorField("error", err))
+			case <-timerC:
+				timerC = nil
+				if err := l.Reindex(); err != nil {
+					l.logger.Error("重建曲库索引失败", logger.ErrorField("error", err))
+				}
+			}
+		}
+	}()
+
+	l.logger.Info("开始监听曲库目录变化",
+		logger.String("directory", l.config.Directory),
+		logger.String("reindex_delay", delay.String()),
+	)
+	return nil
+}
+
+// addWatchDirs 递归添加目录监听，fsnotify不支持递归监听
+func (l *LocalSource) addWatchDirs(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return l.watcher.Add(path)
+	})
+}
+
+// track 根据ID获取曲目
+func (l *LocalSource) track(id string) (*localTrack, error) {
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	track, ok := l.tracks[id]
+	if !ok {
+		return nil, fmt.Errorf("本地曲库未找到ID为 %s 的曲目", id)
+	}
+	return track, nil
+}
+
+// musicInfo 构建曲目信息
+func (l *LocalSource) musicInfo(ctx context.Context, track *localTrack) *model.MusicInfo {
+	info := &model.MusicInfo{
+		ID:       track.id,
+		Name:     track.tags.Title,
+		Artist:   track.tags.Artist,
+		Album:    track.tags.Album,
+		Duration: int64(track.tags.Duration.Seconds()),
+		Platform: model.PlatformFromContext(ctx),
+	}
+	if track.hasCover {
+		info.PicURL = l.publicURL(track.id, "cover")
+	}
+	return info
+}
+
+// publicURL 生成代理对外提供的曲目资源地址
+func (l *LocalSource) publicURL(id, resource string) string {
+	return l.config.PublicURL + "/api/v1/local/" + id + "/" + resource
+}
+
+// localQuality 根据音频格式与比特率推断音质参数
+func localQuality(tags *audiotag.Tags) string {
+	if tags.Format == audiotag.FormatFLAC {
+		return "999"
+	}
+	switch {
+	case tags.Bitrate >= 256:
+		return "320"
+	case tags.Bitrate >= 160:
+		return "192"
+	default:
+		return "128"
+	}
+}
+
+// matchesAll 检查文本是否包含全部关键词
+func matchesAll(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+)
+
+func TestLocalSourceGetMusicUnknownID(t *testing.T) {
+	source, err := NewLocalSource(&config.LocalSourceConfig{Enabled: true, Directory: t.TempDir()}, testLogger(t))
+	if err != nil {
+		t.Fatalf("创建本地曲库音源失败: %v", err)
+	}
+	defer source.Close()
+	ctx := context.Background()
+
+	// 曲库中没有的ID是明确的未找到，不应计为音源故障
+	if _, err := source.GetMusic(ctx, "1974443814", "320"); !errors.Is(err, ErrNoMusicURL) {
+		t.Errorf("未知ID 错误 = %v，期望 ErrNoMusicURL", err)
+	}
+
+	if _, err := source.GetMusic(ctx, "", "320"); err == nil || errors.Is(err, ErrNoMusicURL) {
+		t.Errorf("空ID 错误 = %v，期望普通错误", err)
+	}
+
+	source.SetEnabled(false)
+	if _, err := source.GetMusic(ctx, "1974443814", "320"); err == nil || errors.Is(err, ErrNoMusicURL) {
+		t.Errorf("禁用时 错误 = %v，期望普通错误", err)
+	}
+}
@@ -130,7 +130,7 @@ func (u *UNMSource) searchMusicWithLimit(ctx context.Context, keyword string, li
 	searchURL := u.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease") // 使用稳定的音乐源
+	params.Set("source", model.PlatformFromContext(ctx)) // 请求指定的音乐平台
 	params.Set("name", keyword)
 	if limit > 0 {
 		params.Set("count", strconv.Itoa(limit))
@@ -143,6 +143,7 @@ func (u *UNMSource) searchMusicWithLimit(ctx context.Context, keyword string, li
 
 	u.logger.Info("UNM搜索音乐",
 		logger.String("keyword", keyword),
+		logger.String("platform", model.PlatformFromContext(ctx)),
 		logger.String("url", fullURL),
 	)
 
@@ -206,6 +207,7 @@ func (u *UNMSource) searchMusicWithLimit(ctx context.Context, keyword string, li
 			Album:    encoding.FixChineseEncoding(item.Album),
 			Duration: 0, // API没有提供时长信息
 			Source:   u.name,
+			Platform: model.PlatformFromContext(ctx),
 		}
 		results = append(results, result)
 	}
@@ -238,7 +240,7 @@ func (u *UNMSource) GetMusic(ctx context.Context, id string, quality string) (*m
 	matchURL := u.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "url")
-	params.Set("source", "netease") // 使用稳定的音乐源
+	params.Set("source", model.PlatformFromContext(ctx)) // 请求指定的音乐平台
 	params.Set("id", id)
 	if quality != "" {
 		params.Set("br", quality)
@@ -250,6 +252,7 @@ func (u *UNMSource) GetMusic(ctx context.Context, id string, quality string) (*m
 
 	u.logger.Info("UNM获取音乐",
 		logger.String("id", id),
+		logger.String("platform", model.PlatformFromContext(ctx)),
 		logger.String("quality", quality),
 		logger.String("url", fullURL),
 	)
@@ -282,6 +285,7 @@ func (u *UNMSource) GetMusic(ctx context.Context, id string, quality string) (*m
 		URL  string `json:"url"`  // 音乐链接
 		BR   int    `json:"br"`   // 实际返回音质
 		Size int64  `json:"size"` // 文件大小，单位为KB
+		MD5  string `json:"md5"`  // 文件MD5，部分部署提供
 		From string `json:"from"` // 来源信息
 	}
 
@@ -291,7 +295,7 @@ func (u *UNMSource) GetMusic(ctx context.Context, id string, quality string) (*m
 
 	// 检查是否获取到有效URL
 	if urlResp.URL == "" {
-		return nil, fmt.Errorf("未获取到有效的音乐链接")
+		return nil, ErrNoMusicURL
 	}
 
 	// 构建结果
@@ -300,6 +304,7 @@ func (u *UNMSource) GetMusic(ctx context.Context, id string, quality string) (*m
 		ProxyURL: "", // GDStudio API没有提供ProxyURL
 		Quality:  strconv.Itoa(urlResp.BR),
 		Size:     urlResp.Size,
+		MD5:      strings.ToLower(urlResp.MD5),
 		Source:   u.name,
 		Info:     musicInfo, // 添加音乐信息
 	}
@@ -318,7 +323,7 @@ func (u *UNMSource) getMusicInfoBySearch(ctx context.Context, id string) (*model
 	searchURL := u.config.BaseURL
 	params := url.Values{}
 	params.Set("types", "search")
-	params.Set("source", "netease")
+	params.Set("source", model.PlatformFromContext(ctx))
 	params.Set("name", id) // 使用ID作为关键词搜索
 	params.Set("count", "20") // 搜索更多结果
 	params.Set("pages", "1")
@@ -387,6 +392,7 @@ func (u *UNMSource) tryGetMusicInfo(ctx context.Context, fullURL, targetID strin
 					Album:    encoding.FixChineseEncoding(item.Album),
 					Duration: 0, // API没有提供时长信息
 					PicURL:   "", // 可以后续添加封面URL处理
+					Platform: model.PlatformFromContext(ctx),
 				}, nil
 			}
 		}
@@ -417,6 +423,7 @@ func (u *UNMSource) GetMusicInfo(ctx context.Context, id string) (*model.MusicIn
 		Artist:   "未知艺术家",
 		Album:    "未知专辑",
 		Duration: 0,
+		Platform: model.PlatformFromContext(ctx),
 	}, nil
 }
 
@@ -430,6 +437,11 @@ func (u *UNMSource) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// GetSupportedQualities 获取支持的音质列表
+func (u *UNMSource) GetSupportedQualities() []string {
+	return model.GetValidQualities()
+}
+
 // GetConfig 获取音源配置
 func (u *UNMSource) GetConfig() *model.SourceConfig {
 	return &model.SourceConfig{
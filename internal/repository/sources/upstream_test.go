@@ -0,0 +1,326 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/fakeupstream"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// 内置曲库中的曲目
+const (
+	trackSunny   = "186016"     // 晴天，最高999
+	trackQilixi  = "185811"     // 七里香，最高320
+	trackGuyong  = "1974443814" // 孤勇者，最高128
+	trackMissing = "1"          // 曲库中不存在
+)
+
+// upstreamSource 使用GDStudio接口格式的音源
+type upstreamSource interface {
+	GetName() string
+	SearchMusic(ctx context.Context, keyword string) ([]*model.SearchResult, error)
+	GetMusic(ctx context.Context, id string, quality string) (*model.MusicURL, error)
+}
+
+// testLogger 创建只输出致命错误的日志器
+func testLogger(t *testing.T) logger.Logger {
+	t.Helper()
+	cfg := logger.DefaultConfig()
+	cfg.Level = logger.FatalLevel
+	log, err := logger.NewLogger(cfg)
+	if err != nil {
+		t.Fatalf("创建日志器失败: %v", err)
+	}
+	return log
+}
+
+// newUpstreamSources 创建连接到替身服务器的GDStudio与UNM音源
+func newUpstreamSources(t *testing.T, ts *fakeupstream.TestServer, timeout time.Duration) []upstreamSource {
+	t.Helper()
+	log := testLogger(t)
+	return []upstreamSource{
+		NewGDStudioSource(&config.GDStudioConfig{Enabled: true, BaseURL: ts.GDStudioBaseURL(), UserAgent: "test"}, timeout, log),
+		NewUNMSource(&config.UNMServerConfig{Enabled: true, BaseURL: ts.UNMBaseURL(), UserAgent: "test"}, timeout, log),
+	}
+}
+
+// startUpstream 启动替身服务器，测试结束时关闭
+func startUpstream(t *testing.T) *fakeupstream.TestServer {
+	t.Helper()
+	ts := fakeupstream.NewTestServer(nil, fakeupstream.WithSeed(1))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// setFaults 设置故障注入
+func setFaults(t *testing.T, ts *fakeupstream.TestServer, types string, faults fakeupstream.Faults) {
+	t.Helper()
+	if err := ts.Upstream.SetFaults(types, faults); err != nil {
+		t.Fatalf("设置故障注入失败: %v", err)
+	}
+}
+
+func TestUpstreamSourcesSearch(t *testing.T) {
+	ts := startUpstream(t)
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			results, err := source.SearchMusic(context.Background(), "周杰伦 晴天")
+			if err != nil {
+				t.Fatalf("搜索失败: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("搜索结果数 = %d，期望 1", len(results))
+			}
+			got := results[0]
+			if got.ID != trackSunny || got.Name != "晴天" || got.Artist != "周杰伦" || got.Album != "叶惠美" {
+				t.Errorf("搜索结果 = %+v", got)
+			}
+			if got.Source != source.GetName() || got.Platform != model.PlatformNetease {
+				t.Errorf("来源 = %s/%s，期望 %s/%s", got.Source, got.Platform, source.GetName(), model.PlatformNetease)
+			}
+
+			results, err = source.SearchMusic(context.Background(), "不存在的歌曲")
+			if err != nil {
+				t.Fatalf("无结果的搜索不应失败: %v", err)
+			}
+			if len(results) != 0 {
+				t.Errorf("搜索结果数 = %d，期望 0", len(results))
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesGetMusic(t *testing.T) {
+	ts := startUpstream(t)
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			musicURL, err := source.GetMusic(context.Background(), trackSunny, "320")
+			if err != nil {
+				t.Fatalf("获取音乐失败: %v", err)
+			}
+			if !strings.HasPrefix(musicURL.URL, ts.URL+"/_assets/audio/") {
+				t.Errorf("播放链接 = %s", musicURL.URL)
+			}
+			if musicURL.Quality != "320" || musicURL.Size <= 0 || musicURL.Source != source.GetName() {
+				t.Errorf("音乐链接 = %+v", musicURL)
+			}
+			if musicURL.Info == nil || musicURL.Info.Name != "晴天" {
+				t.Errorf("音乐信息 = %+v，期望通过搜索补全", musicURL.Info)
+			}
+
+			resp, err := http.Get(musicURL.URL)
+			if err != nil {
+				t.Fatalf("下载音频失败: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "audio/mpeg" {
+				t.Errorf("音频响应 = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesQuality(t *testing.T) {
+	ts := startUpstream(t)
+
+	tests := []struct {
+		id        string
+		requested string
+		delivered string
+	}{
+		{trackSunny, "999", "999"},
+		{trackSunny, "128", "128"},
+		{trackQilixi, "999", "320"},
+		{trackGuyong, "320", "128"},
+	}
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			for _, tt := range tests {
+				musicURL, err := source.GetMusic(context.Background(), tt.id, tt.requested)
+				if err != nil {
+					t.Fatalf("获取音乐 %s@%s 失败: %v", tt.id, tt.requested, err)
+				}
+				// 音源如实报告上游返回的音质，由音质协商决定是否接受
+				if musicURL.Quality != tt.delivered {
+					t.Errorf("%s@%s 音质 = %s，期望 %s", tt.id, tt.requested, musicURL.Quality, tt.delivered)
+				}
+				exact := tt.requested == tt.delivered
+				if got := model.AcceptsQuality(musicURL.Quality, tt.requested, model.QualityPolicyExact); got != exact {
+					t.Errorf("%s@%s exact策略接受 = %v，期望 %v", tt.id, tt.requested, got, exact)
+				}
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesNotFound(t *testing.T) {
+	ts := startUpstream(t)
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			_, err := source.GetMusic(context.Background(), trackMissing, "320")
+			if !errors.Is(err, ErrNoMusicURL) {
+				t.Fatalf("错误 = %v，期望 ErrNoMusicURL", err)
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesLatency(t *testing.T) {
+	ts := startUpstream(t)
+	setFaults(t, ts, fakeupstream.TypeURL, fakeupstream.Faults{Latency: 300 * time.Millisecond})
+
+	for _, source := range newUpstreamSources(t, ts, 100*time.Millisecond) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			start := time.Now()
+			_, err := source.GetMusic(context.Background(), trackSunny, "320")
+			if err == nil {
+				t.Fatal("超过客户端超时的请求应失败")
+			}
+			if errors.Is(err, ErrNoMusicURL) {
+				t.Errorf("超时不应视为没有播放链接: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+				t.Errorf("请求耗时 %v，未按超时返回", elapsed)
+			}
+		})
+	}
+
+	// 延迟在超时之内时请求成功
+	for _, source := range newUpstreamSources(t, ts, 2*time.Second) {
+		t.Run(source.GetName()+"_within_timeout", func(t *testing.T) {
+			if _, err := source.GetMusic(context.Background(), trackSunny, "320"); err != nil {
+				t.Fatalf("获取音乐失败: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesContextDeadline(t *testing.T) {
+	ts := startUpstream(t)
+	setFaults(t, ts, "", fakeupstream.Faults{Latency: 300 * time.Millisecond})
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			_, err := source.SearchMusic(ctx, "晴天")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("错误 = %v，期望 context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesHTTPError(t *testing.T) {
+	ts := startUpstream(t)
+	setFaults(t, ts, "", fakeupstream.Faults{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			_, err := source.GetMusic(context.Background(), trackSunny, "320")
+			if err == nil || !strings.Contains(err.Error(), "503") {
+				t.Fatalf("错误 = %v，期望包含状态码503", err)
+			}
+			if errors.Is(err, ErrNoMusicURL) {
+				t.Errorf("HTTP错误不应视为没有播放链接: %v", err)
+			}
+
+			if _, err := source.SearchMusic(context.Background(), "晴天"); err == nil {
+				t.Error("HTTP错误时搜索应失败")
+			}
+		})
+	}
+}
+
+func TestUpstreamSourcesGBK(t *testing.T) {
+	for _, omitCharset := range []bool{false, true} {
+		ts := startUpstream(t)
+		setFaults(t, ts, "", fakeupstream.Faults{GBK: true, OmitCharset: omitCharset})
+
+		for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+			name := source.GetName()
+			if omitCharset {
+				name += "_omit_charset"
+			}
+			t.Run(name, func(t *testing.T) {
+				results, err := source.SearchMusic(context.Background(), "孤勇者")
+				if err != nil {
+					t.Fatalf("搜索失败: %v", err)
+				}
+				if len(results) != 1 {
+					t.Fatalf("搜索结果数 = %d，期望 1", len(results))
+				}
+				if got := results[0]; got.Name != "孤勇者" || got.Artist != "陈奕迅" {
+					t.Errorf("GBK响应解码结果 = %s / %s", got.Name, got.Artist)
+				}
+
+				musicURL, err := source.GetMusic(context.Background(), trackGuyong, "128")
+				if err != nil {
+					t.Fatalf("获取音乐失败: %v", err)
+				}
+				if musicURL.Quality != "128" || musicURL.URL == "" {
+					t.Errorf("音乐链接 = %+v", musicURL)
+				}
+			})
+		}
+	}
+}
+
+func TestUpstreamSourcesMalformedJSON(t *testing.T) {
+	ts := startUpstream(t)
+	setFaults(t, ts, "", fakeupstream.Faults{MalformedRate: 1})
+
+	for _, source := range newUpstreamSources(t, ts, 5*time.Second) {
+		t.Run(source.GetName(), func(t *testing.T) {
+			_, err := source.GetMusic(context.Background(), trackSunny, "320")
+			if err == nil || !strings.Contains(err.Error(), "解析响应失败") {
+				t.Fatalf("错误 = %v，期望解析失败", err)
+			}
+			if errors.Is(err, ErrNoMusicURL) {
+				t.Errorf("损坏的响应不应视为没有播放链接: %v", err)
+			}
+
+			if _, err := source.SearchMusic(context.Background(), "晴天"); err == nil {
+				t.Error("损坏的响应时搜索应失败")
+			}
+		})
+	}
+}
+
+func TestGDStudioSourceLyricAndPicture(t *testing.T) {
+	ts := startUpstream(t)
+	source := newUpstreamSources(t, ts, 5*time.Second)[0].(*GDStudioSource)
+
+	lyric, tlyric, err := source.GetLyric(context.Background(), "28815250")
+	if err != nil {
+		t.Fatalf("获取歌词失败: %v", err)
+	}
+	if !strings.Contains(lyric, "The club isn't the best place") || !strings.Contains(tlyric, "俱乐部") {
+		t.Errorf("歌词 = %q / %q", lyric, tlyric)
+	}
+
+	lyric, _, err = source.GetLyric(context.Background(), trackMissing)
+	if err != nil || lyric != "" {
+		t.Errorf("不存在的歌词 = %q, %v，期望空歌词", lyric, err)
+	}
+
+	picURL, err := source.GetPicture(context.Background(), "109951163074860592", "300")
+	if err != nil {
+		t.Fatalf("获取专辑图失败: %v", err)
+	}
+	if !strings.HasPrefix(picURL, ts.URL+"/_assets/pic/") {
+		t.Errorf("专辑图链接 = %s", picURL)
+	}
+}
@@ -0,0 +1,232 @@
+// Package repository 两级缓存仓库实现
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// hotItem 内存层中的条目
+type hotItem struct {
+	key       string
+	value     string
+	expiresAt int64 // 过期时间（Unix纳秒），0表示永不过期
+}
+
+// tieredCacheRepository 两级缓存仓库实现：磁盘保存全部数据，内存按LRU保留最近访问的条目
+// 写入同时落盘，读取先查内存，磁盘命中后提升到内存；键、过期时间与统计以磁盘为准。
+type tieredCacheRepository struct {
+	disk     *diskCacheRepository
+	maxItems int
+	logger   logger.Logger
+
+	// writeMu 串行化写操作，保证磁盘与内存按相同顺序更新
+	writeMu sync.Mutex
+
+	mu  sync.Mutex
+	hot map[string]*list.Element
+	lru *list.List
+	// generation 每次写入、删除后递增，读取磁盘期间发生过修改时不提升读到的旧值
+	generation uint64
+
+	// 统计信息
+	stats struct {
+		hotHits atomic.Int64
+	}
+}
+
+// NewTieredCacheRepository 创建两级缓存仓库
+func NewTieredCacheRepository(cfg *config.DiskCacheConfig, log logger.Logger) (CacheRepository, error) {
+	disk, err := newDiskCacheRepository(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	return &tieredCacheRepository{
+		disk:     disk,
+		maxItems: cfg.HotMaxItems,
+		logger:   log,
+		hot:      make(map[string]*list.Element),
+		lru:      list.New(),
+	}, nil
+}
+
+// Get 获取缓存，值以字符串返回
+func (r *tieredCacheRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	r.mu.Lock()
+	if elem, ok := r.hot[key]; ok {
+		item := elem.Value.(*hotItem)
+		if item.expiresAt == 0 || time.Now().UnixNano() < item.expiresAt {
+			r.lru.MoveToFront(elem)
+			r.mu.Unlock()
+			r.stats.hotHits.Add(1)
+			return item.value, nil
+		}
+		r.removeHotLocked(elem)
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	value, expiresAt, err := r.disk.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.putHotLocked(key, value, expiresAt)
+	}
+	r.mu.Unlock()
+	return value, nil
+}
+
+// Set 设置缓存，写入磁盘成功后更新内存
+func (r *tieredCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	if err := r.disk.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	data, err := cacheValueBytes(value)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	r.mu.Lock()
+	r.generation++
+	r.putHotLocked(key, string(data), expiresAt)
+	r.mu.Unlock()
+	return nil
+}
+
+// Delete 删除缓存
+func (r *tieredCacheRepository) Delete(ctx context.Context, key string) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	err := r.disk.Delete(ctx, key)
+	r.invalidate(key)
+	return err
+}
+
+// Exists 检查缓存是否存在
+func (r *tieredCacheRepository) Exists(ctx context.Context, key string) (bool, error) {
+	return r.disk.Exists(ctx, key)
+}
+
+// Clear 清空所有缓存
+func (r *tieredCacheRepository) Clear(ctx context.Context) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	err := r.disk.Clear(ctx)
+	r.mu.Lock()
+	r.generation++
+	r.hot = make(map[string]*list.Element)
+	r.lru.Init()
+	r.mu.Unlock()
+	return err
+}
+
+// GetKeys 获取匹配模式的键
+func (r *tieredCacheRepository) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	return r.disk.GetKeys(ctx, pattern)
+}
+
+// GetTTL 获取剩余过期时间，永不过期的键返回0
+func (r *tieredCacheRepository) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.disk.GetTTL(ctx, key)
+}
+
+// Expire 设置过期时间，内存中的条目失效后下次读取时从磁盘重新载入
+func (r *tieredCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	err := r.disk.Expire(ctx, key, ttl)
+	r.invalidate(key)
+	return err
+}
+
+// GetStats 获取缓存统计，命中数包含内存层与磁盘层
+func (r *tieredCacheRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	diskStats, err := r.disk.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	hotItems := r.lru.Len()
+	r.mu.Unlock()
+
+	hotHits := r.stats.hotHits.Load()
+	diskHits, misses := r.disk.stats.hits.Load(), r.disk.stats.misses.Load()
+	hits := hotHits + diskHits
+	hitRate := float64(0)
+	totalRequests := hits + misses
+	if totalRequests > 0 {
+		hitRate = float64(hits) / float64(totalRequests) * 100
+	}
+
+	return map[string]interface{}{
+		"type":           "tiered",
+		"hits":           hits,
+		"misses":         misses,
+		"hot_hits":       hotHits,
+		"disk_hits":      diskHits,
+		"hot_items":      hotItems,
+		"hot_max_items":  r.maxItems,
+		"total_items":    diskStats["total_items"],
+		"hit_rate":       hitRate,
+		"total_requests": totalRequests,
+		"disk":           diskStats,
+	}, nil
+}
+
+// Close 关闭磁盘层
+func (r *tieredCacheRepository) Close() error {
+	return r.disk.Close()
+}
+
+// invalidate 移除内存中的条目
+func (r *tieredCacheRepository) invalidate(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if elem, ok := r.hot[key]; ok {
+		r.removeHotLocked(elem)
+	}
+}
+
+// putHotLocked 写入内存层，超出条目上限时淘汰最久未访问的条目
+func (r *tieredCacheRepository) putHotLocked(key, value string, expiresAt int64) {
+	if elem, ok := r.hot[key]; ok {
+		item := elem.Value.(*hotItem)
+		item.value = value
+		item.expiresAt = expiresAt
+		r.lru.MoveToFront(elem)
+		return
+	}
+
+	r.hot[key] = r.lru.PushFront(&hotItem{key: key, value: value, expiresAt: expiresAt})
+	for r.lru.Len() > r.maxItems {
+		r.removeHotLocked(r.lru.Back())
+	}
+}
+
+// removeHotLocked 从内存层移除条目
+func (r *tieredCacheRepository) removeHotLocked(elem *list.Element) {
+	r.lru.Remove(elem)
+	delete(r.hot, elem.Value.(*hotItem).key)
+}
@@ -0,0 +1,229 @@
+// Package repository 带类型的缓存命名空间
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// Codec 缓存值编解码器，缓存仓库中统一存储编码后的字符串
+type Codec[T any] interface {
+	// Encode 编码缓存值
+	Encode(value T) (string, error)
+
+	// Decode 解码缓存值
+	Decode(data string) (T, error)
+}
+
+// JSONCodec JSON编解码器
+type JSONCodec[T any] struct{}
+
+// Encode 编码为JSON
+func (JSONCodec[T]) Encode(value T) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Decode 从JSON解码
+func (JSONCodec[T]) Decode(data string) (T, error) {
+	var value T
+	err := json.Unmarshal([]byte(data), &value)
+	return value, err
+}
+
+// typedCachePrefix 命名空间键前缀，完整前缀为 unm:<namespace>:
+const typedCachePrefix = "unm:"
+
+// NamespacePattern 获取匹配命名空间内所有键的glob模式
+func NamespacePattern(namespace string) string {
+	return typedCachePrefix + namespace + ":*"
+}
+
+// TypedCache 带类型的缓存命名空间，键自动加上命名空间前缀，按命名空间统计命中情况
+type TypedCache[T any] struct {
+	repo      CacheRepository
+	namespace string
+	prefix    string
+	codec     Codec[T]
+	stats     *NamespaceStats
+	logger    logger.Logger
+}
+
+// NewTypedCache 创建带类型的缓存命名空间，repo为nil时所有读取均未命中、写入被忽略
+func NewTypedCache[T any](repo CacheRepository, namespace string, codec Codec[T], metrics *CacheMetrics, log logger.Logger) *TypedCache[T] {
+	return &TypedCache[T]{
+		repo:      repo,
+		namespace: namespace,
+		prefix:    typedCachePrefix + namespace + ":",
+		codec:     codec,
+		stats:     metrics.Namespace(namespace),
+		logger:    log,
+	}
+}
+
+// Get 获取缓存值，不存在、已过期或无法解码时返回false，无法解码的条目会被删除
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, bool) {
+	var zero T
+	if c.repo == nil {
+		return zero, false
+	}
+
+	raw, err := c.repo.Get(ctx, c.prefix+key)
+	if err != nil {
+		c.stats.misses.Add(1)
+		return zero, false
+	}
+
+	value, err := c.decode(raw)
+	if err != nil {
+		c.stats.misses.Add(1)
+		c.stats.errors.Add(1)
+		c.logger.Warn("缓存数据解码失败，删除缓存",
+			logger.String("namespace", c.namespace),
+			logger.String("key", key),
+			logger.ErrorField("error", err),
+		)
+		_ = c.repo.Delete(ctx, c.prefix+key)
+		return zero, false
+	}
+
+	c.stats.hits.Add(1)
+	return value, true
+}
+
+// Set 设置缓存值
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if c.repo == nil {
+		return nil
+	}
+
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		c.stats.errors.Add(1)
+		return fmt.Errorf("缓存数据编码失败: %w", err)
+	}
+	if err := c.repo.Set(ctx, c.prefix+key, data, ttl); err != nil {
+		c.stats.errors.Add(1)
+		return err
+	}
+	c.stats.sets.Add(1)
+	return nil
+}
+
+// Delete 删除缓存值
+func (c *TypedCache[T]) Delete(ctx context.Context, key string) error {
+	if c.repo == nil {
+		return nil
+	}
+	return c.repo.Delete(ctx, c.prefix+key)
+}
+
+// decode 解码仓库返回的值，仓库可能返回字符串或字节切片
+func (c *TypedCache[T]) decode(raw interface{}) (T, error) {
+	switch v := raw.(type) {
+	case string:
+		return c.codec.Decode(v)
+	case []byte:
+		return c.codec.Decode(string(v))
+	default:
+		var zero T
+		return zero, fmt.Errorf("缓存值类型错误: %T", raw)
+	}
+}
+
+// CacheMetrics 按命名空间统计缓存命中情况
+type CacheMetrics struct {
+	mu         sync.Mutex
+	namespaces map[string]*NamespaceStats
+}
+
+// NewCacheMetrics 创建缓存命名空间统计
+func NewCacheMetrics() *CacheMetrics {
+	return &CacheMetrics{namespaces: make(map[string]*NamespaceStats)}
+}
+
+// Namespace 获取命名空间的计数器，不存在时创建；metrics为nil时返回不被汇总的计数器
+func (m *CacheMetrics) Namespace(name string) *NamespaceStats {
+	if m == nil {
+		return &NamespaceStats{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.namespaces[name]
+	if !ok {
+		stats = &NamespaceStats{}
+		m.namespaces[name] = stats
+	}
+	return stats
+}
+
+// Snapshot 获取所有命名空间的统计
+func (m *CacheMetrics) Snapshot() []NamespaceSnapshot {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	names := make([]string, 0, len(m.namespaces))
+	for name := range m.namespaces {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+
+	snapshots := make([]NamespaceSnapshot, 0, len(names))
+	for _, name := range names {
+		snapshots = append(snapshots, m.Namespace(name).snapshot(name))
+	}
+	return snapshots
+}
+
+// NamespaceStats 缓存命名空间计数器
+type NamespaceStats struct {
+	hits     atomic.Int64
+	misses   atomic.Int64
+	sets     atomic.Int64
+	errors   atomic.Int64
+	stale    atomic.Int64
+	negative atomic.Int64
+}
+
+// NamespaceSnapshot 缓存命名空间统计快照
+type NamespaceSnapshot struct {
+	Namespace string  `json:"namespace"` // 命名空间
+	Hits      int64   `json:"hits"`      // 命中次数
+	Misses    int64   `json:"misses"`    // 未命中次数
+	Sets      int64   `json:"sets"`      // 写入次数
+	Errors    int64   `json:"errors"`    // 编解码或写入失败次数
+	Stale     int64   `json:"stale"`     // 命中中返回过期旧值的次数
+	Negative  int64   `json:"negative"`  // 命中中返回否定缓存的次数
+	HitRate   float64 `json:"hit_rate"`  // 命中率（百分比）
+}
+
+// snapshot 生成统计快照
+func (s *NamespaceStats) snapshot(name string) NamespaceSnapshot {
+	snapshot := NamespaceSnapshot{
+		Namespace: name,
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Sets:      s.sets.Load(),
+		Errors:    s.errors.Load(),
+		Stale:     s.stale.Load(),
+		Negative:  s.negative.Load(),
+	}
+	if total := snapshot.Hits + snapshot.Misses; total > 0 {
+		snapshot.HitRate = float64(snapshot.Hits) / float64(total) * 100
+	}
+	return snapshot
+}
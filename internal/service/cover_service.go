@@ -0,0 +1,237 @@
+// Package service 封面图片代理服务
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/repository"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/imageproc"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/useragent"
+)
+
+// ErrUpstreamCover 上游封面请求失败
+var ErrUpstreamCover = errors.New("上游封面请求失败")
+
+// 封面渲染参数
+const (
+	coverDefaultSize = 300        // 默认输出边长
+	coverMinSize     = 16         // 最小输出边长
+	coverMaxSource   = 20 << 20   // 原图大小上限
+	coverMaxPixels   = 40_000_000 // 原图像素数上限，限制解码与缩放占用的内存
+)
+
+// CoverService 封面图片代理服务接口
+type CoverService interface {
+	// Render 获取专辑图并按请求缩放、转换格式，结果缓存在磁盘
+	Render(ctx context.Context, req *model.CoverRequest) (*CoverResult, error)
+}
+
+// CoverResult 渲染后的封面
+type CoverResult struct {
+	Data        []byte // 图片内容
+	ContentType string // 图片Content-Type
+	ETag        string // 内容摘要，带引号
+	Cached      bool   // 是否命中磁盘缓存
+}
+
+// DefaultCoverService 默认封面图片代理服务实现
+type DefaultCoverService struct {
+	musicService MusicService
+	cache        *repository.ImageCache
+	config       *config.CoverProxyConfig
+	client       *http.Client
+	userAgent    string
+	logger       logger.Logger
+}
+
+// NewDefaultCoverService 创建默认封面图片代理服务，请求上游使用流式代理配置的User-Agent
+func NewDefaultCoverService(musicService MusicService, cache *repository.ImageCache, cfg *config.StreamConfig, log logger.Logger) *DefaultCoverService {
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = useragent.Build()
+	}
+
+	return &DefaultCoverService{
+		musicService: musicService,
+		cache:        cache,
+		config:       &cfg.Cover,
+		client:       &http.Client{Timeout: cfg.Cover.FetchTimeout},
+		userAgent:    userAgent,
+		logger:       log,
+	}
+}
+
+// Render 获取专辑图并按请求缩放、转换格式
+func (s *DefaultCoverService) Render(ctx context.Context, req *model.CoverRequest) (*CoverResult, error) {
+	size, format, quality, err := s.normalize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.Join([]string{"cover", req.Platform, req.Source, req.ID, strconv.Itoa(size), format, strconv.Itoa(quality)}, ":")
+	if data, ok := s.cache.Get(key); ok {
+		return newCoverResult(data, format, true), nil
+	}
+
+	original, source, err := s.fetch(ctx, req, size)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imageproc.Decode(original, coverMaxPixels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamCover, err)
+	}
+	// 只缩小不放大，避免小图被插值放大后失真
+	bounds := img.Bounds()
+	img = imageproc.Fit(img, min(size, max(bounds.Dx(), bounds.Dy())))
+
+	data, err := imageproc.Encode(img, format, quality)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Put(key, data); err != nil {
+		s.logger.Warn("写入封面缓存失败",
+			logger.String("id", req.ID),
+			logger.ErrorField("error", err),
+		)
+	}
+
+	s.logger.Debug("封面渲染完成",
+		logger.String("id", req.ID),
+		logger.String("source", source),
+		logger.Int("size", size),
+		logger.String("format", format),
+		logger.Int("original_bytes", len(original)),
+		logger.Int("bytes", len(data)),
+	)
+	return newCoverResult(data, format, false), nil
+}
+
+// normalize 校验并补全渲染参数
+func (s *DefaultCoverService) normalize(req *model.CoverRequest) (int, string, int, error) {
+	if req.ID == "" {
+		return 0, "", 0, fmt.Errorf("参数错误: 专辑图ID不能为空")
+	}
+
+	size := req.Size
+	if size == 0 {
+		size = coverDefaultSize
+	}
+	if size < coverMinSize || size > s.config.MaxDimension {
+		return 0, "", 0, fmt.Errorf("参数错误: size必须在%d-%d之间", coverMinSize, s.config.MaxDimension)
+	}
+
+	format := imageproc.FormatJPEG
+	if req.Format != "" {
+		normalized, err := imageproc.NormalizeFormat(req.Format)
+		if err != nil {
+			return 0, "", 0, fmt.Errorf("参数错误: %w", err)
+		}
+		format = normalized
+	}
+
+	quality := 0
+	if format == imageproc.FormatJPEG {
+		quality = req.Quality
+		if quality == 0 {
+			quality = s.config.DefaultQuality
+		}
+		if quality < 1 || quality > 100 {
+			return 0, "", 0, fmt.Errorf("参数错误: quality必须在1-100之间")
+		}
+	}
+	return size, format, quality, nil
+}
+
+// fetch 获取专辑图原图，返回图片内容与提供专辑图的音源
+func (s *DefaultCoverService) fetch(ctx context.Context, req *model.CoverRequest, size int) ([]byte, string, error) {
+	// 上游只提供300与500两档，按需选择较小的一档以减少下载量
+	upstreamSize := "300"
+	if size > 300 {
+		upstreamSize = "500"
+	}
+
+	picture, err := s.musicService.GetPicture(ctx, req.Source, req.Platform, req.ID, upstreamSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := time.Now()
+	data, err := loadPicture(ctx, s.musicService, s.client, s.userAgent, req.ID, picture.URL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Debug("下载专辑图完成",
+		logger.String("id", req.ID),
+		logger.String("source", picture.Source),
+		logger.Int("bytes", len(data)),
+		logger.String("duration", time.Since(start).String()),
+	)
+	return data, picture.Source, nil
+}
+
+// loadPicture 下载专辑图原图，本地曲库链接或站内相对路径直接读取曲目内嵌封面
+func loadPicture(ctx context.Context, musicService MusicService, client *http.Client, userAgent, trackID, pictureURL string) ([]byte, error) {
+	if parsed, err := url.Parse(pictureURL); err == nil && isLocalLink(parsed) {
+		cover, err := musicService.GetLocalCover(ctx, trackID)
+		if err != nil {
+			return nil, err
+		}
+		return cover.Data, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pictureURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamCover, err)
+	}
+	httpReq.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamCover, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: 上游返回状态码 %d", ErrUpstreamCover, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, coverMaxSource+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamCover, err)
+	}
+	if len(data) > coverMaxSource {
+		return nil, fmt.Errorf("%w: 原图超过 %d 字节", ErrUpstreamCover, coverMaxSource)
+	}
+	return data, nil
+}
+
+// newCoverResult 构造渲染结果，ETag取内容摘要
+func newCoverResult(data []byte, format string, cached bool) *CoverResult {
+	sum := sha256.Sum256(data)
+	return &CoverResult{
+		Data:        data,
+		ContentType: imageproc.ContentType(format),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Cached:      cached,
+	}
+}
@@ -0,0 +1,321 @@
+// Package service 带标签下载服务
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/audiotag"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/lyrics"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/useragent"
+)
+
+// downloadFilenameMaxBytes 下载文件名的最大字节数
+const downloadFilenameMaxBytes = 200
+
+// DownloadService 带标签下载服务接口
+type DownloadService interface {
+	// Open 打开曲目音频并在头部写入标题、艺术家、专辑、封面与歌词标签
+	Open(ctx context.Context, req *model.StreamRequest) (*DownloadResult, error)
+}
+
+// DownloadResult 写入标签后的音频
+type DownloadResult struct {
+	Body          io.ReadCloser // 音频内容，调用方负责关闭
+	ContentType   string        // 音频Content-Type
+	ContentLength int64         // 内容长度，未知时为-1
+	Filename      string        // 下载文件名
+	Source        string        // 提供音频的音源
+	Quality       string        // 实际音质
+	Tagged        bool          // 是否写入了标签，不支持写入的格式原样输出
+}
+
+// downloadFilename 文件名模板变量
+type downloadFilename struct {
+	Title   string
+	Artist  string
+	Album   string
+	ID      string
+	Source  string
+	Quality string
+	Ext     string
+}
+
+// DefaultDownloadService 默认带标签下载服务实现
+type DefaultDownloadService struct {
+	streamService   StreamService
+	musicService    MusicService
+	filename        *template.Template
+	metadataTimeout time.Duration
+	client          *http.Client
+	userAgent       string
+	logger          logger.Logger
+}
+
+// NewDefaultDownloadService 创建默认带标签下载服务，音频经由流式代理服务获取
+func NewDefaultDownloadService(streamService StreamService, musicService MusicService, cfg *config.StreamConfig, log logger.Logger) (*DefaultDownloadService, error) {
+	filename, err := template.New("filename").Parse(cfg.Download.FilenameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("解析下载文件名模板失败: %w", err)
+	}
+
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = useragent.Build()
+	}
+
+	return &DefaultDownloadService{
+		streamService:   streamService,
+		musicService:    musicService,
+		filename:        filename,
+		metadataTimeout: cfg.Download.MetadataTimeout,
+		client:          &http.Client{Timeout: cfg.Download.MetadataTimeout},
+		userAgent:       userAgent,
+		logger:          log,
+	}, nil
+}
+
+// Open 打开曲目音频并写入标签
+func (s *DefaultDownloadService) Open(ctx context.Context, req *model.StreamRequest) (*DownloadResult, error) {
+	// 下载总是获取完整文件，不转发客户端的Range等请求头
+	stream, err := s.streamService.Open(ctx, http.MethodGet, req, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, length, err := s.openAudio(ctx, req, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := s.collectMetadata(ctx, req, stream.Source)
+	if ctx.Err() != nil {
+		body.Close()
+		return nil, ctx.Err()
+	}
+
+	embedded, err := audiotag.Embed(body, meta)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamStream, err)
+	}
+	if length >= 0 {
+		length += embedded.Delta
+	}
+
+	filename := s.renderFilename(req, stream, meta, fileExtension(embedded.Format, contentType))
+	s.logger.Info("开始下载曲目",
+		logger.String("id", req.ID),
+		logger.String("source", stream.Source),
+		logger.String("format", embedded.Format),
+		logger.Bool("tagged", embedded.Tagged),
+		logger.String("filename", filename),
+	)
+
+	return &DownloadResult{
+		Body:          readCloser{Reader: embedded.Reader, Closer: body},
+		ContentType:   contentType,
+		ContentLength: length,
+		Filename:      filename,
+		Source:        stream.Source,
+		Quality:       stream.Quality,
+		Tagged:        embedded.Tagged,
+	}, nil
+}
+
+// openAudio 获取音频内容，本地曲库曲目直接读取文件
+func (s *DefaultDownloadService) openAudio(ctx context.Context, req *model.StreamRequest, stream *StreamResult) (io.ReadCloser, string, int64, error) {
+	if track := stream.File; track != nil {
+		file, err := os.Open(track.Path)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("打开本地曲目失败: %w", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, "", 0, fmt.Errorf("打开本地曲目失败: %w", err)
+		}
+		return file, track.MIMEType, info.Size(), nil
+	}
+
+	if stream.StatusCode != http.StatusOK {
+		stream.Body.Close()
+		return nil, "", 0, fmt.Errorf("%w: 上游返回状态码 %d", ErrUpstreamStream, stream.StatusCode)
+	}
+
+	length := int64(-1)
+	if value := stream.Header.Get("Content-Length"); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			length = parsed
+		}
+	}
+	return stream.Body, stream.Header.Get("Content-Type"), length, nil
+}
+
+// collectMetadata 并行获取曲目信息、歌词与封面，失败的部分不写入标签
+func (s *DefaultDownloadService) collectMetadata(ctx context.Context, req *model.StreamRequest, source string) *audiotag.Metadata {
+	ctx, cancel := context.WithTimeout(ctx, s.metadataTimeout)
+	defer cancel()
+
+	meta := &audiotag.Metadata{}
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		info, err := s.musicService.GetMusicInfo(ctx, source, req.Platform, req.ID)
+		if err != nil {
+			s.logger.Warn("获取曲目信息失败，不写入基本标签",
+				logger.String("id", req.ID),
+				logger.String("source", source),
+				logger.ErrorField("error", err),
+			)
+			return
+		}
+		meta.Title, meta.Artist, meta.Album = info.Name, info.Artist, info.Album
+
+		if info.PicURL == "" {
+			return
+		}
+		data, err := loadPicture(ctx, s.musicService, s.client, s.userAgent, req.ID, info.PicURL)
+		if err != nil {
+			s.logger.Warn("获取封面失败，不写入封面",
+				logger.String("id", req.ID),
+				logger.ErrorField("error", err),
+			)
+			return
+		}
+		meta.Picture = &audiotag.Picture{MIMEType: audiotag.DetectPictureMIME(data), Data: data}
+	}()
+
+	go func() {
+		defer wg.Done()
+		lyric, err := s.musicService.GetLyric(ctx, source, req.Platform, req.ID)
+		if err != nil {
+			s.logger.Debug("获取歌词失败，不写入歌词",
+				logger.String("id", req.ID),
+				logger.ErrorField("error", err),
+			)
+			return
+		}
+		meta.Lyrics = lyric.Lyric
+		meta.SyncedLyrics = syncedLyrics(lyric.Lyric)
+	}()
+
+	wg.Wait()
+	return meta
+}
+
+// renderFilename 按模板生成下载文件名
+func (s *DefaultDownloadService) renderFilename(req *model.StreamRequest, stream *StreamResult, meta *audiotag.Metadata, ext string) string {
+	data := downloadFilename{
+		Title:   meta.Title,
+		Artist:  meta.Artist,
+		Album:   meta.Album,
+		ID:      req.ID,
+		Source:  stream.Source,
+		Quality: stream.Quality,
+		Ext:     ext,
+	}
+	if data.Title == "" {
+		data.Title = req.ID
+	}
+	if data.Artist == "" {
+		data.Artist = "未知艺术家"
+	}
+	if data.Album == "" {
+		data.Album = "未知专辑"
+	}
+
+	var buf bytes.Buffer
+	if err := s.filename.Execute(&buf, data); err != nil {
+		s.logger.Warn("生成下载文件名失败", logger.ErrorField("error", err))
+		buf.Reset()
+	}
+	if name := sanitizeFilename(buf.String()); name != "" {
+		return name
+	}
+	return req.ID + "." + ext
+}
+
+// sanitizeFilename 替换文件名中的路径分隔符与系统保留字符，并限制长度
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20 || r == 0x7F:
+			return -1
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		default:
+			return r
+		}
+	}, name)
+	name = strings.Trim(name, " .")
+
+	if len(name) > downloadFilenameMaxBytes {
+		// 截断时保留扩展名
+		ext := ""
+		if i := strings.LastIndexByte(name, '.'); i > 0 && len(name)-i <= 8 {
+			ext = name[i:]
+		}
+		cut := downloadFilenameMaxBytes - len(ext)
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = strings.TrimRight(name[:cut], " .") + ext
+	}
+	return name
+}
+
+// downloadExtensions 按Content-Type推断的文件扩展名，用于无法从内容识别格式的音频
+var downloadExtensions = map[string]string{
+	"audio/mpeg": "mp3",
+	"audio/flac": "flac",
+	"audio/mp4":  "m4a",
+	"audio/aac":  "aac",
+	"audio/ogg":  "ogg",
+	"audio/wav":  "wav",
+}
+
+// fileExtension 获取下载文件扩展名，优先使用识别出的音频格式
+func fileExtension(format, contentType string) string {
+	if format != "" {
+		return format
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if ext, ok := downloadExtensions[mediaType]; ok {
+			return ext
+		}
+	}
+	return "bin"
+}
+
+// syncedLyrics 将LRC歌词转换为逐行歌词
+func syncedLyrics(lrc string) []audiotag.LyricLine {
+	parsed := lyrics.Parse(lrc)
+	lines := make([]audiotag.LyricLine, 0, len(parsed.Lines))
+	for _, line := range parsed.Lines {
+		lines = append(lines, audiotag.LyricLine{Time: line.Time, Text: line.Text})
+	}
+	return lines
+}
+
+// readCloser 组合读取器与关闭器
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -3,17 +3,25 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/repository"
-	"github.com/IIXINGCHEN/music-api-proxy/internal/repository/sources"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/lyrics"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/singleflight"
 )
 
+// otherMusicCandidates 获取其他音源音乐时最多尝试的搜索结果数
+const otherMusicCandidates = 3
+
+// staleRefreshTimeout 后台刷新过期缓存的超时时间
+const staleRefreshTimeout = 30 * time.Second
+
 // MusicService 音乐服务接口
 type MusicService interface {
 	// MatchMusic 匹配音乐
@@ -26,44 +34,102 @@ type MusicService interface {
 	GetOtherMusic(ctx context.Context, req *model.OtherGetRequest) (*model.OtherGetResponse, error)
 
 	// SearchMusic 搜索音乐
-	SearchMusic(ctx context.Context, keyword string, sources []string) ([]*model.SearchResult, error)
+	SearchMusic(ctx context.Context, keyword, platform string, sources []string) ([]*model.SearchResult, error)
 
 	// GetMusicInfo 获取音乐信息
-	GetMusicInfo(ctx context.Context, source, id string) (*model.MusicInfo, error)
+	GetMusicInfo(ctx context.Context, source, platform, id string) (*model.MusicInfo, error)
 
 	// GetPicture 获取专辑图
-	GetPicture(ctx context.Context, sourceName, picID, size string) (string, error)
+	GetPicture(ctx context.Context, sourceName, platform, picID, size string) (*model.PictureResult, error)
 
 	// GetLyric 获取歌词
-	GetLyric(ctx context.Context, sourceName, lyricID string) (string, string, error)
+	GetLyric(ctx context.Context, sourceName, platform, lyricID string) (*model.LyricResult, error)
+
+	// GetLyricTimeline 获取解析后的时间轴歌词，翻译按时间合并到原文行，offset为整体时间偏移
+	GetLyricTimeline(ctx context.Context, sourceName, platform, lyricID string, offset time.Duration) (*model.LyricTimeline, error)
+
+	// GetKaraokeLyric 获取卡拉OK模式歌词，每行带起止时间与逐字时间
+	GetKaraokeLyric(ctx context.Context, sourceName, platform, lyricID string, offset time.Duration) (*model.KaraokeLyric, error)
+
+	// ExportLyric 将歌词导出为SRT、WebVTT或TTML字幕
+	ExportLyric(ctx context.Context, sourceName, platform, lyricID string, opts *model.LyricExportOptions) (*model.LyricExport, error)
+
+	// GetLocalTrack 获取本地曲库曲目文件
+	GetLocalTrack(ctx context.Context, id string) (*model.LocalTrackFile, error)
+
+	// GetLocalCover 获取本地曲库曲目内嵌封面
+	GetLocalCover(ctx context.Context, id string) (*model.CoverImage, error)
 }
 
 // DefaultMusicService 默认音乐服务实现
 type DefaultMusicService struct {
 	sourceManager repository.SourceManager
-	cache         repository.CacheRepository
 	rateLimiter   repository.RateLimiter
 	logger        logger.Logger
 	configManager *config.SourceConfigManager
+	streamLinker  *StreamLinker
+
+	// 按结果类型划分的缓存命名空间，各自使用配置的缓存策略
+	matchCache  *repository.PolicyCache[*model.MatchResponse]
+	ncmCache    *repository.PolicyCache[*model.NCMGetResponse]
+	otherCache  *repository.PolicyCache[*model.OtherGetResponse]
+	searchCache *repository.PolicyCache[[]*model.SearchResult]
+	infoCache   *repository.PolicyCache[*model.MusicInfo]
+	lyricCache  *repository.PolicyCache[*model.LyricResult]
+
+	// 合并缓存未命中时相同参数的并发上游请求，键与缓存键一致
+	matchFlight  singleflight.Group[*model.MatchResponse]
+	ncmFlight    singleflight.Group[*model.NCMGetResponse]
+	otherFlight  singleflight.Group[*model.OtherGetResponse]
+	searchFlight singleflight.Group[[]*model.SearchResult]
+	infoFlight   singleflight.Group[*model.MusicInfo]
+	lyricFlight  singleflight.Group[*model.LyricResult]
 }
 
-// NewDefaultMusicService 创建默认音乐服务
+// NewDefaultMusicService 创建默认音乐服务，cacheMetrics汇总各缓存命名空间的命中情况，policies为各类查询的缓存策略
 func NewDefaultMusicService(
 	sourceManager repository.SourceManager,
 	cache repository.CacheRepository,
+	cacheMetrics *repository.CacheMetrics,
+	policies *config.CachePoliciesConfig,
 	rateLimiter repository.RateLimiter,
 	configManager *config.SourceConfigManager,
+	streamLinker *StreamLinker,
 	log logger.Logger,
 ) *DefaultMusicService {
 	return &DefaultMusicService{
 		sourceManager: sourceManager,
-		cache:         cache,
 		rateLimiter:   rateLimiter,
 		logger:        log,
 		configManager: configManager,
+		streamLinker:  streamLinker,
+		matchCache:    repository.NewPolicyCache[*model.MatchResponse](cache, "match", cachePolicy(policies.Match), cacheMetrics, log),
+		ncmCache:      repository.NewPolicyCache[*model.NCMGetResponse](cache, "ncm", cachePolicy(policies.NCM), cacheMetrics, log),
+		otherCache:    repository.NewPolicyCache[*model.OtherGetResponse](cache, "other", cachePolicy(policies.Other), cacheMetrics, log),
+		searchCache:   repository.NewPolicyCache[[]*model.SearchResult](cache, "search", cachePolicy(policies.Search), cacheMetrics, log),
+		infoCache:     repository.NewPolicyCache[*model.MusicInfo](cache, "info", cachePolicy(policies.Info), cacheMetrics, log),
+		lyricCache:    repository.NewPolicyCache[*model.LyricResult](cache, "lyric", cachePolicy(policies.Lyric), cacheMetrics, log),
 	}
 }
 
+// cachePolicy 转换缓存策略配置
+func cachePolicy(cfg config.CachePolicyConfig) repository.CachePolicy {
+	return repository.CachePolicy{
+		TTL:         cfg.TTL,
+		StaleTTL:    cfg.StaleTTL,
+		NegativeTTL: cfg.NegativeTTL,
+	}
+}
+
+// revalidate 在后台重新获取过期的缓存条目，与相同键的请求合并，ctx仅用于传递上下文中的值
+func revalidate[T any](ctx context.Context, group *singleflight.Group[T], key string, fetch func(ctx context.Context) (T, error)) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), staleRefreshTimeout)
+		defer cancel()
+		_, _, _ = group.Do(ctx, key, fetch)
+	}()
+}
+
 // MatchMusic 匹配音乐
 func (s *DefaultMusicService) MatchMusic(ctx context.Context, req *model.MatchRequest) (*model.MatchResponse, error) {
 	if req == nil {
@@ -73,6 +139,32 @@ func (s *DefaultMusicService) MatchMusic(ctx context.Context, req *model.MatchRe
 	if req.ID == "" {
 		return nil, fmt.Errorf("音乐ID不能为空")
 	}
+
+	// 解析音乐平台
+	platform, err := s.resolvePlatform(req.Platform)
+	if err != nil {
+		return nil, err
+	}
+	ctx = model.WithPlatform(ctx, platform)
+
+	// 解析匹配策略
+	if ctx, err = s.withMatchStrategy(ctx, req.Strategy); err != nil {
+		return nil, err
+	}
+
+	// 解析期望音质与音质协商策略
+	br := req.BR
+	if br == "" {
+		br = model.DefaultQuality
+	}
+	if !model.IsValidQuality(br) {
+		return nil, fmt.Errorf("不支持的音质参数: %s，支持的音质: %v", br, model.GetValidQualities())
+	}
+	policy, err := s.resolveQualityPolicy(req.QualityPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ctx = model.WithQualityPolicy(ctx, policy)
 	
 	// 解析音源列表（使用配置管理器）
 	sources := req.Sources
@@ -87,48 +179,94 @@ func (s *DefaultMusicService) MatchMusic(ctx context.Context, req *model.MatchRe
 	
 	s.logger.Info("开始匹配音乐",
 		logger.String("id", req.ID),
+		logger.String("platform", platform),
+		logger.String("br", br),
+		logger.String("quality_policy", policy),
 		logger.Any("sources", sources),
 	)
 	
 	// 检查限流
-	if err := s.checkRateLimit(ctx, "match:"+req.ID); err != nil {
+	if err := s.checkRateLimit(ctx, "match:"+platform+":"+req.ID); err != nil {
 		return nil, err
 	}
 	
-	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("unm:match:%s:320", req.ID)
-	if cachedResult, err := s.getFromCache(ctx, cacheKey); err == nil {
-		s.logger.Info("从缓存获取匹配结果", logger.String("id", req.ID))
-		// 安全的类型转换
-		if matchResp, ok := cachedResult.(*model.MatchResponse); ok {
-			return matchResp, nil
+	// 缓存的是签名前的结果，命中后重新生成代理链接以免链接过期
+	cacheKey := fmt.Sprintf("%s:%s:%s:%s:%s:%s", platform, req.ID, br, policy, model.NormalizeMatchStrategy(req.Strategy), strings.Join(sources, ","))
+	fetch := func(ctx context.Context) (*model.MatchResponse, error) {
+		// 使用音源管理器匹配音乐
+		result, err := s.sourceManager.MatchMusic(ctx, req.ID, sources, br)
+		if err != nil {
+			s.logger.Error("匹配音乐失败",
+				logger.String("id", req.ID),
+				logger.ErrorField("error", err),
+			)
+			err = fmt.Errorf("匹配音乐失败: %w", err)
+			if cacheErr := s.matchCache.SetNotFound(ctx, cacheKey, err); cacheErr != nil {
+				s.logger.Warn("缓存未找到结果失败",
+					logger.String("id", req.ID),
+					logger.ErrorField("error", cacheErr),
+				)
+			}
+			return nil, err
+		}
+
+		// 缓存结果
+		if err := s.matchCache.Set(ctx, cacheKey, result); err != nil {
+			s.logger.Warn("缓存匹配结果失败",
+				logger.String("id", req.ID),
+				logger.ErrorField("error", err),
+			)
 		}
-		// 如果类型不匹配，清除缓存并继续
-		s.logger.Warn("缓存数据类型不匹配，清除缓存", logger.String("id", req.ID))
-		_ = s.clearFromCache(ctx, cacheKey)
+		return result, nil
 	}
-	
-	// 使用音源管理器匹配音乐
-	result, err := s.sourceManager.MatchMusic(ctx, req.ID, sources, "320")
+
+	// 尝试从缓存获取，过期的旧值先返回并在后台刷新
+	result, status, err := s.matchCache.Get(ctx, cacheKey)
+	model.RecordCacheStatus(ctx, status)
 	if err != nil {
-		s.logger.Error("匹配音乐失败",
-			logger.String("id", req.ID),
-			logger.ErrorField("error", err),
-		)
-		return nil, fmt.Errorf("匹配音乐失败: %w", err)
+		return nil, err
 	}
-	
-	// 缓存结果
-	if err := s.setToCache(ctx, cacheKey, result, 5*time.Minute); err != nil {
-		s.logger.Warn("缓存匹配结果失败",
-			logger.String("id", req.ID),
-			logger.ErrorField("error", err),
-		)
+	switch status {
+	case model.CacheStatusHit:
+		s.logger.Info("从缓存获取匹配结果", logger.String("id", req.ID))
+	case model.CacheStatusStale:
+		s.logger.Info("从过期缓存获取匹配结果，后台刷新", logger.String("id", req.ID))
+		revalidate(ctx, &s.matchFlight, cacheKey, fetch)
+	default:
+		// 相同参数的并发请求只匹配一次，其余请求等待并共享结果
+		var shared bool
+		result, shared, err = s.matchFlight.Do(ctx, cacheKey, fetch)
+		if err != nil {
+			return nil, err
+		}
+
+		// 共享的结果复制后再填充链接
+		if shared {
+			copied := *result
+			result = &copied
+		}
 	}
 	
+	// 填充流式代理与下载链接，固定为匹配成功的音源
+	streamReq := &model.StreamRequest{
+		ID:            req.ID,
+		Server:        result.Source,
+		Platform:      platform,
+		BR:            br,
+		QualityPolicy: policy,
+	}
+	if link := s.streamLinker.Link(streamReq); link != "" {
+		result.ProxyURL = link
+	}
+	result.DownloadURL = s.streamLinker.DownloadLink(streamReq)
+	result.URL = s.streamLinker.LocalLink(result.URL)
+	result.Info = s.signLocalInfo(result.Info)
+	
 	s.logger.Info("匹配音乐成功",
 		logger.String("id", req.ID),
 		logger.String("source", result.Source),
+		logger.String("requested_quality", br),
+		logger.String("quality", result.Quality),
 		logger.String("url", result.URL),
 	)
 	
@@ -148,7 +286,7 @@ func (s *DefaultMusicService) GetNCMMusic(ctx context.Context, req *model.NCMGet
 	// 设置默认音质
 	br := req.BR
 	if br == "" {
-		br = "320"
+		br = model.DefaultQuality
 	}
 	
 	// 验证音质参数
@@ -160,66 +298,131 @@ func (s *DefaultMusicService) GetNCMMusic(ctx context.Context, req *model.NCMGet
 		logger.String("id", req.ID),
 		logger.String("br", br),
 	)
+
+	// 网易云接口固定使用netease平台
+	ctx = model.WithPlatform(ctx, model.PlatformNetease)
+
+	// 解析匹配策略
+	ctx, err := s.withMatchStrategy(ctx, req.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析音质协商策略
+	policy, err := s.resolveQualityPolicy(req.QualityPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ctx = model.WithQualityPolicy(ctx, policy)
 	
 	// 检查限流
 	if err := s.checkRateLimit(ctx, "ncm:"+req.ID); err != nil {
 		return nil, err
 	}
 	
-	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("unm:ncm:%s:%s", req.ID, br)
-	if cachedResult, err := s.getFromCache(ctx, cacheKey); err == nil {
-		s.logger.Info("从缓存获取网易云音乐", logger.String("id", req.ID))
-		if ncmResp, ok := cachedResult.(*model.NCMGetResponse); ok {
-			return ncmResp, nil
+	// 缓存的是签名前的结果，命中后重新生成代理链接以免链接过期
+	cacheKey := fmt.Sprintf("%s:%s:%s:%s:%s", model.PlatformNetease, req.ID, br, policy, model.NormalizeMatchStrategy(req.Strategy))
+	fetch := func(ctx context.Context) (*model.NCMGetResponse, error) {
+		// 使用可用的音源进行匹配
+		var availableSources []string
+		if s.configManager != nil {
+			availableSources = s.configManager.GetAvailableSources()
+		} else {
+			availableSources = []string{"unm_server", "gdstudio"}
 		}
-		_ = s.clearFromCache(ctx, cacheKey)
-	}
-	
-	// 使用可用的音源进行匹配
-	var availableSources []string
-	if s.configManager != nil {
-		availableSources = s.configManager.GetAvailableSources()
-	} else {
-		availableSources = []string{"unm_server", "gdstudio"}
+
+		// 使用音源管理器匹配音乐
+		matchResult, err := s.sourceManager.MatchMusic(ctx, req.ID, availableSources, br)
+		if err != nil {
+			s.logger.Error("获取网易云音乐失败",
+				logger.String("id", req.ID),
+				logger.String("br", br),
+				logger.ErrorField("error", err),
+			)
+			err = fmt.Errorf("获取网易云音乐失败: %w", err)
+			if cacheErr := s.ncmCache.SetNotFound(ctx, cacheKey, err); cacheErr != nil {
+				s.logger.Warn("缓存未找到结果失败",
+					logger.String("id", req.ID),
+					logger.ErrorField("error", cacheErr),
+				)
+			}
+			return nil, err
+		}
+
+		// 构建响应
+		response := &model.NCMGetResponse{
+			ID:            req.ID,
+			BR:            br,
+			URL:           matchResult.URL,
+			ProxyURL:      matchResult.ProxyURL,
+			Quality:       matchResult.Quality,
+			QualityPolicy: matchResult.QualityPolicy,
+			Format:        matchResult.Format,
+			Size:          matchResult.Size,
+			Source:        matchResult.Source,
+		}
+
+		// 使用匹配结果中的音乐信息
+		if matchResult.Info != nil {
+			response.Info = matchResult.Info
+		}
+
+		// 缓存结果
+		if err := s.ncmCache.Set(ctx, cacheKey, response); err != nil {
+			s.logger.Warn("缓存网易云音乐失败",
+				logger.String("id", req.ID),
+				logger.ErrorField("error", err),
+			)
+		}
+		return response, nil
 	}
 
-	// 使用音源管理器匹配音乐
-	matchResult, err := s.sourceManager.MatchMusic(ctx, req.ID, availableSources, br)
+	// 尝试从缓存获取，过期的旧值先返回并在后台刷新
+	response, status, err := s.ncmCache.Get(ctx, cacheKey)
+	model.RecordCacheStatus(ctx, status)
 	if err != nil {
-		s.logger.Error("获取网易云音乐失败",
-			logger.String("id", req.ID),
-			logger.String("br", br),
-			logger.ErrorField("error", err),
-		)
-		return nil, fmt.Errorf("获取网易云音乐失败: %w", err)
-	}
-	
-	// 构建响应
-	response := &model.NCMGetResponse{
-		ID:       req.ID,
-		BR:       br,
-		URL:      matchResult.URL,
-		ProxyURL: matchResult.ProxyURL,
-		Quality:  matchResult.Quality,
+		return nil, err
 	}
+	switch status {
+	case model.CacheStatusHit:
+		s.logger.Info("从缓存获取网易云音乐", logger.String("id", req.ID))
+	case model.CacheStatusStale:
+		s.logger.Info("从过期缓存获取网易云音乐，后台刷新", logger.String("id", req.ID))
+		revalidate(ctx, &s.ncmFlight, cacheKey, fetch)
+	default:
+		// 相同参数的并发请求只匹配一次，其余请求等待并共享结果
+		var shared bool
+		response, shared, err = s.ncmFlight.Do(ctx, cacheKey, fetch)
+		if err != nil {
+			return nil, err
+		}
 
-	// 使用匹配结果中的音乐信息
-	if matchResult.Info != nil {
-		response.Info = matchResult.Info
-	}
-	
-	// 缓存结果
-	if err := s.setToCache(ctx, cacheKey, response, 5*time.Minute); err != nil {
-		s.logger.Warn("缓存网易云音乐失败",
-			logger.String("id", req.ID),
-			logger.ErrorField("error", err),
-		)
+		// 共享的结果复制后再填充链接
+		if shared {
+			copied := *response
+			response = &copied
+		}
 	}
+
+	// 填充流式代理与下载链接，固定为匹配成功的音源
+	streamReq := &model.StreamRequest{
+		ID:            req.ID,
+		Server:        response.Source,
+		Platform:      model.PlatformNetease,
+		BR:            br,
+		QualityPolicy: policy,
+	}
+	if link := s.streamLinker.Link(streamReq); link != "" {
+		response.ProxyURL = link
+	}
+	response.DownloadURL = s.streamLinker.DownloadLink(streamReq)
+	response.URL = s.streamLinker.LocalLink(response.URL)
+	response.Info = s.signLocalInfo(response.Info)
 	
 	s.logger.Info("获取网易云音乐成功",
 		logger.String("id", req.ID),
 		logger.String("br", br),
+		logger.String("quality", response.Quality),
 		logger.String("url", response.URL),
 	)
 	
@@ -243,52 +446,114 @@ func (s *DefaultMusicService) GetOtherMusic(ctx context.Context, req *model.Othe
 		return nil, err
 	}
 	
-	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("unm:search:other:%s", req.Name)
-	if cachedResult, err := s.getFromCache(ctx, cacheKey); err == nil {
+	fetch := func(ctx context.Context) (*model.OtherGetResponse, error) {
+		return s.fetchOtherMusic(ctx, req.Name)
+	}
+
+	// 尝试从缓存获取，过期的旧值先返回并在后台刷新
+	cached, status, err := s.otherCache.Get(ctx, req.Name)
+	model.RecordCacheStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case model.CacheStatusHit:
 		s.logger.Info("从缓存获取其他音源音乐", logger.String("name", req.Name))
-		if otherResp, ok := cachedResult.(*model.OtherGetResponse); ok {
-			return otherResp, nil
-		}
-		_ = s.clearFromCache(ctx, cacheKey)
+		return s.signLocalOther(cached), nil
+	case model.CacheStatusStale:
+		s.logger.Info("从过期缓存获取其他音源音乐，后台刷新", logger.String("name", req.Name))
+		revalidate(ctx, &s.otherFlight, req.Name, fetch)
+		return s.signLocalOther(cached), nil
+	}
+	
+	// 相同歌曲名的并发请求只搜索一次，其余请求等待并共享结果
+	response, _, err := s.otherFlight.Do(ctx, req.Name, fetch)
+	if err != nil {
+		return nil, err
 	}
 	
+	s.logger.Info("获取其他音源音乐成功",
+		logger.String("name", req.Name),
+		logger.String("source", response.Source),
+		logger.String("url", response.URL),
+	)
+	
+	return s.signLocalOther(response), nil
+}
+
+// fetchOtherMusic 搜索歌曲并获取最佳结果的播放链接，成功后写入缓存，没有搜索结果时写入否定缓存
+func (s *DefaultMusicService) fetchOtherMusic(ctx context.Context, name string) (*model.OtherGetResponse, error) {
 	// 搜索音乐
-	searchResults, err := s.sourceManager.SearchMusic(ctx, req.Name, nil)
+	searchResults, err := s.sourceManager.SearchMusic(ctx, name, nil)
 	if err != nil {
 		s.logger.Error("搜索音乐失败",
-			logger.String("name", req.Name),
+			logger.String("name", name),
 			logger.ErrorField("error", err),
 		)
 		return nil, fmt.Errorf("搜索音乐失败: %w", err)
 	}
 	
 	if len(searchResults) == 0 {
-		return nil, fmt.Errorf("未找到歌曲: %s", req.Name)
+		err := fmt.Errorf("%w歌曲: %s", repository.ErrNotFound, name)
+		if cacheErr := s.otherCache.SetNotFound(ctx, name, err); cacheErr != nil {
+			s.logger.Warn("缓存未找到结果失败",
+				logger.String("name", name),
+				logger.ErrorField("error", cacheErr),
+			)
+		}
+		return nil, err
 	}
 	
-	// 选择最佳匹配结果
-	bestResult := searchResults[0]
-	
-	// 获取播放链接
-	source, err := s.sourceManager.GetSource(bestResult.Source)
-	if err != nil {
-		return nil, fmt.Errorf("音源不可用: %w", err)
+	// 按评分从高到低尝试获取播放链接，最佳结果不可播放时回退到下一个
+	var (
+		bestResult *model.SearchResult
+		musicURL   *model.MusicURL
+		lastErr    error
+	)
+	for i, candidate := range searchResults {
+		if i >= otherMusicCandidates {
+			break
+		}
+		
+		source, err := s.sourceManager.GetSource(candidate.Source)
+		if err != nil {
+			lastErr = fmt.Errorf("音源不可用: %w", err)
+			continue
+		}
+		
+		musicURL, err = source.GetMusic(model.WithPlatform(ctx, candidate.Platform), candidate.ID, "320")
+		if err == nil && musicURL != nil && musicURL.URL != "" {
+			bestResult = candidate
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("音源 %s 未返回播放链接", candidate.Source)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		
+		s.logger.Warn("获取播放链接失败，尝试下一个搜索结果",
+			logger.String("name", name),
+			logger.String("source", candidate.Source),
+			logger.String("id", candidate.ID),
+			logger.Float64("score", candidate.Score),
+			logger.ErrorField("error", err),
+		)
+		lastErr = err
 	}
 	
-	musicURL, err := source.GetMusic(ctx, bestResult.ID, "320")
-	if err != nil {
+	if bestResult == nil {
 		s.logger.Error("获取播放链接失败",
-			logger.String("name", req.Name),
-			logger.String("source", bestResult.Source),
-			logger.ErrorField("error", err),
+			logger.String("name", name),
+			logger.ErrorField("error", lastErr),
 		)
-		return nil, fmt.Errorf("获取播放链接失败: %w", err)
+		return nil, fmt.Errorf("获取播放链接失败: %w", lastErr)
 	}
 	
 	// 构建响应
 	response := &model.OtherGetResponse{
-		Name:    req.Name,
+		Name:    name,
 		URL:     musicURL.URL,
 		Source:  bestResult.Source,
 		Quality: musicURL.Quality,
@@ -298,36 +563,37 @@ func (s *DefaultMusicService) GetOtherMusic(ctx context.Context, req *model.Othe
 			Artist:   bestResult.Artist,
 			Album:    bestResult.Album,
 			Duration: bestResult.Duration,
+			Platform: bestResult.Platform,
 		},
 	}
 	
 	// 缓存结果
-	if err := s.setToCache(ctx, cacheKey, response, 5*time.Minute); err != nil {
+	if err := s.otherCache.Set(ctx, name, response); err != nil {
 		s.logger.Warn("缓存其他音源音乐失败",
-			logger.String("name", req.Name),
+			logger.String("name", name),
 			logger.ErrorField("error", err),
 		)
 	}
 	
-	s.logger.Info("获取其他音源音乐成功",
-		logger.String("name", req.Name),
-		logger.String("source", response.Source),
-		logger.String("url", response.URL),
-	)
-	
 	return response, nil
 }
 
-
-
 // SearchMusic 搜索音乐
-func (s *DefaultMusicService) SearchMusic(ctx context.Context, keyword string, sources []string) ([]*model.SearchResult, error) {
+func (s *DefaultMusicService) SearchMusic(ctx context.Context, keyword, platform string, sources []string) ([]*model.SearchResult, error) {
 	if keyword == "" {
 		return nil, fmt.Errorf("搜索关键词不能为空")
 	}
+
+	// 解析音乐平台
+	platform, err := s.resolvePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	ctx = model.WithPlatform(ctx, platform)
 	
 	s.logger.Info("开始搜索音乐",
 		logger.String("keyword", keyword),
+		logger.String("platform", platform),
 		logger.Any("sources", sources),
 	)
 	
@@ -336,29 +602,48 @@ func (s *DefaultMusicService) SearchMusic(ctx context.Context, keyword string, s
 		return nil, err
 	}
 	
-	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("unm:search:all:%s", keyword)
-	if cachedResult, err := s.getSearchResultsFromCache(ctx, cacheKey); err == nil {
-		s.logger.Info("从缓存获取搜索结果", logger.String("keyword", keyword))
-		return cachedResult, nil
+	cacheKey := fmt.Sprintf("%s:%s:%s", platform, keyword, strings.Join(sources, ","))
+	fetch := func(ctx context.Context) ([]*model.SearchResult, error) {
+		// 使用音源管理器搜索
+		results, err := s.sourceManager.SearchMusic(ctx, keyword, sources)
+		if err != nil {
+			s.logger.Error("搜索音乐失败",
+				logger.String("keyword", keyword),
+				logger.ErrorField("error", err),
+			)
+			return nil, fmt.Errorf("搜索音乐失败: %w", err)
+		}
+
+		// 缓存结果
+		if err := s.searchCache.Set(ctx, cacheKey, results); err != nil {
+			s.logger.Warn("缓存搜索结果失败",
+				logger.String("keyword", keyword),
+				logger.ErrorField("error", err),
+			)
+		}
+		return results, nil
 	}
-	
-	// 使用音源管理器搜索
-	results, err := s.sourceManager.SearchMusic(ctx, keyword, sources)
+
+	// 尝试从缓存获取，过期的旧值先返回并在后台刷新
+	cached, status, err := s.searchCache.Get(ctx, cacheKey)
+	model.RecordCacheStatus(ctx, status)
 	if err != nil {
-		s.logger.Error("搜索音乐失败",
-			logger.String("keyword", keyword),
-			logger.ErrorField("error", err),
-		)
-		return nil, fmt.Errorf("搜索音乐失败: %w", err)
+		return nil, err
+	}
+	switch status {
+	case model.CacheStatusHit:
+		s.logger.Info("从缓存获取搜索结果", logger.String("keyword", keyword))
+		return cached, nil
+	case model.CacheStatusStale:
+		s.logger.Info("从过期缓存获取搜索结果，后台刷新", logger.String("keyword", keyword))
+		revalidate(ctx, &s.searchFlight, cacheKey, fetch)
+		return cached, nil
 	}
 	
-	// 缓存结果
-	if err := s.setToCache(ctx, cacheKey, results, 10*time.Minute); err != nil {
-		s.logger.Warn("缓存搜索结果失败",
-			logger.String("keyword", keyword),
-			logger.ErrorField("error", err),
-		)
+	// 相同关键词的并发请求只搜索一次，其余请求等待并共享结果
+	results, _, err := s.searchFlight.Do(ctx, cacheKey, fetch)
+	if err != nil {
+		return nil, err
 	}
 	
 	s.logger.Info("搜索音乐成功",
@@ -370,7 +655,7 @@ func (s *DefaultMusicService) SearchMusic(ctx context.Context, keyword string, s
 }
 
 // GetMusicInfo 获取音乐信息
-func (s *DefaultMusicService) GetMusicInfo(ctx context.Context, sourceName, id string) (*model.MusicInfo, error) {
+func (s *DefaultMusicService) GetMusicInfo(ctx context.Context, sourceName, platform, id string) (*model.MusicInfo, error) {
 	if sourceName == "" {
 		return nil, fmt.Errorf("音源名称不能为空")
 	}
@@ -378,54 +663,81 @@ func (s *DefaultMusicService) GetMusicInfo(ctx context.Context, sourceName, id s
 	if id == "" {
 		return nil, fmt.Errorf("音乐ID不能为空")
 	}
+
+	// 解析音乐平台
+	platform, err := s.resolvePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	ctx = model.WithPlatform(ctx, platform)
 	
 	s.logger.Info("开始获取音乐信息",
 		logger.String("source", sourceName),
+		logger.String("platform", platform),
 		logger.String("id", id),
 	)
 	
 	// 检查限流
-	if err := s.checkRateLimit(ctx, "info:"+sourceName+":"+id); err != nil {
+	if err := s.checkRateLimit(ctx, "info:"+sourceName+":"+platform+":"+id); err != nil {
 		return nil, err
 	}
 	
-	// 尝试从缓存获取
-	cacheKey := fmt.Sprintf("unm:info:%s:%s", sourceName, id)
-	if cachedResult, err := s.getFromCache(ctx, cacheKey); err == nil {
-		s.logger.Info("从缓存获取音乐信息",
-			logger.String("source", sourceName),
-			logger.String("id", id),
-		)
-		if musicInfo, ok := cachedResult.(*model.MusicInfo); ok {
-			return musicInfo, nil
-		}
-		_ = s.clearFromCache(ctx, cacheKey)
-	}
-	
 	// 获取音源
 	source, err := s.sourceManager.GetSource(sourceName)
 	if err != nil {
 		return nil, fmt.Errorf("音源不可用: %w", err)
 	}
 	
-	// 获取音乐信息
-	info, err := source.GetMusicInfo(ctx, id)
+	cacheKey := fmt.Sprintf("%s:%s:%s", sourceName, platform, id)
+	fetch := func(ctx context.Context) (*model.MusicInfo, error) {
+		// 获取音乐信息
+		info, err := source.GetMusicInfo(ctx, id)
+		if err != nil {
+			s.logger.Error("获取音乐信息失败",
+				logger.String("source", sourceName),
+				logger.String("id", id),
+				logger.ErrorField("error", err),
+			)
+			return nil, fmt.Errorf("获取音乐信息失败: %w", err)
+		}
+
+		// 缓存结果
+		if err := s.infoCache.Set(ctx, cacheKey, info); err != nil {
+			s.logger.Warn("缓存音乐信息失败",
+				logger.String("source", sourceName),
+				logger.String("id", id),
+				logger.ErrorField("error", err),
+			)
+		}
+		return info, nil
+	}
+
+	// 尝试从缓存获取，过期的旧值先返回并在后台刷新
+	cached, status, err := s.infoCache.Get(ctx, cacheKey)
+	model.RecordCacheStatus(ctx, status)
 	if err != nil {
-		s.logger.Error("获取音乐信息失败",
+		return nil, err
+	}
+	switch status {
+	case model.CacheStatusHit:
+		s.logger.Info("从缓存获取音乐信息",
 			logger.String("source", sourceName),
 			logger.String("id", id),
-			logger.ErrorField("error", err),
 		)
-		return nil, fmt.Errorf("获取音乐信息失败: %w", err)
-	}
-	
-	// 缓存结果
-	if err := s.setToCache(ctx, cacheKey, info, 30*time.Minute); err != nil {
-		s.logger.Warn("缓存音乐信息失败",
+		return s.signLocalInfo(cached), nil
+	case model.CacheStatusStale:
+		s.logger.Info("从过期缓存获取音乐信息，后台刷新",
 			logger.String("source", sourceName),
 			logger.String("id", id),
-			logger.ErrorField("error", err),
 		)
+		revalidate(ctx, &s.infoFlight, cacheKey, fetch)
+		return s.signLocalInfo(cached), nil
+	}
+	
+	// 相同曲目的并发请求只获取一次，其余请求等待并共享结果
+	info, _, err := s.infoFlight.Do(ctx, cacheKey, fetch)
+	if err != nil {
+		return nil, err
 	}
 	
 	s.logger.Info("获取音乐信息成功",
@@ -434,7 +746,70 @@ func (s *DefaultMusicService) GetMusicInfo(ctx context.Context, sourceName, id s
 		logger.String("name", info.Name),
 	)
 	
-	return info, nil
+	return s.signLocalInfo(info), nil
+}
+
+// signLocalInfo 为本地曲库的封面链接签名，链接变化时返回副本以免修改缓存或共享的结果
+func (s *DefaultMusicService) signLocalInfo(info *model.MusicInfo) *model.MusicInfo {
+	if info == nil {
+		return nil
+	}
+	picURL := s.streamLinker.LocalLink(info.PicURL)
+	if picURL == info.PicURL {
+		return info
+	}
+	copied := *info
+	copied.PicURL = picURL
+	return &copied
+}
+
+// signLocalOther 为本地曲库的播放链接与封面链接签名，返回副本以免修改共享的结果
+func (s *DefaultMusicService) signLocalOther(response *model.OtherGetResponse) *model.OtherGetResponse {
+	signed := *response
+	signed.URL = s.streamLinker.LocalLink(response.URL)
+	signed.Info = s.signLocalInfo(response.Info)
+	return &signed
+}
+
+// resolvePlatform 解析并校验音乐平台参数
+func (s *DefaultMusicService) resolvePlatform(platform string) (string, error) {
+	if s.configManager != nil {
+		return s.configManager.ResolvePlatform(platform)
+	}
+
+	// 降级到内置平台列表
+	platform = model.NormalizePlatform(platform)
+	if platform == "" {
+		return model.DefaultPlatform, nil
+	}
+	if !model.IsSupportedPlatform(platform) {
+		return "", fmt.Errorf("不支持的平台参数: %s", platform)
+	}
+	return platform, nil
+}
+
+// withMatchStrategy 校验请求指定的匹配策略并写入上下文
+func (s *DefaultMusicService) withMatchStrategy(ctx context.Context, strategy string) (context.Context, error) {
+	strategy = model.NormalizeMatchStrategy(strategy)
+	if strategy == "" {
+		return ctx, nil
+	}
+	if !model.IsValidMatchStrategy(strategy) {
+		return ctx, fmt.Errorf("不支持的匹配策略参数: %s，支持的策略: %v", strategy, model.SupportedMatchStrategies)
+	}
+	return model.WithMatchStrategy(ctx, strategy), nil
+}
+
+// resolveQualityPolicy 解析音质协商策略，未指定时使用默认策略
+func (s *DefaultMusicService) resolveQualityPolicy(policy string) (string, error) {
+	policy = model.NormalizeQualityPolicy(policy)
+	if policy == "" {
+		return model.DefaultQualityPolicy, nil
+	}
+	if !model.IsValidQualityPolicy(policy) {
+		return "", fmt.Errorf("不支持的音质协商策略参数: %s，支持的策略: %v", policy, model.SupportedQualityPolicies)
+	}
+	return policy, nil
 }
 
 // checkRateLimit 检查限流
@@ -455,155 +830,241 @@ func (s *DefaultMusicService) checkRateLimit(ctx context.Context, key string) er
 	return nil
 }
 
-// getFromCache 从缓存获取数据
-func (s *DefaultMusicService) getFromCache(ctx context.Context, key string) (interface{}, error) {
-	if s.cache == nil {
-		return nil, fmt.Errorf("缓存不可用")
+// GetPicture 获取专辑图，sourceName为空时在所有支持专辑图的音源间回退
+func (s *DefaultMusicService) GetPicture(ctx context.Context, sourceName, platform, picID, size string) (*model.PictureResult, error) {
+	if picID == "" {
+		return nil, fmt.Errorf("专辑图ID不能为空")
 	}
 
-	// 从缓存获取序列化数据
-	value, err := s.cache.Get(ctx, key)
+	// 解析音乐平台
+	platform, err := s.resolvePlatform(platform)
 	if err != nil {
 		return nil, err
 	}
+	ctx = model.WithPlatform(ctx, platform)
 
-	if value == "" {
-		return nil, fmt.Errorf("缓存数据为空")
-	}
-
-	// 反序列化缓存数据
-	var result interface{}
-	valueBytes, ok := value.([]byte)
-	if !ok {
-		if valueStr, ok := value.(string); ok {
-			valueBytes = []byte(valueStr)
-		} else {
-			return nil, fmt.Errorf("缓存值类型错误")
-		}
+	// 指定的音源优先
+	sourceNames, err := s.preferredSources(sourceName)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal(valueBytes, &result); err != nil {
-		s.logger.Error("缓存数据反序列化失败",
-			logger.String("key", key),
-			logger.String("value", string(valueBytes)),
-			logger.ErrorField("error", err),
-		)
-		return nil, fmt.Errorf("缓存数据反序列化失败: %w", err)
+	picture, err := s.sourceManager.GetPicture(ctx, picID, size, sourceNames)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	// 填充封面代理链接，固定提供专辑图的音源以免代理时重新回退
+	picture.URL = s.streamLinker.LocalLink(picture.URL)
+	proxySize, _ := strconv.Atoi(size)
+	picture.ProxyURL = s.streamLinker.CoverLink(&model.CoverRequest{
+		ID:       picID,
+		Source:   picture.Source,
+		Platform: platform,
+		Size:     proxySize,
+	})
+	return picture, nil
 }
 
-// getSearchResultsFromCache 从缓存获取搜索结果
-func (s *DefaultMusicService) getSearchResultsFromCache(ctx context.Context, key string) ([]*model.SearchResult, error) {
-	if s.cache == nil {
-		return nil, fmt.Errorf("缓存不可用")
+// GetLyric 获取歌词，sourceName为空时在所有支持歌词的音源间回退
+func (s *DefaultMusicService) GetLyric(ctx context.Context, sourceName, platform, lyricID string) (*model.LyricResult, error) {
+	if lyricID == "" {
+		return nil, fmt.Errorf("歌词ID不能为空")
 	}
 
-	// 从缓存获取序列化数据
-	value, err := s.cache.Get(ctx, key)
+	// 解析音乐平台
+	platform, err := s.resolvePlatform(platform)
 	if err != nil {
 		return nil, err
 	}
+	ctx = model.WithPlatform(ctx, platform)
 
-	if value == "" {
-		return nil, fmt.Errorf("缓存数据为空")
+	// 指定的音源优先
+	sourceNames, err := s.preferredSources(sourceName)
+	if err != nil {
+		return nil, err
 	}
 
-	// 反序列化缓存数据
-	var results []*model.SearchResult
-	valueBytes, ok := value.([]byte)
-	if !ok {
-		if valueStr, ok := value.(string); ok {
-			valueBytes = []byte(valueStr)
-		} else {
-			return nil, fmt.Errorf("缓存值类型错误")
+	cacheKey := fmt.Sprintf("%s:%s:%s", sourceName, platform, lyricID)
+	fetch := func(ctx context.Context) (*model.LyricResult, error) {
+		lyric, err := s.sourceManager.GetLyric(ctx, lyricID, sourceNames)
+		if err != nil {
+			if cacheErr := s.lyricCache.SetNotFound(ctx, cacheKey, err); cacheErr != nil {
+				s.logger.Warn("缓存未找到结果失败",
+					logger.String("lyric_id", lyricID),
+					logger.ErrorField("error", cacheErr),
+				)
+			}
+			return nil, err
 		}
+		if err := s.lyricCache.Set(ctx, cacheKey, lyric); err != nil {
+			s.logger.Warn("缓存歌词失败",
+				logger.String("lyric_id", lyricID),
+				logger.ErrorField("error", err),
+			)
+		}
+		return lyric, nil
 	}
 
-	if err := json.Unmarshal(valueBytes, &results); err != nil {
-		s.logger.Error("搜索结果缓存数据反序列化失败",
-			logger.String("key", key),
-			logger.String("value", string(valueBytes)),
-			logger.ErrorField("error", err),
-		)
-		return nil, fmt.Errorf("搜索结果缓存数据反序列化失败: %w", err)
+	// 过期的旧值先返回并在后台刷新
+	cached, status, err := s.lyricCache.Get(ctx, cacheKey)
+	model.RecordCacheStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case model.CacheStatusHit:
+		return cached, nil
+	case model.CacheStatusStale:
+		revalidate(ctx, &s.lyricFlight, cacheKey, fetch)
+		return cached, nil
 	}
 
-	return results, nil
+	// 相同歌词的并发请求只获取一次，其余请求等待并共享结果
+	lyric, _, err := s.lyricFlight.Do(ctx, cacheKey, fetch)
+	return lyric, err
 }
 
-// setToCache 设置缓存数据
-func (s *DefaultMusicService) setToCache(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	if s.cache == nil {
-		return nil
-	}
-	
-	// 序列化数据为JSON
-	data, err := json.Marshal(value)
+// GetLyricTimeline 获取解析后的时间轴歌词
+func (s *DefaultMusicService) GetLyricTimeline(ctx context.Context, sourceName, platform, lyricID string, offset time.Duration) (*model.LyricTimeline, error) {
+	parsed, lyric, err := s.parseLyric(ctx, sourceName, platform, lyricID, offset)
 	if err != nil {
-		s.logger.Error("缓存数据序列化失败",
-			logger.String("key", key),
-			logger.ErrorField("error", err),
-		)
-		return err
+		return nil, err
 	}
 
-	return s.cache.Set(ctx, key, string(data), ttl)
+	timeline := &model.LyricTimeline{
+		Timeline: make([]model.LyricLine, 0, len(parsed.Lines)),
+		Source:   lyric.Source,
+	}
+	if len(parsed.Metadata) > 0 {
+		timeline.Metadata = parsed.Metadata
+	}
+	for _, line := range parsed.Lines {
+		timeline.Timeline = append(timeline.Timeline, model.LyricLine{
+			Time:        line.Time.Milliseconds(),
+			Content:     line.Text,
+			Translation: line.Translation,
+			Words:       lyricWords(line.Words),
+		})
+	}
+	return timeline, nil
 }
 
-// GetPicture 获取专辑图
-func (s *DefaultMusicService) GetPicture(ctx context.Context, sourceName, picID, size string) (string, error) {
-	if sourceName == "" {
-		return "", fmt.Errorf("音源名称不能为空")
+// GetKaraokeLyric 获取卡拉OK模式歌词，原歌词没有逐字时间时每行整体作为一个词
+func (s *DefaultMusicService) GetKaraokeLyric(ctx context.Context, sourceName, platform, lyricID string, offset time.Duration) (*model.KaraokeLyric, error) {
+	parsed, lyric, err := s.parseLyric(ctx, sourceName, platform, lyricID, offset)
+	if err != nil {
+		return nil, err
 	}
 
-	if picID == "" {
-		return "", fmt.Errorf("专辑图ID不能为空")
-	}
+	lines := lyrics.Karaoke(parsed)
+	karaoke := &model.KaraokeLyric{
+		Lines:     make([]model.KaraokeLine, 0, len(lines)),
+		WordTimed: parsed.HasWordTimings(),
+		Source:    lyric.Source,
+	}
+	for _, line := range lines {
+		karaoke.Lines = append(karaoke.Lines, model.KaraokeLine{
+			Start:       line.Start.Milliseconds(),
+			End:         line.End.Milliseconds(),
+			Content:     line.Text,
+			Translation: line.Translation,
+			Words:       lyricWords(line.Words),
+		})
+	}
+	return karaoke, nil
+}
 
-	// 获取指定音源
-	source, err := s.sourceManager.GetSource(sourceName)
+// ExportLyric 将歌词导出为字幕，字幕结束时间取下一行歌词的开始时间
+func (s *DefaultMusicService) ExportLyric(ctx context.Context, sourceName, platform, lyricID string, opts *model.LyricExportOptions) (*model.LyricExport, error) {
+	parsed, lyric, err := s.parseLyric(ctx, sourceName, platform, lyricID, opts.Offset)
 	if err != nil {
-		return "", fmt.Errorf("获取音源失败: %w", err)
+		return nil, err
 	}
 
-	// 检查音源是否支持获取专辑图
-	if gdSource, ok := source.(*sources.GDStudioSource); ok {
-		return gdSource.GetPicture(ctx, picID, size)
+	content, err := lyrics.Encode(lyrics.Cues(parsed, lyrics.TranslationMode(opts.Translation)), opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("参数错误: %w", err)
 	}
+	return &model.LyricExport{
+		Content:     content,
+		ContentType: lyrics.ContentType(opts.Format),
+		Source:      lyric.Source,
+	}, nil
+}
 
-	return "", fmt.Errorf("音源 %s 不支持获取专辑图", sourceName)
+// lyricWords 转换逐字时间，时间单位为毫秒
+func lyricWords(words []lyrics.Word) []model.LyricWord {
+	if len(words) == 0 {
+		return nil
+	}
+	result := make([]model.LyricWord, 0, len(words))
+	for _, w := range words {
+		result = append(result, model.LyricWord{
+			Time:     w.Time.Milliseconds(),
+			Duration: (w.End - w.Time).Milliseconds(),
+			Text:     w.Text,
+		})
+	}
+	return result
 }
 
-// GetLyric 获取歌词
-func (s *DefaultMusicService) GetLyric(ctx context.Context, sourceName, lyricID string) (string, string, error) {
-	if sourceName == "" {
-		return "", "", fmt.Errorf("音源名称不能为空")
+// parseLyric 获取并解析歌词，合并翻译后整体平移offset
+func (s *DefaultMusicService) parseLyric(ctx context.Context, sourceName, platform, lyricID string, offset time.Duration) (*lyrics.Lyrics, *model.LyricResult, error) {
+	lyric, err := s.GetLyric(ctx, sourceName, platform, lyricID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if lyricID == "" {
-		return "", "", fmt.Errorf("歌词ID不能为空")
+	parsed := lyrics.Merge(lyrics.Parse(lyric.Lyric), lyrics.Parse(lyric.TLyric), lyrics.DefaultTolerance)
+	parsed.Shift(offset)
+	return parsed, lyric, nil
+}
+
+// GetLocalTrack 获取本地曲库曲目文件
+func (s *DefaultMusicService) GetLocalTrack(ctx context.Context, id string) (*model.LocalTrackFile, error) {
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
 	}
 
-	// 获取指定音源
-	source, err := s.sourceManager.GetSource(sourceName)
+	library, err := s.localLibrary()
 	if err != nil {
-		return "", "", fmt.Errorf("获取音源失败: %w", err)
+		return nil, err
+	}
+	return library.TrackFile(id)
+}
+
+// GetLocalCover 获取本地曲库曲目内嵌封面
+func (s *DefaultMusicService) GetLocalCover(ctx context.Context, id string) (*model.CoverImage, error) {
+	if id == "" {
+		return nil, fmt.Errorf("音乐ID不能为空")
 	}
 
-	// 检查音源是否支持获取歌词
-	if gdSource, ok := source.(*sources.GDStudioSource); ok {
-		return gdSource.GetLyric(ctx, lyricID)
+	library, err := s.localLibrary()
+	if err != nil {
+		return nil, err
 	}
+	return library.TrackCover(id)
+}
 
-	return "", "", fmt.Errorf("音源 %s 不支持获取歌词", sourceName)
+// localLibrary 查找已启用的本地曲库音源
+func (s *DefaultMusicService) localLibrary() (repository.LocalLibrary, error) {
+	for _, source := range s.sourceManager.GetEnabledSources() {
+		if library, ok := repository.UnwrapSource(source).(repository.LocalLibrary); ok {
+			return library, nil
+		}
+	}
+	return nil, fmt.Errorf("没有支持本地曲库的音源")
 }
 
-// clearFromCache 清除缓存数据
-func (s *DefaultMusicService) clearFromCache(ctx context.Context, key string) error {
-	if s.cache == nil {
-		return nil
+// preferredSources 校验请求指定的音源，返回优先尝试的音源列表
+func (s *DefaultMusicService) preferredSources(sourceName string) ([]string, error) {
+	if sourceName == "" {
+		return nil, nil
 	}
-	return s.cache.Delete(ctx, key)
+	if _, err := s.sourceManager.GetSource(sourceName); err != nil {
+		return nil, fmt.Errorf("无效的音源参数: %s", sourceName)
+	}
+	return []string{sourceName}, nil
 }
+
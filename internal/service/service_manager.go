@@ -4,12 +4,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/health"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/plugin"
 	"github.com/IIXINGCHEN/music-api-proxy/internal/repository"
 	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
 )
@@ -85,9 +87,13 @@ func (a *repositoryLoggerAdapter) convertFields(fields ...interface{}) []logger.
 // ServiceManager 服务管理器
 type ServiceManager struct {
 	// 服务实例
-	MusicService  MusicService
-	SystemService SystemService
-	ConfigService ConfigService
+	MusicService    MusicService
+	StreamService   StreamService
+	DownloadService DownloadService
+	CoverService    CoverService
+	StreamLinker    *StreamLinker
+	SystemService   SystemService
+	ConfigService   ConfigService
 	
 	// 仓库实例
 	Repository *repository.Repository
@@ -95,6 +101,9 @@ type ServiceManager struct {
 	// 应用配置仓库
 	AppConfigRepo repository.AppConfigRepository
 
+	// 音频磁盘缓存，未启用时为nil
+	audioCache *repository.AudioCache
+
 	// 配置和日志
 	Config *config.Config
 	Logger logger.Logger
@@ -162,8 +171,35 @@ func (sm *ServiceManager) initializeRepositories(ctx context.Context) error {
 	// 创建HTTP客户端
 	httpClient := repository.NewDefaultHTTPClient(httpConfig, sm.Logger)
 	
-	// 创建缓存仓库
-	cache := repository.NewMemoryCacheRepository(sm.Logger)
+	// 创建缓存仓库，Redis缓存由多个副本共享，不可用时回退到内存缓存；磁盘缓存在重启后保留
+	var cacheBytes int64
+	if sm.Config.Cache.MaxSize != "" {
+		size, err := config.ParseByteSize(sm.Config.Cache.MaxSize)
+		if err != nil {
+			return fmt.Errorf("解析缓存容量失败: %w", err)
+		}
+		cacheBytes = size
+	}
+	var cache repository.CacheRepository
+	switch sm.Config.Cache.Type {
+	case "redis":
+		fallback := repository.NewMemoryCacheRepository(cacheBytes, sm.Config.Cache.CleanupInterval, sm.Logger)
+		cache = repository.NewRedisCacheRepository(&sm.Config.Cache.Redis, fallback, sm.Logger)
+	case "disk":
+		diskCache, err := repository.NewDiskCacheRepository(&sm.Config.Cache.Disk, sm.Logger)
+		if err != nil {
+			return fmt.Errorf("初始化磁盘缓存失败: %w", err)
+		}
+		cache = diskCache
+	case "tiered":
+		tieredCache, err := repository.NewTieredCacheRepository(&sm.Config.Cache.Disk, sm.Logger)
+		if err != nil {
+			return fmt.Errorf("初始化两级缓存失败: %w", err)
+		}
+		cache = tieredCache
+	default:
+		cache = repository.NewMemoryCacheRepository(cacheBytes, sm.Config.Cache.CleanupInterval, sm.Logger)
+	}
 	
 	// 创建限流器
 	rateLimiter := repository.NewMemoryRateLimiter(100, 10, sm.Logger)
@@ -202,8 +238,76 @@ func (sm *ServiceManager) initializeRepositories(ctx context.Context) error {
 		TestSources:    sm.Config.Sources.TestSources,
 		Timeout:        sm.Config.Sources.Timeout,
 		RetryCount:     sm.Config.Sources.RetryCount,
+
+		// 音乐平台配置
+		Platforms:       sm.Config.Sources.Platforms,
+		DefaultPlatform: sm.Config.Sources.DefaultPlatform,
+
+		// 匹配策略配置
+		MatchStrategy: sm.Config.Sources.MatchStrategy,
+		HedgeDelay:    sm.Config.Sources.HedgeDelay,
+
+		// 音源熔断器配置
+		CircuitBreaker: model.CircuitBreakerConfigModel{
+			Enabled:              sm.Config.Sources.CircuitBreaker.Enabled,
+			FailureThreshold:     sm.Config.Sources.CircuitBreaker.FailureThreshold,
+			FailureRateThreshold: sm.Config.Sources.CircuitBreaker.FailureRateThreshold,
+			WindowSize:           sm.Config.Sources.CircuitBreaker.WindowSize,
+			MinRequests:          sm.Config.Sources.CircuitBreaker.MinRequests,
+			CoolDown:             sm.Config.Sources.CircuitBreaker.CoolDown,
+			HalfOpenProbes:       sm.Config.Sources.CircuitBreaker.HalfOpenProbes,
+		},
+	}
+
+	// 本地曲库音源配置
+	sourcesConfig.Local = model.LocalSourceConfigModel{
+		Enabled:      sm.Config.Sources.Local.Enabled,
+		Directory:    sm.Config.Sources.Local.Directory,
+		PublicURL:    sm.Config.Sources.Local.PublicURL,
+		Extensions:   sm.Config.Sources.Local.Extensions,
+		Priority:     sm.Config.Sources.Local.Priority,
+		Watch:        sm.Config.Sources.Local.Watch,
+		ReindexDelay: sm.Config.Sources.Local.ReindexDelay,
+	}
+
+	// 声明式HTTP音源配置
+	for _, source := range sm.Config.Sources.HTTPSources {
+		operations := make(map[string]model.HTTPSourceOperationModel, len(source.Operations))
+		for name, op := range source.Operations {
+			operations[name] = model.HTTPSourceOperationModel{
+				Method:  op.Method,
+				URL:     op.URL,
+				Headers: op.Headers,
+				Body:    op.Body,
+				Results: op.Results,
+				Fields:  op.Fields,
+				Success: model.HTTPSourceSuccessModel{
+					Status: op.Success.Status,
+					Path:   op.Success.Path,
+					Equals: op.Success.Equals,
+				},
+			}
+		}
+		sourcesConfig.HTTPSources = append(sourcesConfig.HTTPSources, model.HTTPSourceConfigModel{
+			Name:        source.Name,
+			Enabled:     source.Enabled,
+			Priority:    source.Priority,
+			BaseURL:     source.BaseURL,
+			APIKey:      source.APIKey,
+			UserAgent:   source.UserAgent,
+			Timeout:     source.Timeout,
+			Headers:     source.Headers,
+			Qualities:   source.Qualities,
+			FixEncoding: source.FixEncoding,
+			Operations:  operations,
+		})
 	}
 	sourceManager := repository.NewDefaultSourceManager(httpClient, sourcesConfig, cache, sm.Logger)
+
+	// 加载插件注册表中启用的音源插件
+	if loaded := sourceManager.LoadPluginSources(context.Background(), plugin.GlobalRegistry, sm.Config.Plugins.Sources); loaded > 0 {
+		sm.Logger.Info("音源插件加载完成", logger.Int("count", loaded))
+	}
 	
 	// 创建配置仓库
 	configRepo := repository.NewMemoryConfigRepository(sm.Logger)
@@ -243,19 +347,65 @@ func (sm *ServiceManager) initializeServices(ctx context.Context) error {
 	// 创建配置管理器
 	configManager := config.NewSourceConfigManager(sm.Config)
 
+	// 创建代理链接生成器
+	streamLinker, err := NewStreamLinker(&sm.Config.Stream)
+	if err != nil {
+		return fmt.Errorf("初始化流式代理失败: %w", err)
+	}
+	sm.StreamLinker = streamLinker
+
 	// 创建音乐服务
 	sm.MusicService = NewDefaultMusicService(
 		sm.Repository.SourceManager,
 		sm.Repository.Cache,
+		sm.Repository.CacheMetrics,
+		&sm.Config.Cache.Policies,
 		sm.Repository.RateLimiter,
 		configManager,
+		sm.StreamLinker,
 		sm.Logger,
 	)
+
+	// 创建流式代理服务
+	if sm.Config.Stream.Enabled {
+		if sm.Config.Stream.Cache.Enabled {
+			maxBytes, err := config.ParseByteSize(sm.Config.Stream.Cache.MaxSize)
+			if err != nil {
+				return fmt.Errorf("解析音频缓存容量失败: %w", err)
+			}
+			sm.audioCache, err = repository.NewAudioCache(sm.Config.Stream.Cache.Directory, maxBytes, sm.Config.Stream.Cache.FillTimeout, sm.Logger)
+			if err != nil {
+				return fmt.Errorf("初始化音频缓存失败: %w", err)
+			}
+		}
+		sm.StreamService = NewDefaultStreamService(sm.MusicService, sm.audioCache, &sm.Config.Stream, sm.Logger)
+
+		if sm.Config.Stream.Download.Enabled {
+			sm.DownloadService, err = NewDefaultDownloadService(sm.StreamService, sm.MusicService, &sm.Config.Stream, sm.Logger)
+			if err != nil {
+				return fmt.Errorf("初始化下载服务失败: %w", err)
+			}
+		}
+	}
+
+	// 创建封面代理服务
+	if sm.Config.Stream.Cover.Enabled {
+		maxBytes, err := config.ParseByteSize(sm.Config.Stream.Cover.MaxSize)
+		if err != nil {
+			return fmt.Errorf("解析封面缓存容量失败: %w", err)
+		}
+		imageCache, err := repository.NewImageCache(sm.Config.Stream.Cover.Directory, maxBytes, sm.Logger)
+		if err != nil {
+			return fmt.Errorf("初始化封面缓存失败: %w", err)
+		}
+		sm.CoverService = NewDefaultCoverService(sm.MusicService, imageCache, &sm.Config.Stream, sm.Logger)
+	}
 	
 	// 创建系统服务
 	sm.SystemService = NewDefaultSystemService(
 		sm.Repository.SourceManager,
 		sm.Repository.Cache,
+		sm.Repository.CacheMetrics,
 		healthChecker,
 		metricsCollector,
 		sm.Config,
@@ -298,9 +448,34 @@ func (sm *ServiceManager) Shutdown(ctx context.Context) error {
 	
 	sm.Logger.Info("开始关闭服务管理器")
 	
-	// 清理资源
+	// 关闭持有后台资源的音源（如本地曲库的目录监听）
+	if sm.Repository != nil && sm.Repository.SourceManager != nil {
+		for _, source := range sm.Repository.SourceManager.GetAllSources() {
+			if closer, ok := repository.UnwrapSource(source).(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					sm.Logger.Warn("关闭音源失败",
+						logger.String("source", source.GetName()),
+						logger.ErrorField("error", err),
+					)
+				}
+			}
+		}
+	}
+
+	// 停止音频缓存的后台下载，已下载部分保留在磁盘
+	if sm.audioCache != nil {
+		if err := sm.audioCache.Close(); err != nil {
+			sm.Logger.Warn("关闭音频缓存失败", logger.ErrorField("error", err))
+		}
+	}
+
+	// 清理资源，共享缓存只关闭连接，不清空其他副本仍在使用的数据
 	if sm.Repository != nil && sm.Repository.Cache != nil {
-		if err := sm.Repository.Cache.Clear(ctx); err != nil {
+		if closer, ok := sm.Repository.Cache.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				sm.Logger.Warn("关闭缓存失败", logger.ErrorField("error", err))
+			}
+		} else if err := sm.Repository.Cache.Clear(ctx); err != nil {
 			sm.Logger.Warn("清理缓存失败", logger.ErrorField("error", err))
 		}
 	}
@@ -325,6 +500,34 @@ func (sm *ServiceManager) GetMusicService() MusicService {
 	return sm.MusicService
 }
 
+// GetStreamLinker 获取代理链接生成器，流式代理、封面代理与链接签名均未启用时返回nil
+func (sm *ServiceManager) GetStreamLinker() *StreamLinker {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.StreamLinker
+}
+
+// GetDownloadService 获取带标签下载服务，未启用时返回nil
+func (sm *ServiceManager) GetDownloadService() DownloadService {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.DownloadService
+}
+
+// GetCoverService 获取封面代理服务，未启用时返回nil
+func (sm *ServiceManager) GetCoverService() CoverService {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.CoverService
+}
+
+// GetStreamService 获取流式代理服务，未启用时返回nil
+func (sm *ServiceManager) GetStreamService() StreamService {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.StreamService
+}
+
 // GetSystemService 获取系统服务
 func (sm *ServiceManager) GetSystemService() SystemService {
 	sm.mu.RLock()
@@ -0,0 +1,679 @@
+// Package service 音频流式代理服务
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/model"
+	"github.com/IIXINGCHEN/music-api-proxy/internal/repository"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/urlsign"
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/useragent"
+)
+
+// 代理接口路径，同时作为链接签名的作用域
+const (
+	StreamPath   = "/api/v1/stream"   // 流式代理接口路径
+	CoverPath    = "/api/v1/cover"    // 封面代理接口路径
+	DownloadPath = "/api/v1/download" // 带标签下载接口路径
+	LocalPath    = "/api/v1/local"    // 本地曲库文件与封面接口路径前缀，签名作用域为完整的资源路径
+)
+
+// ErrUpstreamStream 上游音频请求失败
+var ErrUpstreamStream = errors.New("上游音频请求失败")
+
+// ErrAudioCacheDisabled 音频缓存未启用
+var ErrAudioCacheDisabled = errors.New("音频缓存不可用")
+
+// streamSeekWindow 请求位置超出缓存已下载部分该距离时直接转发上游，避免等待顺序下载
+const streamSeekWindow = 2 << 20
+
+// errCacheBypass 本次请求不经过缓存
+var errCacheBypass = errors.New("跳过音频缓存")
+
+// 转发给客户端的上游响应头
+var streamResponseHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Content-Range",
+	"Accept-Ranges",
+	"ETag",
+	"Last-Modified",
+}
+
+// 按文件格式推断的音频Content-Type
+var streamContentTypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"flac": "audio/flac",
+	"m4a":  "audio/mp4",
+	"mp4":  "audio/mp4",
+	"aac":  "audio/aac",
+	"ogg":  "audio/ogg",
+	"oga":  "audio/ogg",
+	"opus": "audio/ogg",
+	"wav":  "audio/wav",
+}
+
+// StreamLinker 生成并校验指向流式代理与封面代理的链接
+type StreamLinker struct {
+	publicURL string
+	streams   bool            // 是否生成音频代理链接
+	covers    bool            // 是否生成封面代理链接
+	downloads bool            // 是否生成下载链接
+	signer    *urlsign.Signer // 未启用链接签名时为nil
+}
+
+// NewStreamLinker 创建代理链接生成器，流式代理、封面代理与链接签名均未启用时返回nil
+func NewStreamLinker(cfg *config.StreamConfig) (*StreamLinker, error) {
+	if cfg == nil || (!cfg.Enabled && !cfg.Cover.Enabled && !cfg.Signing.Enabled) {
+		return nil, nil
+	}
+
+	linker := &StreamLinker{
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		streams:   cfg.Enabled,
+		covers:    cfg.Cover.Enabled,
+		downloads: cfg.Enabled && cfg.Download.Enabled,
+	}
+	if cfg.Signing.Enabled {
+		keys := make([]urlsign.Key, 0, len(cfg.Signing.Keys))
+		for _, key := range cfg.Signing.Keys {
+			keys = append(keys, urlsign.Key{ID: key.ID, Secret: key.Secret})
+		}
+		signer, err := urlsign.NewSigner(keys, cfg.Signing.ActiveKey, cfg.Signing.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("创建链接签名器失败: %w", err)
+		}
+		linker.signer = signer
+	}
+	return linker, nil
+}
+
+// Verify 校验代理请求的链接签名，未启用链接签名时总是通过
+func (l *StreamLinker) Verify(scope string, query url.Values) error {
+	if l == nil || l.signer == nil {
+		return nil
+	}
+	return l.signer.Verify(scope, query)
+}
+
+// Link 生成音频代理链接，启用签名时附带过期时间与签名，未启用流式代理时返回空字符串
+func (l *StreamLinker) Link(req *model.StreamRequest) string {
+	if l == nil || !l.streams {
+		return ""
+	}
+	return l.trackLink(StreamPath, req)
+}
+
+// DownloadLink 生成带标签下载链接，未启用下载接口时返回空字符串
+func (l *StreamLinker) DownloadLink(req *model.StreamRequest) string {
+	if l == nil || !l.downloads {
+		return ""
+	}
+	return l.trackLink(DownloadPath, req)
+}
+
+// trackLink 生成指向曲目接口的链接，接口路径同时作为签名作用域
+func (l *StreamLinker) trackLink(path string, req *model.StreamRequest) string {
+	if req == nil || req.ID == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("id", req.ID)
+	if req.Platform != "" {
+		query.Set("platform", req.Platform)
+	}
+	if req.Server != "" {
+		query.Set("server", req.Server)
+	}
+	if req.BR != "" {
+		query.Set("br", req.BR)
+	}
+	if req.QualityPolicy != "" {
+		query.Set("quality_policy", req.QualityPolicy)
+	}
+	if l.signer != nil {
+		query = l.signer.Sign(path, query)
+	}
+	return l.publicURL + path + "?" + query.Encode()
+}
+
+// CoverLink 生成封面代理链接，未启用封面代理时返回空字符串
+func (l *StreamLinker) CoverLink(req *model.CoverRequest) string {
+	if l == nil || !l.covers || req == nil || req.ID == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("id", req.ID)
+	if req.Platform != "" {
+		query.Set("platform", req.Platform)
+	}
+	if req.Source != "" {
+		query.Set("source", req.Source)
+	}
+	if req.Size > 0 {
+		query.Set("size", strconv.Itoa(req.Size))
+	}
+	if req.Format != "" {
+		query.Set("format", req.Format)
+	}
+	if req.Quality > 0 {
+		query.Set("quality", strconv.Itoa(req.Quality))
+	}
+	if l.signer != nil {
+		query = l.signer.Sign(CoverPath, query)
+	}
+	return l.publicURL + CoverPath + "?" + query.Encode()
+}
+
+// LocalLink 为本地曲库的文件或封面链接签名，作用域为 /api/v1/local/<id>/<资源>
+// 未启用链接签名或不是本地曲库链接时原样返回
+func (l *StreamLinker) LocalLink(rawURL string) string {
+	if l == nil || l.signer == nil || rawURL == "" {
+		return rawURL
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	scope, ok := localScope(target)
+	if !ok {
+		return rawURL
+	}
+	target.RawQuery = l.signer.Sign(scope, target.Query()).Encode()
+	return target.String()
+}
+
+// localScope 获取本地曲库链接的签名作用域，对外地址带路径前缀时忽略前缀
+func localScope(target *url.URL) (string, bool) {
+	index := strings.Index(target.Path, LocalPath+"/")
+	if index < 0 {
+		return "", false
+	}
+	return target.Path[index:], true
+}
+
+// isLocalLink 检查链接是否指向本地曲库接口或为站内相对路径
+func isLocalLink(target *url.URL) bool {
+	_, ok := localScope(target)
+	return ok || !target.IsAbs()
+}
+
+// StreamResult 打开的上游音频流
+type StreamResult struct {
+	StatusCode int                   // 上游状态码（200、206、304或416）
+	Header     http.Header           // 转发给客户端的响应头
+	Body       io.ReadCloser         // 上游响应体，调用方负责关闭
+	File       *model.LocalTrackFile // 本地曲库曲目文件，不为nil时由调用方直接输出文件
+	Source     string                // 提供链接的音源
+	Quality    string                // 实际音质
+}
+
+// StreamService 流式代理服务接口
+type StreamService interface {
+	// Open 解析曲目并打开上游音频流，method为GET或HEAD，header为客户端请求头
+	Open(ctx context.Context, method string, req *model.StreamRequest, header http.Header) (*StreamResult, error)
+
+	// CacheStats 获取音频缓存统计
+	CacheStats(ctx context.Context) (*model.AudioCacheStats, error)
+
+	// CacheEntries 列出音频缓存条目
+	CacheEntries(ctx context.Context) ([]model.AudioCacheEntry, error)
+
+	// PurgeCacheEntry 清除指定的音频缓存条目
+	PurgeCacheEntry(ctx context.Context, id string) error
+
+	// PurgeCache 清除全部音频缓存，返回清除的条目数
+	PurgeCache(ctx context.Context) (int, error)
+}
+
+// DefaultStreamService 默认流式代理服务实现
+type DefaultStreamService struct {
+	musicService MusicService
+	cache        *repository.AudioCache // 未启用音频缓存时为nil
+	client       *http.Client
+	userAgent    string
+	logger       logger.Logger
+}
+
+// NewDefaultStreamService 创建默认流式代理服务，cache为nil时不缓存音频
+func NewDefaultStreamService(musicService MusicService, cache *repository.AudioCache, cfg *config.StreamConfig, log logger.Logger) *DefaultStreamService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = cfg.UpstreamTimeout
+	transport.DialContext = (&net.Dialer{Timeout: cfg.UpstreamTimeout, KeepAlive: 30 * time.Second}).DialContext
+	// 音频需原样转发，禁止透明解压以保证Content-Length与Range一致
+	transport.DisableCompression = true
+
+	maxRedirects := cfg.MaxRedirects
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("上游重定向次数超过 %d 次", maxRedirects)
+			}
+			return nil
+		},
+	}
+
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = useragent.Build()
+	}
+
+	return &DefaultStreamService{
+		musicService: musicService,
+		cache:        cache,
+		client:       client,
+		userAgent:    userAgent,
+		logger:       log,
+	}
+}
+
+// Open 解析曲目并打开上游音频流
+func (s *DefaultStreamService) Open(ctx context.Context, method string, req *model.StreamRequest, header http.Header) (*StreamResult, error) {
+	if req == nil || req.ID == "" {
+		return nil, fmt.Errorf("音乐ID参数不能为空")
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		method = http.MethodGet
+	}
+
+	match, err := s.musicService.MatchMusic(ctx, &model.MatchRequest{
+		ID:            req.ID,
+		Server:        req.Server,
+		Platform:      req.Platform,
+		BR:            req.BR,
+		QualityPolicy: req.QualityPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(match.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 音源返回的链接无效: %v", ErrUpstreamStream, err)
+	}
+	// 本地曲库曲目直接读取文件，不经过本地曲库接口，以免绕过代理链接的签名校验
+	if _, ok := localScope(target); ok {
+		file, err := s.musicService.GetLocalTrack(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResult{File: file, Source: match.Source, Quality: match.Quality}, nil
+	}
+	if !target.IsAbs() {
+		return nil, fmt.Errorf("%w: 音源返回的链接不是绝对地址: %s", ErrUpstreamStream, match.URL)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("%w: 不支持的链接协议: %s", ErrUpstreamStream, target.Scheme)
+	}
+
+	if s.cache != nil {
+		result, err := s.openCached(ctx, method, req, header, match, target)
+		if err == nil {
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, errCacheBypass) {
+			s.logger.Warn("音频缓存不可用，直接转发上游",
+				logger.String("id", req.ID),
+				logger.String("source", match.Source),
+				logger.ErrorField("error", err),
+			)
+		}
+	}
+
+	return s.openUpstream(ctx, method, req, header, match, target)
+}
+
+// openUpstream 直接转发上游响应
+func (s *DefaultStreamService) openUpstream(ctx context.Context, method string, req *model.StreamRequest, header http.Header, match *model.MatchResponse, target *url.URL) (*StreamResult, error) {
+	upstreamReq, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamStream, err)
+	}
+	upstreamReq.Header.Set("User-Agent", s.userAgent)
+	upstreamReq.Header.Set("Accept-Encoding", "identity")
+	for _, name := range []string{"Range", "If-Range", "If-None-Match", "If-Modified-Since"} {
+		if value := header.Get(name); value != "" {
+			upstreamReq.Header.Set(name, value)
+		}
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(upstreamReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamStream, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusNotModified, http.StatusRequestedRangeNotSatisfiable:
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: 上游返回状态码 %d", ErrUpstreamStream, resp.StatusCode)
+	}
+
+	result := &StreamResult{
+		StatusCode: resp.StatusCode,
+		Header:     make(http.Header),
+		Body:       resp.Body,
+		Source:     match.Source,
+		Quality:    match.Quality,
+	}
+	for _, name := range streamResponseHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			result.Header.Set(name, value)
+		}
+	}
+	if resp.StatusCode == http.StatusPartialContent && result.Header.Get("Accept-Ranges") == "" {
+		result.Header.Set("Accept-Ranges", "bytes")
+	}
+	if contentType := streamContentType(result.Header.Get("Content-Type"), match.Format, resp.Request.URL.Path); contentType != "" {
+		result.Header.Set("Content-Type", contentType)
+	}
+
+	s.logger.Info("打开上游音频流",
+		logger.String("id", req.ID),
+		logger.String("source", match.Source),
+		logger.String("quality", match.Quality),
+		logger.Int("status", resp.StatusCode),
+		logger.String("range", header.Get("Range")),
+		logger.String("duration", time.Since(start).String()),
+	)
+
+	return result, nil
+}
+
+// openCached 经磁盘缓存提供音频，缓存未命中时后台下载并边下边读
+// HEAD请求只使用已完整缓存的条目，不触发后台下载
+func (s *DefaultStreamService) openCached(ctx context.Context, method string, req *model.StreamRequest, header http.Header, match *model.MatchResponse, target *url.URL) (*StreamResult, error) {
+	key := strings.Join([]string{match.Platform, match.Source, match.ID, match.Quality}, ":")
+	hint := repository.AudioCacheHint{
+		ContentType: streamContentType("", match.Format, target.Path),
+		MD5:         match.MD5,
+		Source:      match.Source,
+		Quality:     match.Quality,
+	}
+
+	var object *repository.AudioObject
+	if method == http.MethodHead {
+		var ok bool
+		if object, ok = s.cache.OpenComplete(key); !ok {
+			return nil, errCacheBypass
+		}
+	} else {
+		var err error
+		if object, err = s.cache.Open(ctx, key, hint, s.cacheFetcher(target)); err != nil {
+			return nil, err
+		}
+	}
+
+	size := object.Size()
+	etag := audioETag(object)
+	result := &StreamResult{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Source:     match.Source,
+		Quality:    match.Quality,
+	}
+	result.Header.Set("Accept-Ranges", "bytes")
+	if etag != "" {
+		result.Header.Set("ETag", etag)
+	}
+	result.Header.Set("X-Audio-Cache", object.State())
+	if contentType := streamContentType(object.ContentType(), match.Format, target.Path); contentType != "" {
+		result.Header.Set("Content-Type", contentType)
+	}
+
+	if inm := header.Get("If-None-Match"); inm != "" && etag != "" && inm == etag {
+		object.Close()
+		result.StatusCode = http.StatusNotModified
+		return result, nil
+	}
+
+	start, end := int64(0), size-1
+	rangeHeader := header.Get("Range")
+	if ifRange := header.Get("If-Range"); ifRange != "" && (etag == "" || ifRange != etag) {
+		rangeHeader = ""
+	}
+	// 上游未返回大小时边下边读整个文件，无法确定范围的请求直接转发上游
+	if size < 0 {
+		if rangeHeader != "" {
+			object.Close()
+			return nil, errCacheBypass
+		}
+		return s.streamCached(ctx, req, match, object, result, rangeHeader, 0, -1)
+	}
+	if rangeHeader != "" {
+		var ok bool
+		start, end, ok = parseByteRange(rangeHeader, size)
+		if !ok {
+			object.Close()
+			result.StatusCode = http.StatusRequestedRangeNotSatisfiable
+			result.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return result, nil
+		}
+		result.StatusCode = http.StatusPartialContent
+		result.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+	length := end - start + 1
+	result.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	if method == http.MethodHead {
+		object.Close()
+		return result, nil
+	}
+
+	// 拖动到远未下载的位置时直接转发上游，缓存继续在后台下载
+	if object.State() != "hit" && start > object.Available()+streamSeekWindow {
+		object.Close()
+		return nil, errCacheBypass
+	}
+
+	return s.streamCached(ctx, req, match, object, result, rangeHeader, start, length)
+}
+
+// streamCached 以缓存读取器作为响应体，length为负数时读取到下载完成为止
+func (s *DefaultStreamService) streamCached(ctx context.Context, req *model.StreamRequest, match *model.MatchResponse, object *repository.AudioObject, result *StreamResult, rangeHeader string, start, length int64) (*StreamResult, error) {
+	body, err := object.NewReader(ctx, start, length)
+	if err != nil {
+		return nil, err
+	}
+	result.Body = body
+
+	s.logger.Info("由音频缓存提供音频流",
+		logger.String("id", req.ID),
+		logger.String("source", match.Source),
+		logger.String("cache", object.State()),
+		logger.Int("status", result.StatusCode),
+		logger.String("range", rangeHeader),
+	)
+	return result, nil
+}
+
+// cacheFetcher 生成从上游下载音频的函数，续传时携带Range请求头
+func (s *DefaultStreamService) cacheFetcher(target *url.URL) repository.AudioFetchFunc {
+	return func(ctx context.Context, offset int64) (io.ReadCloser, int64, int64, string, error) {
+		upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, 0, 0, "", fmt.Errorf("%w: %v", ErrUpstreamStream, err)
+		}
+		upstreamReq.Header.Set("User-Agent", s.userAgent)
+		upstreamReq.Header.Set("Accept-Encoding", "identity")
+		if offset > 0 {
+			upstreamReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+
+		resp, err := s.client.Do(upstreamReq)
+		if err != nil {
+			return nil, 0, 0, "", fmt.Errorf("%w: %v", ErrUpstreamStream, err)
+		}
+		contentType := resp.Header.Get("Content-Type")
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return resp.Body, 0, resp.ContentLength, contentType, nil
+		case http.StatusPartialContent:
+			start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			if !ok {
+				resp.Body.Close()
+				return nil, 0, 0, "", fmt.Errorf("%w: 上游返回的Content-Range无效", ErrUpstreamStream)
+			}
+			return resp.Body, start, total, contentType, nil
+		default:
+			resp.Body.Close()
+			return nil, 0, 0, "", fmt.Errorf("%w: 上游返回状态码 %d", ErrUpstreamStream, resp.StatusCode)
+		}
+	}
+}
+
+// CacheStats 获取音频缓存统计
+func (s *DefaultStreamService) CacheStats(ctx context.Context) (*model.AudioCacheStats, error) {
+	if s.cache == nil {
+		return nil, ErrAudioCacheDisabled
+	}
+	stats := s.cache.Stats()
+	return &stats, nil
+}
+
+// CacheEntries 列出音频缓存条目
+func (s *DefaultStreamService) CacheEntries(ctx context.Context) ([]model.AudioCacheEntry, error) {
+	if s.cache == nil {
+		return nil, ErrAudioCacheDisabled
+	}
+	return s.cache.Entries(), nil
+}
+
+// PurgeCacheEntry 清除指定的音频缓存条目
+func (s *DefaultStreamService) PurgeCacheEntry(ctx context.Context, id string) error {
+	if s.cache == nil {
+		return ErrAudioCacheDisabled
+	}
+	if !s.cache.Purge(id) {
+		return fmt.Errorf("未找到音频缓存条目: %s", id)
+	}
+	s.logger.Info("清除音频缓存条目", logger.String("id", id))
+	return nil
+}
+
+// PurgeCache 清除全部音频缓存
+func (s *DefaultStreamService) PurgeCache(ctx context.Context) (int, error) {
+	if s.cache == nil {
+		return 0, ErrAudioCacheDisabled
+	}
+	count := s.cache.PurgeAll()
+	s.logger.Info("清除全部音频缓存", logger.Int("count", count))
+	return count, nil
+}
+
+// audioETag 缓存音频的ETag，音源提供MD5时使用MD5，大小未知且无MD5时返回空字符串
+func audioETag(object *repository.AudioObject) string {
+	if checksum := object.MD5(); checksum != "" {
+		return `"` + checksum + `"`
+	}
+	if object.Size() < 0 {
+		return ""
+	}
+	return fmt.Sprintf(`"%s-%x"`, object.ID(), object.Size())
+}
+
+// parseByteRange 解析单段Range请求头，返回闭区间；多段或无法满足的范围返回false
+func parseByteRange(header string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") || size <= 0 {
+		return 0, 0, false
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		// bytes=-N 表示最后N个字节
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 {
+			return 0, 0, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size - 1, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, true
+}
+
+// parseContentRange 解析 "bytes start-end/total" 形式的Content-Range，total未知时为-1
+func parseContentRange(header string) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	span, totalText, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	first, _, ok := strings.Cut(span, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	total := int64(-1)
+	if totalText != "*" {
+		if total, err = strconv.ParseInt(totalText, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, total, true
+}
+
+// streamContentType 上游未返回音频类型时按格式或链接扩展名推断
+func streamContentType(upstream, format, urlPath string) string {
+	if mediaType, _, err := mime.ParseMediaType(upstream); err == nil && strings.HasPrefix(mediaType, "audio/") {
+		return upstream
+	}
+	if contentType, ok := streamContentTypes[strings.ToLower(format)]; ok {
+		return contentType
+	}
+	if contentType, ok := streamContentTypes[strings.TrimPrefix(strings.ToLower(path.Ext(urlPath)), ".")]; ok {
+		return contentType
+	}
+	return upstream
+}
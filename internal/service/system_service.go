@@ -3,7 +3,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/IIXINGCHEN/music-api-proxy/internal/config"
@@ -30,12 +34,30 @@ type SystemService interface {
 	// RefreshSources 刷新音源配置
 	RefreshSources(ctx context.Context) error
 
+	// OpenCircuitBreaker 强制打开音源熔断器
+	OpenCircuitBreaker(ctx context.Context, name string) (*model.CircuitBreakerStatus, error)
+
+	// ResetCircuitBreaker 重置音源熔断器
+	ResetCircuitBreaker(ctx context.Context, name string) (*model.CircuitBreakerStatus, error)
+
 	// ClearCache 清空缓存
 	ClearCache(ctx context.Context) error
 
 	// GetCacheStats 获取缓存统计
 	GetCacheStats(ctx context.Context) (map[string]interface{}, error)
 
+	// ListCacheKeys 分页列出选中的缓存键，未指定选择条件时列出所有键
+	ListCacheKeys(ctx context.Context, selector *model.CacheKeySelector, page, pageSize int) (*model.CacheKeyList, error)
+
+	// GetCacheEntry 获取缓存条目的值与剩余过期时间
+	GetCacheEntry(ctx context.Context, key string) (*model.CacheEntry, error)
+
+	// DeleteCacheKeys 删除选中的缓存键，返回删除的数量
+	DeleteCacheKeys(ctx context.Context, selector *model.CacheKeySelector) (int, error)
+
+	// ExpireCacheKeys 重新设置选中缓存键的剩余过期时间，返回每个键的处理结果
+	ExpireCacheKeys(ctx context.Context, selector *model.CacheKeySelector, ttl time.Duration) (*model.CacheExpireSummary, error)
+
 	// IsHealthy 检查系统是否健康
 	IsHealthy(ctx context.Context) bool
 
@@ -55,10 +77,20 @@ type SystemService interface {
 	RecordError(errorType string, statusCode int, errorMsg string)
 }
 
+// ErrCacheDisabled 缓存未启用时缓存管理接口返回的错误
+var ErrCacheDisabled = errors.New("缓存未启用")
+
+// 缓存键列表的分页大小
+const (
+	defaultCacheKeyPageSize = 50
+	maxCacheKeyPageSize     = 1000
+)
+
 // DefaultSystemService 默认系统服务实现
 type DefaultSystemService struct {
 	sourceManager   repository.SourceManager
 	cache           repository.CacheRepository
+	cacheMetrics    *repository.CacheMetrics
 	healthChecker   *health.Checker
 	metricsCollector *health.MetricsCollector
 	logger          logger.Logger
@@ -75,6 +107,7 @@ type DefaultSystemService struct {
 func NewDefaultSystemService(
 	sourceManager repository.SourceManager,
 	cache repository.CacheRepository,
+	cacheMetrics *repository.CacheMetrics,
 	healthChecker *health.Checker,
 	metricsCollector *health.MetricsCollector,
 	cfg *config.Config,
@@ -84,6 +117,7 @@ func NewDefaultSystemService(
 	return &DefaultSystemService{
 		sourceManager:    sourceManager,
 		cache:            cache,
+		cacheMetrics:     cacheMetrics,
 		healthChecker:    healthChecker,
 		metricsCollector: metricsCollector,
 		logger:           log,
@@ -262,6 +296,38 @@ func (s *DefaultSystemService) RefreshSources(ctx context.Context) error {
 	return nil
 }
 
+// OpenCircuitBreaker 强制打开音源熔断器
+func (s *DefaultSystemService) OpenCircuitBreaker(ctx context.Context, name string) (*model.CircuitBreakerStatus, error) {
+	s.logger.Info("强制打开音源熔断器", logger.String("source", name))
+
+	status, err := s.sourceManager.OpenCircuitBreaker(name)
+	if err != nil {
+		s.logger.Error("强制打开音源熔断器失败",
+			logger.String("source", name),
+			logger.ErrorField("error", err),
+		)
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// ResetCircuitBreaker 重置音源熔断器
+func (s *DefaultSystemService) ResetCircuitBreaker(ctx context.Context, name string) (*model.CircuitBreakerStatus, error) {
+	s.logger.Info("重置音源熔断器", logger.String("source", name))
+
+	status, err := s.sourceManager.ResetCircuitBreaker(name)
+	if err != nil {
+		s.logger.Error("重置音源熔断器失败",
+			logger.String("source", name),
+			logger.ErrorField("error", err),
+		)
+		return nil, err
+	}
+
+	return status, nil
+}
+
 // ClearCache 清空缓存
 func (s *DefaultSystemService) ClearCache(ctx context.Context) error {
 	if s.cache == nil {
@@ -296,8 +362,9 @@ func (s *DefaultSystemService) GetCacheStats(ctx context.Context) (map[string]in
 		return nil, err
 	}
 	
-	// 添加缓存启用状态
+	// 添加缓存启用状态与各命名空间的命中统计
 	stats["enabled"] = true
+	stats["namespaces"] = s.cacheMetrics.Snapshot()
 	
 	s.logger.Info("获取缓存统计成功",
 		logger.Any("stats", stats),
@@ -306,6 +373,221 @@ func (s *DefaultSystemService) GetCacheStats(ctx context.Context) (map[string]in
 	return stats, nil
 }
 
+// ListCacheKeys 分页列出选中的缓存键，键按字典序排列
+func (s *DefaultSystemService) ListCacheKeys(ctx context.Context, selector *model.CacheKeySelector, page, pageSize int) (*model.CacheKeyList, error) {
+	if s.cache == nil {
+		return nil, ErrCacheDisabled
+	}
+	if *selector == (model.CacheKeySelector{}) {
+		selector = &model.CacheKeySelector{Pattern: "*"}
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultCacheKeyPageSize
+	}
+	pageSize = min(pageSize, maxCacheKeyPageSize)
+
+	pattern, keys, err := s.selectCacheKeys(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	start := min((page-1)*pageSize, len(keys))
+	end := min(start+pageSize, len(keys))
+	return &model.CacheKeyList{
+		Pattern:    pattern,
+		Keys:       append([]string{}, keys[start:end]...),
+		Pagination: model.CalculatePagination(page, pageSize, int64(len(keys))),
+	}, nil
+}
+
+// GetCacheEntry 获取缓存条目，JSON值原样返回
+func (s *DefaultSystemService) GetCacheEntry(ctx context.Context, key string) (*model.CacheEntry, error) {
+	if s.cache == nil {
+		return nil, ErrCacheDisabled
+	}
+	if key == "" {
+		return nil, fmt.Errorf("参数错误: 缓存键不能为空")
+	}
+
+	exists, err := s.cache.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("缓存条目不存在: %s", key)
+	}
+
+	raw, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("缓存值序列化失败: %w", err)
+		}
+	}
+
+	ttl, err := s.cache.GetTTL(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("缓存条目不存在: %s", key)
+	}
+
+	entry := &model.CacheEntry{
+		Key:  key,
+		Size: len(data),
+		TTL:  int64(ttl.Seconds()),
+	}
+	if json.Valid(data) {
+		entry.Value = json.RawMessage(data)
+	} else {
+		entry.Value = string(data)
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+	return entry, nil
+}
+
+// DeleteCacheKeys 删除选中的缓存键，期间已过期或被删除的键不计入数量
+func (s *DefaultSystemService) DeleteCacheKeys(ctx context.Context, selector *model.CacheKeySelector) (int, error) {
+	if s.cache == nil {
+		return 0, ErrCacheDisabled
+	}
+
+	pattern, keys, err := s.selectCacheKeys(ctx, selector)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, key := range keys {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			if ctx.Err() != nil {
+				return deleted, ctx.Err()
+			}
+			continue
+		}
+		deleted++
+	}
+
+	s.logger.Info("删除缓存键",
+		logger.String("pattern", pattern),
+		logger.Int("matched", len(keys)),
+		logger.Int("deleted", deleted),
+	)
+	return deleted, nil
+}
+
+// ExpireCacheKeys 重新设置选中缓存键的剩余过期时间，可用于延长或缩短缓存时间
+// 带缓存策略的条目同时改写新鲜期截止时间，否则新鲜期过后仍按旧值返回并被后台刷新覆盖
+func (s *DefaultSystemService) ExpireCacheKeys(ctx context.Context, selector *model.CacheKeySelector, ttl time.Duration) (*model.CacheExpireSummary, error) {
+	if s.cache == nil {
+		return nil, ErrCacheDisabled
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("参数错误: 过期时间必须大于0")
+	}
+
+	pattern, keys, err := s.selectCacheKeys(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	summary := &model.CacheExpireSummary{
+		Pattern: pattern,
+		TTL:     ttl.String(),
+		Matched: len(keys),
+		Results: make([]*model.CacheExpireResult, 0, len(keys)),
+	}
+	for _, key := range keys {
+		result := s.expireCacheKey(ctx, key, ttl)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if result.Applied == model.CacheExpireFresh || result.Applied == model.CacheExpireTTL {
+			summary.Updated++
+		}
+		summary.Results = append(summary.Results, result)
+	}
+
+	s.logger.Info("设置缓存过期时间",
+		logger.String("pattern", pattern),
+		logger.Duration("ttl", ttl),
+		logger.Int("matched", summary.Matched),
+		logger.Int("updated", summary.Updated),
+	)
+	return summary, nil
+}
+
+// expireCacheKey 重新设置单个缓存键的过期时间
+func (s *DefaultSystemService) expireCacheKey(ctx context.Context, key string, ttl time.Duration) *model.CacheExpireResult {
+	result := &model.CacheExpireResult{Key: key}
+	exists, err := s.cache.Exists(ctx, key)
+	if err == nil && !exists {
+		result.Applied = model.CacheExpireSkipped
+		return result
+	}
+
+	fresh, err := repository.ExpirePolicyEntry(ctx, s.cache, key, ttl)
+	if err == nil && !fresh {
+		err = s.cache.Expire(ctx, key, ttl)
+	}
+	if err != nil {
+		result.Applied = model.CacheExpireFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	result.ExpiresAt = &expiresAt
+	result.Applied = model.CacheExpireTTL
+	if fresh {
+		result.Applied = model.CacheExpireFresh
+		result.FreshUntil = &expiresAt
+	}
+	return result
+}
+
+// selectCacheKeys 按选择条件查找缓存键，返回使用的模式与匹配的键
+func (s *DefaultSystemService) selectCacheKeys(ctx context.Context, selector *model.CacheKeySelector) (string, []string, error) {
+	var pattern string
+	switch {
+	case selector.Key != "":
+		exists, err := s.cache.Exists(ctx, selector.Key)
+		if err != nil {
+			return "", nil, err
+		}
+		if !exists {
+			return selector.Key, nil, nil
+		}
+		return selector.Key, []string{selector.Key}, nil
+	case selector.Pattern != "":
+		pattern = selector.Pattern
+	case selector.Namespace != "":
+		pattern = repository.NamespacePattern(selector.Namespace)
+	default:
+		return "", nil, fmt.Errorf("参数错误: 需要指定key、pattern或namespace")
+	}
+
+	keys, err := s.cache.GetKeys(ctx, pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("查找缓存键失败: %w", err)
+	}
+	return pattern, keys, nil
+}
+
 // RecordRequest 记录请求指标
 func (s *DefaultSystemService) RecordRequest(success bool, latency time.Duration) {
 	if s.metricsCollector != nil {
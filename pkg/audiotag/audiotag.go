@@ -0,0 +1,192 @@
+// Package audiotag 音频文件标签读写
+//
+// 支持MP3的ID3v2/ID3v1标签、FLAC与OGG（Vorbis/Opus）的Vorbis注释、M4A的MP4元数据，
+// 同时解析音频时长与内嵌封面。写入支持MP3（ID3v2.4）与FLAC（Vorbis注释与图片块）。
+package audiotag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/encoding"
+)
+
+// 音频格式
+const (
+	FormatMP3  = "mp3"
+	FormatFLAC = "flac"
+	FormatOGG  = "ogg"
+	FormatOpus = "opus"
+	FormatM4A  = "m4a"
+)
+
+// ErrUnsupportedFormat 不支持的音频格式
+var ErrUnsupportedFormat = errors.New("不支持的音频格式")
+
+// Picture 内嵌图片
+type Picture struct {
+	MIMEType string // 图片MIME类型
+	Data     []byte // 图片数据
+}
+
+// Tags 音频标签
+type Tags struct {
+	Title      string        // 标题
+	Artist     string        // 艺术家，多位以", "连接
+	Album      string        // 专辑
+	Year       string        // 年份
+	Track      int           // 音轨号
+	Format     string        // 音频格式
+	Duration   time.Duration // 时长
+	Bitrate    int           // 比特率（kbps），无法计算时为0
+	SampleRate int           // 采样率（Hz）
+	Picture    *Picture      // 内嵌封面，读取时未要求封面则为nil
+}
+
+// Options 读取选项
+type Options struct {
+	// WithPicture 是否读取内嵌封面数据
+	WithPicture bool
+}
+
+// ReadFile 读取音频文件标签
+func ReadFile(path string, opts Options) (*Tags, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return Read(file, info.Size(), opts)
+}
+
+// Read 从数据流读取音频标签，size为数据总长度
+func Read(r io.ReadSeeker, size int64, opts Options) (*Tags, error) {
+	header := make([]byte, 12)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("读取文件头失败: %w", err)
+	}
+	header = header[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	tags := &Tags{}
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		tags.Format = FormatFLAC
+		err = readFLAC(r, tags, opts)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		tags.Format = FormatOGG
+		err = readOGG(r, size, tags, opts)
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		tags.Format = FormatM4A
+		err = readMP4(r, size, tags, opts)
+	case bytes.HasPrefix(header, []byte("ID3")) || isMPEGSync(header):
+		tags.Format = FormatMP3
+		err = readMP3(r, size, tags, opts)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 未从帧头获得比特率时按文件大小估算
+	if tags.Bitrate == 0 && tags.Duration > 0 {
+		tags.Bitrate = int(float64(size) * 8 / tags.Duration.Seconds() / 1000)
+	}
+
+	tags.Title = strings.TrimSpace(tags.Title)
+	tags.Artist = strings.TrimSpace(tags.Artist)
+	tags.Album = strings.TrimSpace(tags.Album)
+	tags.Year = strings.TrimSpace(tags.Year)
+	return tags, nil
+}
+
+// DetectPictureMIME 根据图片数据识别MIME类型
+func DetectPictureMIME(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return "image/gif"
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp"
+	case bytes.HasPrefix(data, []byte("BM")):
+		return "image/bmp"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// decodeLatin1 解码ISO-8859-1文本
+// 不少中文音频文件在Latin-1字段中实际写入GBK编码，这里优先尝试识别
+func decodeLatin1(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	if fixed := encoding.FixChineseEncoding(string(data)); utf8.ValidString(fixed) && !strings.ContainsRune(fixed, utf8.RuneError) {
+		return fixed
+	}
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// joinValues 连接多值字段，忽略空值与重复值
+func joinValues(values []string) string {
+	seen := make(map[string]bool, len(values))
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseTrack 解析"3"或"3/12"形式的音轨号
+func parseTrack(value string) int {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		value = value[:i]
+	}
+	track := 0
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			break
+		}
+		track = track*10 + int(c-'0')
+	}
+	return track
+}
+
+// secondsToDuration 将秒数转换为时长
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
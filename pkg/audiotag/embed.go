@@ -0,0 +1,53 @@
+package audiotag
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"time"
+)
+
+// Metadata 写入音频文件的标签
+type Metadata struct {
+	Title        string      // 标题
+	Artist       string      // 艺术家
+	Album        string      // 专辑
+	Picture      *Picture    // 封面
+	Lyrics       string      // 歌词原文（通常为LRC），写入ID3v2的USLT帧或Vorbis注释的LYRICS字段
+	SyncedLyrics []LyricLine // 逐行歌词，写入ID3v2的SYLT帧
+}
+
+// LyricLine 带时间的一行歌词
+type LyricLine struct {
+	Time time.Duration // 开始时间
+	Text string        // 歌词文本
+}
+
+// Embedded 写入标签后的音频流
+type Embedded struct {
+	Reader io.Reader // 音频流，格式不支持写入时为原始数据
+	Format string    // 识别出的音频格式，无法识别时为空
+	Tagged bool      // 是否写入了标签
+	Delta  int64     // 输出长度相对输入长度的变化量
+}
+
+// Embed 在音频流头部写入标签，只读取到音频数据开始处，其余数据按流输出
+// MP3已有的ID3v2标签整体替换；FLAC替换同名注释字段与图片块并去掉填充块；
+// 其他格式原样输出，Tagged为false
+func Embed(r io.Reader, meta *Metadata) (*Embedded, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	header, _ := br.Peek(12)
+
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return embedFLAC(br, meta)
+	case bytes.HasPrefix(header, []byte("ID3")) || isMPEGSync(header):
+		return embedMP3(br, meta)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return &Embedded{Reader: br, Format: FormatOGG}, nil
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return &Embedded{Reader: br, Format: FormatM4A}, nil
+	default:
+		return &Embedded{Reader: br}, nil
+	}
+}
@@ -0,0 +1,215 @@
+package audiotag
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/jpeg" // 注册JPEG解码器以读取封面尺寸
+	_ "image/png"  // 注册PNG解码器以读取封面尺寸
+	"io"
+	"strings"
+)
+
+// flacBlockPadding FLAC填充块类型
+const flacBlockPadding = 1
+
+// flacMaxBlockLength FLAC元数据块长度上限（24位）
+const flacMaxBlockLength = 1<<24 - 1
+
+// flacVendor 文件中没有Vorbis注释时使用的编码器标识
+const flacVendor = "music-api-proxy"
+
+// flacBlock FLAC元数据块
+type flacBlock struct {
+	blockType byte
+	data      []byte
+}
+
+// embedFLAC 重写FLAC元数据块：保留流信息、寻道表等块，替换注释与图片，去掉填充
+// 已有注释中未被覆盖的字段（如DATE、TRACKNUMBER）原样保留
+func embedFLAC(br *bufio.Reader, meta *Metadata) (*Embedded, error) {
+	if _, err := br.Discard(4); err != nil {
+		return nil, fmt.Errorf("读取FLAC文件头失败: %w", err)
+	}
+	removed := int64(4)
+
+	var kept []flacBlock
+	vendor := flacVendor
+	var comments []string
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil, fmt.Errorf("读取FLAC元数据块失败: %w", err)
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		if length > flacMaxBlockSize {
+			return nil, fmt.Errorf("FLAC元数据块过大: %d", length)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, fmt.Errorf("读取FLAC元数据块失败: %w", err)
+		}
+		removed += 4 + length
+
+		switch blockType {
+		case flacBlockVorbisComment:
+			vendor, comments = parseVorbisComments(data)
+		case flacBlockPicture:
+			if meta.Picture == nil {
+				kept = append(kept, flacBlock{blockType: blockType, data: data})
+			}
+		case flacBlockPadding:
+		default:
+			kept = append(kept, flacBlock{blockType: blockType, data: data})
+		}
+		if last {
+			break
+		}
+	}
+	if len(kept) == 0 || kept[0].blockType != flacBlockStreamInfo {
+		return nil, fmt.Errorf("FLAC文件缺少STREAMINFO块")
+	}
+
+	kept = append(kept, flacBlock{blockType: flacBlockVorbisComment, data: buildVorbisComment(vendor, mergeVorbisComments(comments, meta))})
+	if pic := meta.Picture; pic != nil && len(pic.Data) > 0 {
+		if data := buildFLACPicture(pic); len(data) <= flacMaxBlockLength {
+			kept = append(kept, flacBlock{blockType: flacBlockPicture, data: data})
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("fLaC")
+	for i, block := range kept {
+		if len(block.data) > flacMaxBlockLength {
+			return nil, fmt.Errorf("FLAC元数据块过大: %d", len(block.data))
+		}
+		blockType := block.blockType
+		if i == len(kept)-1 {
+			blockType |= 0x80
+		}
+		length := len(block.data)
+		out.Write([]byte{blockType, byte(length >> 16), byte(length >> 8), byte(length)})
+		out.Write(block.data)
+	}
+
+	return &Embedded{
+		Reader: io.MultiReader(bytes.NewReader(out.Bytes()), br),
+		Format: FormatFLAC,
+		Tagged: true,
+		Delta:  int64(out.Len()) - removed,
+	}, nil
+}
+
+// parseVorbisComments 解析Vorbis注释块，返回编码器标识与"KEY=value"形式的字段
+func parseVorbisComments(data []byte) (string, []string) {
+	read32 := func() (uint32, bool) {
+		if len(data) < 4 {
+			return 0, false
+		}
+		v := binary.LittleEndian.Uint32(data[:4])
+		data = data[4:]
+		return v, true
+	}
+
+	vendorLen, ok := read32()
+	if !ok || uint64(len(data)) < uint64(vendorLen) {
+		return flacVendor, nil
+	}
+	vendor := string(data[:vendorLen])
+	data = data[vendorLen:]
+
+	count, ok := read32()
+	if !ok {
+		return vendor, nil
+	}
+	var comments []string
+	for i := uint32(0); i < count; i++ {
+		length, ok := read32()
+		if !ok || uint64(len(data)) < uint64(length) {
+			break
+		}
+		comments = append(comments, string(data[:length]))
+		data = data[length:]
+	}
+	return vendor, comments
+}
+
+// mergeVorbisComments 用新标签覆盖同名字段
+func mergeVorbisComments(comments []string, meta *Metadata) []string {
+	values := []struct{ key, value string }{
+		{"TITLE", meta.Title},
+		{"ARTIST", meta.Artist},
+		{"ALBUM", meta.Album},
+		{"LYRICS", meta.Lyrics},
+	}
+	replaced := make(map[string]bool, len(values)+1)
+	for _, field := range values {
+		if field.value != "" {
+			replaced[field.key] = true
+		}
+	}
+	if meta.Picture != nil {
+		replaced["METADATA_BLOCK_PICTURE"] = true
+	}
+
+	merged := make([]string, 0, len(comments)+len(values))
+	for _, comment := range comments {
+		key, _, ok := strings.Cut(comment, "=")
+		if ok && !replaced[strings.ToUpper(key)] {
+			merged = append(merged, comment)
+		}
+	}
+	for _, field := range values {
+		if field.value != "" {
+			merged = append(merged, field.key+"="+field.value)
+		}
+	}
+	return merged
+}
+
+// buildVorbisComment 生成Vorbis注释块
+func buildVorbisComment(vendor string, comments []string) []byte {
+	var buf bytes.Buffer
+	write32 := func(v int) {
+		binary.Write(&buf, binary.LittleEndian, uint32(v))
+	}
+
+	write32(len(vendor))
+	buf.WriteString(vendor)
+	write32(len(comments))
+	for _, comment := range comments {
+		write32(len(comment))
+		buf.WriteString(comment)
+	}
+	return buf.Bytes()
+}
+
+// buildFLACPicture 生成FLAC图片块，图片类型为封面（正面）
+func buildFLACPicture(pic *Picture) []byte {
+	var width, height int
+	if config, _, err := image.DecodeConfig(bytes.NewReader(pic.Data)); err == nil {
+		width, height = config.Width, config.Height
+	}
+	mime := pictureMIME(pic)
+
+	var buf bytes.Buffer
+	write32 := func(v int) {
+		binary.Write(&buf, binary.BigEndian, uint32(v))
+	}
+
+	write32(3)
+	write32(len(mime))
+	buf.WriteString(mime)
+	write32(0) // 空描述
+	write32(width)
+	write32(height)
+	write32(24) // 色深
+	write32(0)  // 非索引色图片
+	write32(len(pic.Data))
+	buf.Write(pic.Data)
+	return buf.Bytes()
+}
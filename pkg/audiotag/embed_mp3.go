@@ -0,0 +1,121 @@
+package audiotag
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// id3Language 歌词帧的语言代码，歌词语言未知
+const id3Language = "und"
+
+// ID3v2.4文本编码
+const id3EncodingUTF8 = 0x03
+
+// embedMP3 跳过已有的ID3v2标签并写入新的ID3v2.4标签
+func embedMP3(br *bufio.Reader, meta *Metadata) (*Embedded, error) {
+	var removed int64
+	// 文件头部可能有多个连续的ID3v2标签
+	for {
+		header, err := br.Peek(10)
+		if err != nil || !bytes.HasPrefix(header, []byte("ID3")) {
+			break
+		}
+		size := 10 + int64(syncsafe(header[6:10]))
+		if header[3] == 4 && header[5]&0x10 != 0 {
+			size += 10 // 标签尾部
+		}
+		if size-10 > id3v2MaxSize {
+			return nil, fmt.Errorf("ID3v2标签过大: %d", size-10)
+		}
+		if _, err := io.CopyN(io.Discard, br, size); err != nil {
+			return nil, fmt.Errorf("跳过ID3v2标签失败: %w", err)
+		}
+		removed += size
+	}
+
+	tag := buildID3v2(meta)
+	return &Embedded{
+		Reader: io.MultiReader(bytes.NewReader(tag), br),
+		Format: FormatMP3,
+		Tagged: true,
+		Delta:  int64(len(tag)) - removed,
+	}, nil
+}
+
+// buildID3v2 生成ID3v2.4标签，文本统一使用UTF-8编码
+func buildID3v2(meta *Metadata) []byte {
+	var frames bytes.Buffer
+	writeFrame := func(id string, body []byte) {
+		frames.WriteString(id)
+		frames.Write(syncsafeBytes(uint32(len(body))))
+		frames.Write([]byte{0, 0}) // 帧标志
+		frames.Write(body)
+	}
+	writeText := func(id, value string) {
+		if value != "" {
+			writeFrame(id, append([]byte{id3EncodingUTF8}, value...))
+		}
+	}
+
+	writeText("TIT2", meta.Title)
+	writeText("TPE1", meta.Artist)
+	writeText("TALB", meta.Album)
+
+	if pic := meta.Picture; pic != nil && len(pic.Data) > 0 {
+		var body bytes.Buffer
+		body.WriteByte(id3EncodingUTF8)
+		body.WriteString(pictureMIME(pic))
+		body.WriteByte(0)
+		body.WriteByte(3) // 封面（正面）
+		body.WriteByte(0) // 空描述
+		body.Write(pic.Data)
+		writeFrame("APIC", body.Bytes())
+	}
+
+	if meta.Lyrics != "" {
+		var body bytes.Buffer
+		body.WriteByte(id3EncodingUTF8)
+		body.WriteString(id3Language)
+		body.WriteByte(0) // 空描述
+		body.WriteString(meta.Lyrics)
+		writeFrame("USLT", body.Bytes())
+	}
+
+	if len(meta.SyncedLyrics) > 0 {
+		var body bytes.Buffer
+		body.WriteByte(id3EncodingUTF8)
+		body.WriteString(id3Language)
+		body.WriteByte(2) // 时间戳单位：毫秒
+		body.WriteByte(1) // 内容类型：歌词
+		body.WriteByte(0) // 空描述
+		stamp := make([]byte, 4)
+		for _, line := range meta.SyncedLyrics {
+			body.WriteString(line.Text)
+			body.WriteByte(0)
+			binary.BigEndian.PutUint32(stamp, uint32(max(line.Time.Milliseconds(), 0)))
+			body.Write(stamp)
+		}
+		writeFrame("SYLT", body.Bytes())
+	}
+
+	tag := make([]byte, 0, 10+frames.Len())
+	tag = append(tag, 'I', 'D', '3', 4, 0, 0)
+	tag = append(tag, syncsafeBytes(uint32(frames.Len()))...)
+	return append(tag, frames.Bytes()...)
+}
+
+// syncsafeBytes 编码28位同步安全整数
+func syncsafeBytes(n uint32) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// pictureMIME 获取图片MIME类型，未设置时按内容识别
+func pictureMIME(pic *Picture) string {
+	if pic.MIMEType != "" {
+		return pic.MIMEType
+	}
+	return DetectPictureMIME(pic.Data)
+}
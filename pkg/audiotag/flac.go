@@ -0,0 +1,213 @@
+package audiotag
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FLAC元数据块类型
+const (
+	flacBlockStreamInfo    = 0
+	flacBlockVorbisComment = 4
+	flacBlockPicture       = 6
+)
+
+// flacMaxBlockSize 单个元数据块的最大读取长度
+const flacMaxBlockSize = 16 << 20
+
+// readFLAC 读取FLAC元数据块
+func readFLAC(r io.ReadSeeker, tags *Tags, opts Options) error {
+	if _, err := r.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+
+	var picture *Picture
+	pictureIsFront := false
+	header := make([]byte, 4)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("读取FLAC元数据块失败: %w", err)
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		switch {
+		case blockType == flacBlockStreamInfo,
+			blockType == flacBlockVorbisComment,
+			blockType == flacBlockPicture && opts.WithPicture && !pictureIsFront:
+			if length > flacMaxBlockSize {
+				return fmt.Errorf("FLAC元数据块过大: %d", length)
+			}
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return fmt.Errorf("读取FLAC元数据块失败: %w", err)
+			}
+
+			switch blockType {
+			case flacBlockStreamInfo:
+				readStreamInfo(data, tags)
+			case flacBlockVorbisComment:
+				readVorbisComment(data, tags, opts)
+			case flacBlockPicture:
+				if pic, front := parseFLACPicture(data); pic != nil {
+					picture, pictureIsFront = pic, front
+				}
+			}
+		default:
+			if _, err := r.Seek(length, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+
+		if last {
+			break
+		}
+	}
+
+	if picture != nil && (tags.Picture == nil || pictureIsFront) {
+		tags.Picture = picture
+	}
+	return nil
+}
+
+// readStreamInfo 解析STREAMINFO块中的采样率与总采样数
+func readStreamInfo(data []byte, tags *Tags) {
+	if len(data) < 18 {
+		return
+	}
+	packed := binary.BigEndian.Uint64(data[10:18])
+	sampleRate := int(packed >> 44)
+	totalSamples := packed & 0xFFFFFFFFF
+
+	tags.SampleRate = sampleRate
+	if sampleRate > 0 && totalSamples > 0 {
+		tags.Duration = secondsToDuration(float64(totalSamples) / float64(sampleRate))
+	}
+}
+
+// parseFLACPicture 解析FLAC图片块，返回图片及是否为封面
+func parseFLACPicture(data []byte) (*Picture, bool) {
+	read32 := func() (uint32, bool) {
+		if len(data) < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		return v, true
+	}
+	skip := func(n uint32) bool {
+		if uint64(len(data)) < uint64(n) {
+			return false
+		}
+		data = data[n:]
+		return true
+	}
+
+	pictureType, ok := read32()
+	if !ok {
+		return nil, false
+	}
+	mimeLen, ok := read32()
+	if !ok || uint64(len(data)) < uint64(mimeLen) {
+		return nil, false
+	}
+	mime := strings.ToLower(string(data[:mimeLen]))
+	data = data[mimeLen:]
+
+	descLen, ok := read32()
+	if !ok || !skip(descLen) || !skip(16) { // 描述、宽、高、色深、颜色数
+		return nil, false
+	}
+
+	dataLen, ok := read32()
+	if !ok || uint64(len(data)) < uint64(dataLen) || dataLen == 0 {
+		return nil, false
+	}
+	picData := append([]byte(nil), data[:dataLen]...)
+	if mime == "" || !strings.HasPrefix(mime, "image/") {
+		mime = DetectPictureMIME(picData)
+	}
+
+	return &Picture{MIMEType: mime, Data: picData}, pictureType == 3
+}
+
+// readVorbisComment 解析Vorbis注释
+func readVorbisComment(data []byte, tags *Tags, opts Options) {
+	read32 := func() (uint32, bool) {
+		if len(data) < 4 {
+			return 0, false
+		}
+		v := binary.LittleEndian.Uint32(data[:4])
+		data = data[4:]
+		return v, true
+	}
+
+	vendorLen, ok := read32()
+	if !ok || uint64(len(data)) < uint64(vendorLen) {
+		return
+	}
+	data = data[vendorLen:]
+
+	count, ok := read32()
+	if !ok {
+		return
+	}
+
+	var artists, albums, titles []string
+	for i := uint32(0); i < count; i++ {
+		length, ok := read32()
+		if !ok || uint64(len(data)) < uint64(length) {
+			break
+		}
+		comment := string(data[:length])
+		data = data[length:]
+
+		eq := strings.IndexByte(comment, '=')
+		if eq <= 0 {
+			continue
+		}
+		key := strings.ToUpper(comment[:eq])
+		value := comment[eq+1:]
+
+		switch key {
+		case "TITLE":
+			titles = append(titles, value)
+		case "ARTIST":
+			artists = append(artists, value)
+		case "ALBUM":
+			albums = append(albums, value)
+		case "DATE", "YEAR":
+			if tags.Year == "" {
+				tags.Year = firstN(value, 4)
+			}
+		case "TRACKNUMBER":
+			tags.Track = parseTrack(value)
+		case "METADATA_BLOCK_PICTURE":
+			if !opts.WithPicture {
+				continue
+			}
+			raw, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			if pic, front := parseFLACPicture(raw); pic != nil && (tags.Picture == nil || front) {
+				tags.Picture = pic
+			}
+		}
+	}
+
+	if len(titles) > 0 {
+		tags.Title = joinValues(titles)
+	}
+	if len(artists) > 0 {
+		tags.Artist = joinValues(artists)
+	}
+	if len(albums) > 0 {
+		tags.Album = joinValues(albums)
+	}
+}
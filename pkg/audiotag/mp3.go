@@ -0,0 +1,515 @@
+package audiotag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3v2MaxSize ID3v2标签的最大读取长度
+const id3v2MaxSize = 64 << 20
+
+// mpegScanLimit 查找首个MPEG帧时的最大扫描长度
+const mpegScanLimit = 256 << 10
+
+// id3v1Size ID3v1标签长度
+const id3v1Size = 128
+
+// MPEG比特率表（kbps），按 [版本类别][层][索引] 排列，版本类别0为MPEG1，1为MPEG2/2.5
+var mpegBitrates = [2][3][16]int{
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	},
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	},
+}
+
+// MPEG采样率表（Hz），按 [版本][索引] 排列，版本0为MPEG1，1为MPEG2，2为MPEG2.5
+var mpegSampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// mpegFrame MPEG音频帧头
+type mpegFrame struct {
+	version    int // 0: MPEG1, 1: MPEG2, 2: MPEG2.5
+	layer      int // 1, 2, 3
+	bitrate    int // kbps
+	sampleRate int
+	padding    int
+	mono       bool
+}
+
+// samplesPerFrame 每帧采样数
+func (f *mpegFrame) samplesPerFrame() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && f.version != 0:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// length 帧长度（字节）
+func (f *mpegFrame) length() int {
+	if f.layer == 1 {
+		return (12*f.bitrate*1000/f.sampleRate + f.padding) * 4
+	}
+	return f.samplesPerFrame()/8*f.bitrate*1000/f.sampleRate + f.padding
+}
+
+// sideInfoSize Layer III边信息长度
+func (f *mpegFrame) sideInfoSize() int {
+	if f.layer != 3 {
+		return 0
+	}
+	switch {
+	case f.version == 0 && f.mono:
+		return 17
+	case f.version == 0:
+		return 32
+	case f.mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// isMPEGSync 检查数据是否以MPEG帧同步字开头
+func isMPEGSync(data []byte) bool {
+	_, ok := parseMPEGFrame(data)
+	return ok
+}
+
+// parseMPEGFrame 解析MPEG音频帧头
+func parseMPEGFrame(data []byte) (*mpegFrame, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return nil, false
+	}
+
+	var version int
+	switch (data[1] >> 3) & 0x03 {
+	case 3:
+		version = 0
+	case 2:
+		version = 1
+	case 0:
+		version = 2
+	default:
+		return nil, false
+	}
+
+	layerBits := (data[1] >> 1) & 0x03
+	if layerBits == 0 {
+		return nil, false
+	}
+	layer := 4 - int(layerBits)
+
+	bitrateIndex := data[2] >> 4
+	sampleRateIndex := (data[2] >> 2) & 0x03
+	if bitrateIndex == 0 || bitrateIndex == 0x0F || sampleRateIndex == 3 {
+		return nil, false
+	}
+
+	versionClass := 0
+	if version != 0 {
+		versionClass = 1
+	}
+
+	return &mpegFrame{
+		version:    version,
+		layer:      layer,
+		bitrate:    mpegBitrates[versionClass][layer-1][bitrateIndex],
+		sampleRate: mpegSampleRates[version][sampleRateIndex],
+		padding:    int((data[2] >> 1) & 0x01),
+		mono:       data[3]>>6 == 0x03,
+	}, true
+}
+
+// readMP3 读取MP3标签与时长
+func readMP3(r io.ReadSeeker, size int64, tags *Tags, opts Options) error {
+	audioStart := int64(0)
+	tlen := int64(0)
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err == nil && bytes.HasPrefix(header, []byte("ID3")) {
+		tagSize := int64(syncsafe(header[6:10]))
+		if tagSize > id3v2MaxSize {
+			return fmt.Errorf("ID3v2标签过大: %d", tagSize)
+		}
+		data := make([]byte, tagSize)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("读取ID3v2标签失败: %w", err)
+		}
+		tlen = readID3v2(header, data, tags, opts)
+
+		audioStart = 10 + tagSize
+		if header[3] == 4 && header[5]&0x10 != 0 {
+			audioStart += 10 // 标签尾部
+		}
+	}
+
+	audioEnd := size
+	if size >= id3v1Size {
+		trailer := make([]byte, id3v1Size)
+		if _, err := r.Seek(size-id3v1Size, io.SeekStart); err == nil {
+			if _, err := io.ReadFull(r, trailer); err == nil && bytes.HasPrefix(trailer, []byte("TAG")) {
+				readID3v1(trailer, tags)
+				audioEnd -= id3v1Size
+			}
+		}
+	}
+
+	readMPEGDuration(r, audioStart, audioEnd, tags)
+	if tags.Duration == 0 && tlen > 0 {
+		tags.Duration = secondsToDuration(float64(tlen) / 1000)
+	}
+	return nil
+}
+
+// readMPEGDuration 根据首帧的Xing/VBRI头或固定码率计算时长
+func readMPEGDuration(r io.ReadSeeker, start, end int64, tags *Tags) {
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return
+	}
+
+	limit := end - start
+	if limit > mpegScanLimit {
+		limit = mpegScanLimit
+	}
+	if limit <= 4 {
+		return
+	}
+	buf := make([]byte, limit)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMPEGFrame(buf[i:])
+		if !ok {
+			continue
+		}
+
+		// 校验下一帧同步字，避免误判
+		next := i + frame.length()
+		if next+4 <= len(buf) && !isMPEGSync(buf[next:]) {
+			continue
+		}
+
+		tags.SampleRate = frame.sampleRate
+		tags.Bitrate = frame.bitrate
+		frameData := buf[i:]
+
+		if frames := xingFrames(frame, frameData); frames > 0 {
+			seconds := float64(frames) * float64(frame.samplesPerFrame()) / float64(frame.sampleRate)
+			tags.Duration = secondsToDuration(seconds)
+			if seconds > 0 {
+				tags.Bitrate = int(float64(end-start-int64(i)) * 8 / seconds / 1000)
+			}
+			return
+		}
+
+		audioBytes := end - start - int64(i)
+		if frame.bitrate > 0 && audioBytes > 0 {
+			tags.Duration = secondsToDuration(float64(audioBytes) * 8 / float64(frame.bitrate*1000))
+		}
+		return
+	}
+}
+
+// xingFrames 读取Xing/Info或VBRI头中的总帧数
+func xingFrames(frame *mpegFrame, data []byte) int {
+	offset := 4 + frame.sideInfoSize()
+	if len(data) >= offset+12 {
+		id := string(data[offset : offset+4])
+		if id == "Xing" || id == "Info" {
+			flags := binary.BigEndian.Uint32(data[offset+4 : offset+8])
+			if flags&0x01 != 0 {
+				return int(binary.BigEndian.Uint32(data[offset+8 : offset+12]))
+			}
+		}
+	}
+
+	if len(data) >= 36+18 && string(data[36:40]) == "VBRI" {
+		return int(binary.BigEndian.Uint32(data[36+14 : 36+18]))
+	}
+	return 0
+}
+
+// readID3v2 解析ID3v2标签，返回TLEN帧中的时长（毫秒）
+func readID3v2(header, data []byte, tags *Tags, opts Options) int64 {
+	major := header[3]
+	flags := header[5]
+
+	// v2.2与v2.3的反同步作用于整个标签
+	if major < 4 && flags&0x80 != 0 {
+		data = removeUnsync(data)
+	}
+
+	pos := 0
+	if flags&0x40 != 0 && major >= 3 && len(data) >= 4 {
+		if major == 3 {
+			pos = 4 + int(binary.BigEndian.Uint32(data[0:4]))
+		} else {
+			pos = int(syncsafe(data[0:4]))
+		}
+	}
+
+	idSize, headerSize := 4, 10
+	if major == 2 {
+		idSize, headerSize = 3, 6
+	}
+
+	var tlen int64
+	var picture *Picture
+	pictureIsFront := false
+
+	for pos+headerSize <= len(data) {
+		id := string(data[pos : pos+idSize])
+		if id[0] == 0 {
+			break // 填充区
+		}
+
+		var frameSize int
+		var formatFlags byte
+		switch major {
+		case 2:
+			frameSize = int(data[pos+3])<<16 | int(data[pos+4])<<8 | int(data[pos+5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+			formatFlags = data[pos+9]
+		default:
+			frameSize = int(syncsafe(data[pos+4 : pos+8]))
+			formatFlags = data[pos+9]
+		}
+		pos += headerSize
+		if frameSize <= 0 || pos+frameSize > len(data) {
+			break
+		}
+		body := data[pos : pos+frameSize]
+		pos += frameSize
+
+		// 跳过压缩、加密的帧
+		if major == 3 && formatFlags&0xC0 != 0 {
+			continue
+		}
+		if major == 4 {
+			if formatFlags&0x0C != 0 {
+				continue
+			}
+			if formatFlags&0x01 != 0 && len(body) >= 4 {
+				body = body[4:] // 数据长度指示
+			}
+			if formatFlags&0x02 != 0 {
+				body = removeUnsync(body)
+			}
+		}
+
+		switch id {
+		case "TIT2", "TT2":
+			tags.Title = joinValues(id3Text(body))
+		case "TPE1", "TP1":
+			tags.Artist = joinValues(id3Text(body))
+		case "TALB", "TAL":
+			tags.Album = joinValues(id3Text(body))
+		case "TRCK", "TRK":
+			if values := id3Text(body); len(values) > 0 {
+				tags.Track = parseTrack(values[0])
+			}
+		case "TYER", "TYE", "TDRC":
+			if values := id3Text(body); len(values) > 0 && tags.Year == "" {
+				tags.Year = firstN(values[0], 4)
+			}
+		case "TLEN", "TLE":
+			if values := id3Text(body); len(values) > 0 {
+				tlen, _ = strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+			}
+		case "APIC", "PIC":
+			if !opts.WithPicture || pictureIsFront {
+				continue
+			}
+			if pic, front := id3Picture(body, id == "PIC"); pic != nil {
+				picture = pic
+				pictureIsFront = front
+			}
+		}
+	}
+
+	if picture != nil {
+		tags.Picture = picture
+	}
+	return tlen
+}
+
+// readID3v1 解析ID3v1标签，仅补充ID3v2缺失的字段
+func readID3v1(data []byte, tags *Tags) {
+	field := func(b []byte) string {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(decodeLatin1(bytes.TrimRight(b, " ")))
+	}
+
+	if tags.Title == "" {
+		tags.Title = field(data[3:33])
+	}
+	if tags.Artist == "" {
+		tags.Artist = field(data[33:63])
+	}
+	if tags.Album == "" {
+		tags.Album = field(data[63:93])
+	}
+	if tags.Year == "" {
+		tags.Year = field(data[93:97])
+	}
+	if tags.Track == 0 && data[125] == 0 && data[126] != 0 {
+		tags.Track = int(data[126])
+	}
+}
+
+// id3Text 解码文本帧，返回以空字符分隔的多个值
+func id3Text(body []byte) []string {
+	if len(body) < 1 {
+		return nil
+	}
+	text := id3Decode(body[0], body[1:])
+	return strings.Split(strings.TrimRight(text, "\x00"), "\x00")
+}
+
+// id3Picture 解析APIC/PIC帧，返回图片及是否为封面
+func id3Picture(body []byte, v22 bool) (*Picture, bool) {
+	if len(body) < 2 {
+		return nil, false
+	}
+	enc := body[0]
+	rest := body[1:]
+
+	mime := ""
+	if v22 {
+		if len(rest) < 3 {
+			return nil, false
+		}
+		switch strings.ToUpper(string(rest[:3])) {
+		case "PNG":
+			mime = "image/png"
+		default:
+			mime = "image/jpeg"
+		}
+		rest = rest[3:]
+	} else {
+		i := bytes.IndexByte(rest, 0)
+		if i < 0 {
+			return nil, false
+		}
+		mime = strings.ToLower(string(rest[:i]))
+		rest = rest[i+1:]
+	}
+
+	if len(rest) < 1 {
+		return nil, false
+	}
+	pictureType := rest[0]
+	rest = rest[1:]
+
+	// 跳过描述
+	_, rest = id3Terminated(enc, rest)
+	if len(rest) == 0 {
+		return nil, false
+	}
+
+	data := append([]byte(nil), rest...)
+	if mime == "" || !strings.HasPrefix(mime, "image/") {
+		mime = DetectPictureMIME(data)
+	}
+	if mime == "image/jpg" {
+		mime = "image/jpeg"
+	}
+	return &Picture{MIMEType: mime, Data: data}, pictureType == 3
+}
+
+// id3Terminated 按编码切分以空字符结尾的字符串
+func id3Terminated(enc byte, data []byte) (string, []byte) {
+	if enc == 1 || enc == 2 {
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return id3Decode(enc, data[:i]), data[i+2:]
+			}
+		}
+		return id3Decode(enc, data), nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return id3Decode(enc, data[:i]), data[i+1:]
+	}
+	return id3Decode(enc, data), nil
+}
+
+// id3Decode 按ID3文本编码解码
+func id3Decode(enc byte, data []byte) string {
+	switch enc {
+	case 1:
+		return decodeUTF16(data, binary.LittleEndian, true)
+	case 2:
+		return decodeUTF16(data, binary.BigEndian, false)
+	case 3:
+		return string(data)
+	default:
+		return decodeLatin1(data)
+	}
+}
+
+// decodeUTF16 解码UTF-16文本，withBOM为true时每个值可带字节序标记
+func decodeUTF16(data []byte, order binary.ByteOrder, withBOM bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if withBOM {
+			switch {
+			case data[i] == 0xFF && data[i+1] == 0xFE:
+				order = binary.LittleEndian
+				continue
+			case data[i] == 0xFE && data[i+1] == 0xFF:
+				order = binary.BigEndian
+				continue
+			}
+		}
+		units = append(units, order.Uint16(data[i:i+2]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// removeUnsync 还原反同步处理（0xFF 0x00 -> 0xFF）
+func removeUnsync(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		out = append(out, data[i])
+		if data[i] == 0xFF && i+1 < len(data) && data[i+1] == 0x00 {
+			i++
+		}
+	}
+	return out
+}
+
+// syncsafe 解析同步安全整数
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// firstN 截取字符串前n个字符
+func firstN(value string, n int) string {
+	value = strings.TrimSpace(value)
+	if len(value) > n {
+		return value[:n]
+	}
+	return value
+}
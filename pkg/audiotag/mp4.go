@@ -0,0 +1,206 @@
+package audiotag
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// mp4MaxMoovSize moov原子的最大读取长度
+const mp4MaxMoovSize = 64 << 20
+
+// MP4 data原子的数据类型
+const (
+	mp4TypeUTF8 = 1
+	mp4TypeJPEG = 13
+	mp4TypePNG  = 14
+)
+
+// mp4Atom MP4原子
+type mp4Atom struct {
+	name string
+	data []byte
+}
+
+// readMP4 读取M4A的moov原子中的时长与ilst元数据
+func readMP4(r io.ReadSeeker, size int64, tags *Tags, opts Options) error {
+	moov, err := findTopLevelAtom(r, size, "moov")
+	if err != nil {
+		return err
+	}
+
+	for _, atom := range parseAtoms(moov) {
+		switch atom.name {
+		case "mvhd":
+			readMVHD(atom.data, tags)
+		case "udta":
+			for _, child := range parseAtoms(atom.data) {
+				if child.name == "meta" && len(child.data) > 4 {
+					readMeta(child.data[4:], tags, opts) // meta为完整原子，跳过版本与标志
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findTopLevelAtom 按原子头跳转查找顶层原子，避免读取mdat
+func findTopLevelAtom(r io.ReadSeeker, size int64, name string) ([]byte, error) {
+	offset := int64(0)
+	header := make([]byte, 16)
+
+	for offset+8 <= size {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, fmt.Errorf("读取MP4原子失败: %w", err)
+		}
+
+		atomSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		atomName := string(header[4:8])
+		headerSize := int64(8)
+
+		switch atomSize {
+		case 0:
+			atomSize = size - offset
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, fmt.Errorf("读取MP4原子失败: %w", err)
+			}
+			atomSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if atomSize < headerSize {
+			return nil, fmt.Errorf("无效的MP4原子: %s", atomName)
+		}
+
+		if atomName == name {
+			length := atomSize - headerSize
+			if length > mp4MaxMoovSize {
+				return nil, fmt.Errorf("MP4原子过大: %s", atomName)
+			}
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, fmt.Errorf("读取MP4原子失败: %w", err)
+			}
+			return data, nil
+		}
+
+		offset += atomSize
+	}
+
+	return nil, fmt.Errorf("未找到MP4原子: %s", name)
+}
+
+// parseAtoms 解析内存中的子原子列表
+func parseAtoms(data []byte) []mp4Atom {
+	atoms := make([]mp4Atom, 0)
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		name := string(data[4:8])
+		headerSize := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return atoms
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return atoms
+		}
+
+		atoms = append(atoms, mp4Atom{name: name, data: data[headerSize:size]})
+		data = data[size:]
+	}
+	return atoms
+}
+
+// readMVHD 解析影片头中的时长
+func readMVHD(data []byte, tags *Tags) {
+	if len(data) < 1 {
+		return
+	}
+
+	var timescale, duration uint64
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return
+		}
+		timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+		duration = binary.BigEndian.Uint64(data[24:32])
+	} else {
+		if len(data) < 20 {
+			return
+		}
+		timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	}
+
+	if timescale > 0 {
+		tags.Duration = secondsToDuration(float64(duration) / float64(timescale))
+	}
+}
+
+// readMeta 解析meta原子下的ilst元数据
+func readMeta(data []byte, tags *Tags, opts Options) {
+	for _, atom := range parseAtoms(data) {
+		if atom.name != "ilst" {
+			continue
+		}
+		for _, item := range parseAtoms(atom.data) {
+			readIlstItem(item, tags, opts)
+		}
+	}
+}
+
+// readIlstItem 解析单个ilst条目
+func readIlstItem(item mp4Atom, tags *Tags, opts Options) {
+	for _, child := range parseAtoms(item.data) {
+		if child.name != "data" || len(child.data) < 8 {
+			continue
+		}
+		dataType := binary.BigEndian.Uint32(child.data[0:4]) & 0x00FFFFFF
+		value := child.data[8:]
+
+		switch item.name {
+		case "\xa9nam":
+			tags.Title = string(value)
+		case "\xa9ART":
+			tags.Artist = string(value)
+		case "aART":
+			if tags.Artist == "" {
+				tags.Artist = string(value)
+			}
+		case "\xa9alb":
+			tags.Album = string(value)
+		case "\xa9day":
+			tags.Year = firstN(string(value), 4)
+		case "trkn":
+			if len(value) >= 4 {
+				tags.Track = int(binary.BigEndian.Uint16(value[2:4]))
+			}
+		case "covr":
+			if !opts.WithPicture || tags.Picture != nil || len(value) == 0 {
+				continue
+			}
+			mime := DetectPictureMIME(value)
+			switch dataType {
+			case mp4TypeJPEG:
+				mime = "image/jpeg"
+			case mp4TypePNG:
+				mime = "image/png"
+			}
+			tags.Picture = &Picture{MIMEType: mime, Data: append([]byte(nil), value...)}
+		}
+
+		if dataType == mp4TypeUTF8 {
+			return // 文本条目只取第一个data原子
+		}
+	}
+}
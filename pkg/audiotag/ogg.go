@@ -0,0 +1,134 @@
+package audiotag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// oggMaxHeaderSize 标识头与注释头的最大读取长度
+const oggMaxHeaderSize = 16 << 20
+
+// oggTailSize 查找最后一页时从文件尾部读取的长度
+const oggTailSize = 64 << 10
+
+// readOGG 读取OGG Vorbis/Opus的注释与时长
+func readOGG(r io.ReadSeeker, size int64, tags *Tags, opts Options) error {
+	packets, err := readOggPackets(r, 2)
+	if err != nil {
+		return err
+	}
+	if len(packets) < 2 {
+		return fmt.Errorf("OGG头信息不完整")
+	}
+
+	ident, comment := packets[0], packets[1]
+	sampleRate := 0
+	preSkip := 0
+
+	switch {
+	case len(ident) >= 16 && bytes.HasPrefix(ident, []byte("\x01vorbis")):
+		sampleRate = int(binary.LittleEndian.Uint32(ident[12:16]))
+		if bytes.HasPrefix(comment, []byte("\x03vorbis")) {
+			readVorbisComment(comment[7:], tags, opts)
+		}
+	case len(ident) >= 19 && bytes.HasPrefix(ident, []byte("OpusHead")):
+		tags.Format = FormatOpus
+		sampleRate = 48000 // Opus的granule始终以48kHz计
+		preSkip = int(binary.LittleEndian.Uint16(ident[10:12]))
+		if bytes.HasPrefix(comment, []byte("OpusTags")) {
+			readVorbisComment(comment[8:], tags, opts)
+		}
+	default:
+		return ErrUnsupportedFormat
+	}
+
+	if tags.Format == FormatOpus {
+		tags.SampleRate = int(binary.LittleEndian.Uint32(ident[12:16]))
+	} else {
+		tags.SampleRate = sampleRate
+	}
+
+	if granule := lastGranule(r, size); granule > int64(preSkip) && sampleRate > 0 {
+		seconds := float64(granule-int64(preSkip)) / float64(sampleRate)
+		tags.Duration = secondsToDuration(seconds)
+		if seconds > 0 {
+			tags.Bitrate = int(float64(size) * 8 / seconds / 1000)
+		}
+	}
+	return nil
+}
+
+// readOggPackets 从文件头开始读取前count个数据包
+func readOggPackets(r io.ReadSeeker, count int) ([][]byte, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	packets := make([][]byte, 0, count)
+	var current []byte
+	total := 0
+	header := make([]byte, 27)
+
+	for len(packets) < count {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return packets, nil
+		}
+		if string(header[0:4]) != "OggS" {
+			return nil, fmt.Errorf("无效的OGG页")
+		}
+
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return nil, fmt.Errorf("读取OGG页失败: %w", err)
+		}
+
+		for _, seg := range segments {
+			chunk := make([]byte, seg)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("读取OGG页失败: %w", err)
+			}
+			total += int(seg)
+			if total > oggMaxHeaderSize {
+				return nil, fmt.Errorf("OGG头信息过大")
+			}
+			current = append(current, chunk...)
+			if seg < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == count {
+					break
+				}
+			}
+		}
+	}
+
+	return packets, nil
+}
+
+// lastGranule 读取最后一页的granule位置
+func lastGranule(r io.ReadSeeker, size int64) int64 {
+	offset := size - oggTailSize
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0
+	}
+
+	buf := make([]byte, size-offset)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	for i := bytes.LastIndex(buf, []byte("OggS")); i >= 0; i = bytes.LastIndex(buf[:i], []byte("OggS")) {
+		if i+14 > len(buf) {
+			continue
+		}
+		granule := int64(binary.LittleEndian.Uint64(buf[i+6 : i+14]))
+		if granule > 0 {
+			return granule
+		}
+	}
+	return 0
+}
@@ -115,6 +115,8 @@ var (
 	ErrTokenInvalid    = NewBusinessError(CodeTokenInvalid, "")
 	ErrTokenExpired    = NewBusinessError(CodeTokenExpired, "")
 	ErrPermissionDenied = NewBusinessError(CodePermissionDenied, "")
+	ErrLinkSignatureInvalid = NewBusinessError(CodeLinkSignatureInvalid, "")
+	ErrLinkExpired      = NewBusinessError(CodeLinkExpired, "")
 
 	// 限流相关错误
 	ErrRateLimitExceeded = NewBusinessError(CodeRateLimitExceeded, "")
@@ -45,6 +45,8 @@ const (
 	CodeTokenInvalid    = 4002 // 令牌无效
 	CodeTokenExpired    = 4003 // 令牌过期
 	CodePermissionDenied = 4004 // 权限不足
+	CodeLinkSignatureInvalid = 4005 // 代理链接签名无效
+	CodeLinkExpired      = 4006 // 代理链接已过期
 
 	// 限流相关错误 5000-5099
 	CodeRateLimitExceeded = 5001 // 请求频率超限
@@ -87,6 +89,8 @@ var ErrorMessages = map[int]string{
 	CodeTokenInvalid:      "令牌无效",
 	CodeTokenExpired:      "令牌过期",
 	CodePermissionDenied:  "权限不足",
+	CodeLinkSignatureInvalid: "代理链接签名无效",
+	CodeLinkExpired:       "代理链接已过期",
 	CodeRateLimitExceeded: "请求频率超限",
 	CodeQuotaExceeded:     "配额超限",
 	CodeSystemMaintenance: "系统维护中",
@@ -0,0 +1,121 @@
+// Package fakeupstream GDStudio/UNM上游API的离线替身
+//
+// 模拟GDStudioSource与UNMSource使用的 types=search|url|pic|lyric 接口，
+// 曲目数据来自JSON曲库文件，并可注入延迟、HTTP错误、GBK编码响应与损坏的JSON，
+// 既可作为独立进程运行（cmd/fake-upstream），也可通过NewTestServer在httptest中使用。
+package fakeupstream
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//go:embed catalog.json
+var defaultCatalogJSON []byte
+
+// Track 曲库中的曲目
+type Track struct {
+	ID       int64    `json:"id"`       // 曲目ID
+	Name     string   `json:"name"`     // 歌曲名
+	Artist   []string `json:"artist"`   // 歌手列表
+	Album    string   `json:"album"`    // 专辑名
+	PicID    string   `json:"pic_id"`   // 专辑图ID
+	LyricID  int64    `json:"lyric_id"` // 歌词ID，为0时与曲目ID相同
+	Source   string   `json:"source"`   // 所属平台，为空时匹配任意平台
+	BR       int      `json:"br"`       // 可提供的最高音质，默认320
+	Size     int64    `json:"size"`     // 文件大小（KB），为0时按生成的音频计算
+	Duration int      `json:"duration"` // 时长（秒），默认180
+	URL      string   `json:"url"`      // 播放链接，为空时由替身服务器生成静音音频
+	PicURL   string   `json:"pic_url"`  // 专辑图链接，为空时由替身服务器生成纯色图片
+	Lyric    string   `json:"lyric"`    // LRC歌词
+	TLyric   string   `json:"tlyric"`   // LRC翻译歌词
+}
+
+// Catalog 曲库
+type Catalog struct {
+	Tracks []Track `json:"tracks"`
+}
+
+// DefaultCatalog 内置曲库
+func DefaultCatalog() *Catalog {
+	catalog, err := ParseCatalog(defaultCatalogJSON)
+	if err != nil {
+		panic(fmt.Sprintf("内置曲库无效: %v", err))
+	}
+	return catalog
+}
+
+// LoadCatalog 从JSON文件加载曲库
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取曲库文件失败: %w", err)
+	}
+	return ParseCatalog(data)
+}
+
+// ParseCatalog 解析JSON曲库，支持 {"tracks": [...]} 或曲目数组两种格式
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &catalog.Tracks); err != nil {
+			return nil, fmt.Errorf("解析曲库失败: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("解析曲库失败: %w", err)
+	}
+
+	seen := make(map[string]bool, len(catalog.Tracks))
+	for i := range catalog.Tracks {
+		track := &catalog.Tracks[i]
+		if track.ID == 0 {
+			return nil, fmt.Errorf("第%d首曲目缺少ID", i+1)
+		}
+		key := track.Source + "/" + strconv.FormatInt(track.ID, 10)
+		if seen[key] {
+			return nil, fmt.Errorf("曲目ID重复: %s", key)
+		}
+		seen[key] = true
+
+		if track.LyricID == 0 {
+			track.LyricID = track.ID
+		}
+		if track.BR == 0 {
+			track.BR = 320
+		}
+		if track.Duration <= 0 {
+			track.Duration = 180
+		}
+	}
+
+	return &catalog, nil
+}
+
+// matchesPlatform 检查曲目是否属于请求的平台
+func (t *Track) matchesPlatform(platform string) bool {
+	return platform == "" || t.Source == "" || t.Source == platform
+}
+
+// matchesKeyword 检查曲目是否命中关键词，关键词可为曲目ID
+func (t *Track) matchesKeyword(keyword string) bool {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return false
+	}
+	if keyword == strconv.FormatInt(t.ID, 10) {
+		return true
+	}
+
+	text := strings.ToLower(t.Name + " " + strings.Join(t.Artist, " ") + " " + t.Album)
+	for _, term := range strings.Fields(keyword) {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package fakeupstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 接口类型，与上游API的types参数一致
+const (
+	TypeSearch = "search"
+	TypeURL    = "url"
+	TypePic    = "pic"
+	TypeLyric  = "lyric"
+	TypeHealth = "health"
+	TypeAsset  = "asset" // 替身服务器生成的音频与图片
+)
+
+// Faults 故障注入配置
+type Faults struct {
+	Latency       time.Duration // 固定延迟
+	Jitter        time.Duration // 在固定延迟之上附加的随机延迟上限
+	ErrorRate     float64       // 返回HTTP错误的概率，0为从不，1为总是
+	ErrorStatus   int           // 注入的HTTP状态码，默认500
+	MalformedRate float64       // 返回截断JSON的概率
+	GBK           bool          // 以GBK编码响应体
+	OmitCharset   bool          // GBK响应不在Content-Type中声明charset，用于验证编码自动识别
+}
+
+// IsZero 检查是否未配置任何故障
+func (f Faults) IsZero() bool {
+	return f == Faults{}
+}
+
+// Validate 验证故障配置
+func (f Faults) Validate() error {
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("延迟不能为负数")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("错误概率必须在0-1之间，当前值: %.2f", f.ErrorRate)
+	}
+	if f.MalformedRate < 0 || f.MalformedRate > 1 {
+		return fmt.Errorf("损坏JSON概率必须在0-1之间，当前值: %.2f", f.MalformedRate)
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 400 || f.ErrorStatus > 599) {
+		return fmt.Errorf("错误状态码必须在400-599之间，当前值: %d", f.ErrorStatus)
+	}
+	return nil
+}
+
+// faultsJSON 控制接口使用的故障配置格式，延迟以"200ms"形式表示
+type faultsJSON struct {
+	Latency       string  `json:"latency,omitempty"`
+	Jitter        string  `json:"jitter,omitempty"`
+	ErrorRate     float64 `json:"error_rate,omitempty"`
+	ErrorStatus   int     `json:"error_status,omitempty"`
+	MalformedRate float64 `json:"malformed_rate,omitempty"`
+	GBK           bool    `json:"gbk,omitempty"`
+	OmitCharset   bool    `json:"omit_charset,omitempty"`
+}
+
+// MarshalJSON 序列化故障配置
+func (f Faults) MarshalJSON() ([]byte, error) {
+	v := faultsJSON{
+		ErrorRate:     f.ErrorRate,
+		ErrorStatus:   f.ErrorStatus,
+		MalformedRate: f.MalformedRate,
+		GBK:           f.GBK,
+		OmitCharset:   f.OmitCharset,
+	}
+	if f.Latency > 0 {
+		v.Latency = f.Latency.String()
+	}
+	if f.Jitter > 0 {
+		v.Jitter = f.Jitter.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON 反序列化故障配置
+func (f *Faults) UnmarshalJSON(data []byte) error {
+	var v faultsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	faults := Faults{
+		ErrorRate:     v.ErrorRate,
+		ErrorStatus:   v.ErrorStatus,
+		MalformedRate: v.MalformedRate,
+		GBK:           v.GBK,
+		OmitCharset:   v.OmitCharset,
+	}
+	var err error
+	if v.Latency != "" {
+		if faults.Latency, err = time.ParseDuration(v.Latency); err != nil {
+			return fmt.Errorf("无效的延迟: %w", err)
+		}
+	}
+	if v.Jitter != "" {
+		if faults.Jitter, err = time.ParseDuration(v.Jitter); err != nil {
+			return fmt.Errorf("无效的随机延迟: %w", err)
+		}
+	}
+
+	*f = faults
+	return nil
+}
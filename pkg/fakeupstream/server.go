@@ -0,0 +1,604 @@
+package fakeupstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"github.com/IIXINGCHEN/music-api-proxy/pkg/logger"
+)
+
+// 生成静音音频使用的MPEG1 Layer III帧（128kbps, 44.1kHz, 立体声）
+var silentFrameHeader = []byte{0xFF, 0xFB, 0x90, 0x00}
+
+const (
+	silentFrameSize       = 417
+	silentSamplesPerFrame = 1152
+	silentSampleRate      = 44100
+)
+
+// Server 上游API替身服务器，实现http.Handler
+type Server struct {
+	mu        sync.RWMutex
+	catalog   *Catalog
+	faults    map[string]Faults
+	requests  map[string]int
+	publicURL string
+	logger    logger.Logger
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// Option 服务器选项
+type Option func(*Server)
+
+// WithSeed 固定随机种子，使按概率注入的故障可复现
+func WithSeed(seed int64) Option {
+	return func(s *Server) {
+		s.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithPublicURL 指定生成音频、图片链接时使用的对外地址，默认取请求的Host
+func WithPublicURL(publicURL string) Option {
+	return func(s *Server) {
+		s.publicURL = strings.TrimRight(publicURL, "/")
+	}
+}
+
+// WithLogger 指定请求日志输出
+func WithLogger(log logger.Logger) Option {
+	return func(s *Server) {
+		s.logger = log
+	}
+}
+
+// WithFaults 指定初始故障配置，types为空时作用于所有接口
+func WithFaults(types string, faults Faults) Option {
+	return func(s *Server) {
+		s.faults[types] = faults
+	}
+}
+
+// NewServer 创建替身服务器，catalog为nil时使用内置曲库
+func NewServer(catalog *Catalog, opts ...Option) *Server {
+	if catalog == nil {
+		catalog = DefaultCatalog()
+	}
+
+	s := &Server{
+		catalog:  catalog,
+		faults:   make(map[string]Faults),
+		requests: make(map[string]int),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SetCatalog 替换曲库
+func (s *Server) SetCatalog(catalog *Catalog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catalog = catalog
+}
+
+// SetFaults 设置故障注入，types为空时作用于所有未单独配置的接口
+func (s *Server) SetFaults(types string, faults Faults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if faults.IsZero() {
+		delete(s.faults, types)
+	} else {
+		s.faults[types] = faults
+	}
+	return nil
+}
+
+// ClearFaults 清除所有故障注入
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string]Faults)
+}
+
+// Faults 获取当前的故障注入配置
+func (s *Server) Faults() map[string]Faults {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	faults := make(map[string]Faults, len(s.faults))
+	for k, v := range s.faults {
+		faults[k] = v
+	}
+	return faults
+}
+
+// Requests 获取指定接口类型收到的请求数
+func (s *Server) Requests(types string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.requests[types]
+}
+
+// ResetRequests 清零请求计数
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = make(map[string]int)
+}
+
+// ServeHTTP 处理请求
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/_control/"):
+		s.handleControl(w, r)
+	case strings.HasPrefix(r.URL.Path, "/_assets/"):
+		s.serve(w, r, TypeAsset, s.handleAsset)
+	case r.URL.Query().Get("types") != "":
+		s.serve(w, r, r.URL.Query().Get("types"), s.handleAPI)
+	default:
+		// GDStudio检查 BaseURL+"/health"，UNM检查 BaseURL+"/"
+		s.serve(w, r, TypeHealth, func(w http.ResponseWriter, r *http.Request) {
+			s.writeJSON(w, r, TypeHealth, map[string]string{"status": "ok", "service": "fake-upstream"})
+		})
+	}
+}
+
+// serve 记录请求并在注入延迟、错误后调用处理函数
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, types string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.requests[types]++
+	faults, ok := s.faults[types]
+	if !ok {
+		faults = s.faults[""]
+	}
+	s.mu.Unlock()
+
+	if s.logger != nil {
+		s.logger.Info("替身上游收到请求",
+			logger.String("types", types),
+			logger.String("url", r.URL.String()),
+		)
+	}
+
+	if delay := faults.Latency + s.jitter(faults.Jitter); delay > 0 {
+		if !sleep(r.Context(), delay) {
+			return
+		}
+	}
+
+	if s.chance(faults.ErrorRate) {
+		status := faults.ErrorStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":"injected fault","status":%d}`, status)
+		return
+	}
+
+	handler(w, r.WithContext(context.WithValue(r.Context(), faultsKey{}, faults)))
+}
+
+// faultsKey 在请求上下文中传递本次生效的故障配置
+type faultsKey struct{}
+
+// handleAPI 处理 types=search|url|pic|lyric 接口
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	types := query.Get("types")
+	platform := query.Get("source")
+
+	switch types {
+	case TypeSearch:
+		s.writeJSON(w, r, types, s.search(platform, query.Get("name"), atoi(query.Get("count"), 20), atoi(query.Get("pages"), 1)))
+	case TypeURL:
+		s.writeJSON(w, r, types, s.musicURL(r, platform, query.Get("id"), atoi(query.Get("br"), 320)))
+	case TypePic:
+		s.writeJSON(w, r, types, s.picture(r, platform, query.Get("id"), atoi(query.Get("size"), 300)))
+	case TypeLyric:
+		s.writeJSON(w, r, types, s.lyric(platform, query.Get("id")))
+	default:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":"unknown types: %s"}`, types)
+	}
+}
+
+// searchItem 搜索结果条目，字段与GDStudio一致
+type searchItem struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Artist  []string `json:"artist"`
+	Album   string   `json:"album"`
+	PicID   string   `json:"pic_id"`
+	URLID   int64    `json:"url_id"`
+	LyricID int64    `json:"lyric_id"`
+	Source  string   `json:"source"`
+}
+
+// search 搜索曲目
+func (s *Server) search(platform, keyword string, count, page int) []searchItem {
+	if count <= 0 {
+		count = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]searchItem, 0)
+	skip := (page - 1) * count
+	for i := range s.catalog.Tracks {
+		track := &s.catalog.Tracks[i]
+		if !track.matchesPlatform(platform) || !track.matchesKeyword(keyword) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		source := track.Source
+		if source == "" {
+			source = platform
+		}
+		items = append(items, searchItem{
+			ID:      track.ID,
+			Name:    track.Name,
+			Artist:  track.Artist,
+			Album:   track.Album,
+			PicID:   track.PicID,
+			URLID:   track.ID,
+			LyricID: track.LyricID,
+			Source:  source,
+		})
+		if len(items) >= count {
+			break
+		}
+	}
+	return items
+}
+
+// musicURL 获取播放链接，未找到曲目时与真实接口一样返回空链接
+func (s *Server) musicURL(r *http.Request, platform, id string, br int) map[string]interface{} {
+	track := s.findTrack(platform, func(t *Track) bool { return strconv.FormatInt(t.ID, 10) == id })
+	if track == nil {
+		return map[string]interface{}{"url": "", "br": 0, "size": 0, "from": ""}
+	}
+
+	if br <= 0 || br > track.BR {
+		br = track.BR
+	}
+
+	musicURL := track.URL
+	size := track.Size
+	checksum := ""
+	if musicURL == "" {
+		musicURL = fmt.Sprintf("%s/_assets/audio/%s/%d.mp3", s.baseURL(r), pathSegment(track.Source, platform), track.ID)
+		// 生成的音频内容确定，与UNM一样附带MD5便于校验下载
+		sum := md5.Sum(silentAudio(track.Duration))
+		checksum = hex.EncodeToString(sum[:])
+	}
+	if size == 0 {
+		size = int64(silentAudioSize(track.Duration) / 1024)
+	}
+
+	return map[string]interface{}{
+		"url":  musicURL,
+		"br":   br,
+		"size": size,
+		"md5":  checksum,
+		"from": "fake-upstream",
+	}
+}
+
+// picture 获取专辑图链接
+func (s *Server) picture(r *http.Request, platform, picID string, size int) map[string]string {
+	track := s.findTrack(platform, func(t *Track) bool { return t.PicID != "" && t.PicID == picID })
+	if track == nil {
+		return map[string]string{"url": ""}
+	}
+	if track.PicURL != "" {
+		return map[string]string{"url": track.PicURL}
+	}
+	return map[string]string{"url": fmt.Sprintf("%s/_assets/pic/%s.png?size=%d", s.baseURL(r), picID, size)}
+}
+
+// lyric 获取歌词
+func (s *Server) lyric(platform, id string) map[string]string {
+	track := s.findTrack(platform, func(t *Track) bool { return strconv.FormatInt(t.LyricID, 10) == id })
+	if track == nil {
+		return map[string]string{"lyric": "", "tlyric": ""}
+	}
+	return map[string]string{"lyric": track.Lyric, "tlyric": track.TLyric}
+}
+
+// findTrack 按条件查找平台内的曲目
+func (s *Server) findTrack(platform string, match func(*Track) bool) *Track {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := range s.catalog.Tracks {
+		track := &s.catalog.Tracks[i]
+		if track.matchesPlatform(platform) && match(track) {
+			copied := *track
+			return &copied
+		}
+	}
+	return nil
+}
+
+// handleAsset 输出生成的静音音频与纯色专辑图
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/_assets/"), "/")
+
+	switch {
+	case len(parts) == 3 && parts[0] == "audio":
+		id := strings.TrimSuffix(parts[2], path.Ext(parts[2]))
+		platform := parts[1]
+		if platform == "-" {
+			platform = ""
+		}
+		track := s.findTrack(platform, func(t *Track) bool { return strconv.FormatInt(t.ID, 10) == id })
+		if track == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		http.ServeContent(w, r, parts[2], time.Time{}, bytes.NewReader(silentAudio(track.Duration)))
+	case len(parts) == 2 && parts[0] == "pic":
+		size := atoi(r.URL.Query().Get("size"), 300)
+		if size < 16 {
+			size = 16
+		}
+		if size > 1000 {
+			size = 1000
+		}
+		data, err := solidPNG(strings.TrimSuffix(parts[1], path.Ext(parts[1])), size)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		http.ServeContent(w, r, parts[1], time.Time{}, bytes.NewReader(data))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleControl 处理故障注入与统计控制接口
+//
+//	GET    /_control/faults             查看故障配置
+//	PUT    /_control/faults?types=url   设置故障配置，types为空时作用于所有接口
+//	DELETE /_control/faults             清除故障配置
+//	GET    /_control/requests           查看请求计数
+//	DELETE /_control/requests           清零请求计数
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+
+	switch strings.TrimPrefix(r.URL.Path, "/_control/") {
+	case "faults":
+		switch r.Method {
+		case http.MethodGet:
+			encoder.Encode(s.Faults())
+		case http.MethodPut, http.MethodPost:
+			var faults Faults
+			if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				encoder.Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			if err := s.SetFaults(r.URL.Query().Get("types"), faults); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				encoder.Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			encoder.Encode(s.Faults())
+		case http.MethodDelete:
+			s.ClearFaults()
+			encoder.Encode(s.Faults())
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	case "requests":
+		switch r.Method {
+		case http.MethodGet:
+			s.mu.RLock()
+			encoder.Encode(s.requests)
+			s.mu.RUnlock()
+		case http.MethodDelete:
+			s.ResetRequests()
+			encoder.Encode(map[string]int{})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		encoder.Encode(map[string]string{"error": "unknown control endpoint"})
+	}
+}
+
+// writeJSON 按本次生效的故障配置输出JSON响应
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, types string, v interface{}) {
+	faults, _ := r.Context().Value(faultsKey{}).(Faults)
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if s.chance(faults.MalformedRate) {
+		body = append(body[:len(body)/2], `,"truncated`...)
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if faults.GBK {
+		encoded, err := simplifiedchinese.GBK.NewEncoder().Bytes(body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("GBK编码失败: %v", err), http.StatusInternalServerError)
+			return
+		}
+		body = encoded
+		contentType = "application/json; charset=gbk"
+		if faults.OmitCharset {
+			contentType = "application/json"
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Write(body)
+}
+
+// baseURL 生成资源链接使用的地址
+func (s *Server) baseURL(r *http.Request) string {
+	if s.publicURL != "" {
+		return s.publicURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// chance 按概率返回true
+func (s *Server) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return s.rand.Float64() < rate
+}
+
+// jitter 生成随机附加延迟
+func (s *Server) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return time.Duration(s.rand.Int63n(int64(max)))
+}
+
+// TestServer 运行在httptest上的替身服务器
+type TestServer struct {
+	*httptest.Server
+	Upstream *Server
+}
+
+// NewTestServer 启动httptest替身服务器，使用完毕后需调用Close
+func NewTestServer(catalog *Catalog, opts ...Option) *TestServer {
+	upstream := NewServer(catalog, opts...)
+	return &TestServer{
+		Server:   httptest.NewServer(upstream),
+		Upstream: upstream,
+	}
+}
+
+// GDStudioBaseURL GDStudio音源使用的base_url
+func (ts *TestServer) GDStudioBaseURL() string {
+	return ts.URL + "/api.php"
+}
+
+// UNMBaseURL UNM音源使用的base_url
+func (ts *TestServer) UNMBaseURL() string {
+	return ts.URL
+}
+
+// silentAudioSize 生成的静音音频大小（字节）
+func silentAudioSize(seconds int) int {
+	frames := seconds * silentSampleRate / silentSamplesPerFrame
+	return frames * silentFrameSize
+}
+
+// silentAudio 生成指定时长的静音MP3
+func silentAudio(seconds int) []byte {
+	frame := make([]byte, silentFrameSize)
+	copy(frame, silentFrameHeader)
+	return bytes.Repeat(frame, silentAudioSize(seconds)/silentFrameSize)
+}
+
+// solidPNG 生成以ID决定颜色的纯色PNG图片
+func solidPNG(id string, size int) ([]byte, error) {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	sum := h.Sum32()
+	fill := color.RGBA{R: uint8(sum >> 16), G: uint8(sum >> 8), B: uint8(sum), A: 0xFF}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = fill.R, fill.G, fill.B, fill.A
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sleep 等待指定时间，请求取消时返回false
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pathSegment 生成资源路径中的平台段
+func pathSegment(source, platform string) string {
+	if source != "" {
+		return source
+	}
+	if platform != "" {
+		return platform
+	}
+	return "-"
+}
+
+// atoi 解析整数参数，失败时返回默认值
+func atoi(value string, def int) int {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return def
+}
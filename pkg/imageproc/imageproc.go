@@ -0,0 +1,233 @@
+// Package imageproc 封面图片的解码、缩放与重新编码
+//
+// 仅依赖标准库：支持解码JPEG、PNG、GIF，输出JPEG或PNG。
+// 重新编码的图片不携带EXIF、ICC等元数据。
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // 注册GIF解码器
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// 输出格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// 图片处理错误
+var (
+	ErrUnsupportedFormat = errors.New("不支持的图片格式")
+	ErrImageTooLarge     = errors.New("图片尺寸超过限制")
+)
+
+// formatAliases 格式别名
+var formatAliases = map[string]string{
+	"jpeg": FormatJPEG,
+	"jpg":  FormatJPEG,
+	"png":  FormatPNG,
+}
+
+// contentTypes 输出格式对应的Content-Type
+var contentTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatPNG:  "image/png",
+}
+
+// NormalizeFormat 规范化输出格式名称，不支持时返回ErrUnsupportedFormat
+func NormalizeFormat(format string) (string, error) {
+	if normalized, ok := formatAliases[strings.ToLower(strings.TrimSpace(format))]; ok {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("%w: %s，支持的格式: jpeg, png", ErrUnsupportedFormat, format)
+}
+
+// ContentType 获取输出格式的Content-Type
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Decode 解码图片，返回图片与源格式名称
+// 解码前先读取图片头，宽高乘积超过maxPixels时返回ErrImageTooLarge，避免高压缩比的小文件解码出巨大的像素缓冲；
+// maxPixels不大于0时不限制
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	if maxPixels > 0 {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("解码图片失败: %w", err)
+		}
+		if pixels := int64(config.Width) * int64(config.Height); pixels > int64(maxPixels) {
+			return nil, "", fmt.Errorf("%w: %dx%d，上限 %d 像素", ErrImageTooLarge, config.Width, config.Height, maxPixels)
+		}
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("解码图片失败: %w", err)
+	}
+	return img, format, nil
+}
+
+// Fit 等比缩放图片使长边等于maxSide，maxSide不大于0或与原图一致时原样返回
+func Fit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxSide <= 0 || width == 0 || height == 0 || max(width, height) == maxSide {
+		return img
+	}
+
+	if width >= height {
+		return Resize(img, maxSide, max(1, int(math.Round(float64(height)*float64(maxSide)/float64(width)))))
+	}
+	return Resize(img, max(1, int(math.Round(float64(width)*float64(maxSide)/float64(height)))), maxSide)
+}
+
+// Resize 缩放图片到指定尺寸
+// 使用可分离的三角滤波，缩小时滤波半径随缩放比例扩大，相当于对覆盖区域取加权平均，避免锯齿
+func Resize(img image.Image, width, height int) *image.NRGBA {
+	src := toNRGBA(img)
+	bounds := src.Bounds()
+
+	// 先水平后垂直，中间结果以预乘alpha的浮点数保存
+	horizontal := resample(bounds.Dx(), width)
+	vertical := resample(bounds.Dy(), height)
+
+	tmp := make([]float64, width*bounds.Dy()*4)
+	for y := 0; y < bounds.Dy(); y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, taps := range horizontal {
+			var r, g, b, a float64
+			for _, tap := range taps {
+				p := row[tap.index*4:]
+				alpha := float64(p[3]) * tap.weight
+				r += float64(p[0]) * alpha
+				g += float64(p[1]) * alpha
+				b += float64(p[2]) * alpha
+				a += alpha
+			}
+			offset := (y*width + x) * 4
+			tmp[offset], tmp[offset+1], tmp[offset+2], tmp[offset+3] = r, g, b, a
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y, taps := range vertical {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float64
+			for _, tap := range taps {
+				offset := (tap.index*width + x) * 4
+				r += tmp[offset] * tap.weight
+				g += tmp[offset+1] * tap.weight
+				b += tmp[offset+2] * tap.weight
+				a += tmp[offset+3] * tap.weight
+			}
+			p := dst.Pix[y*dst.Stride+x*4:]
+			if a > 0 {
+				p[0], p[1], p[2] = clamp(r/a), clamp(g/a), clamp(b/a)
+			}
+			p[3] = clamp(a)
+		}
+	}
+	return dst
+}
+
+// Encode 按格式编码图片，quality仅对JPEG有效（1-100）
+// JPEG不支持透明通道，透明区域以白色背景填充
+func Encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJPEG:
+		if quality < 1 || quality > 100 {
+			quality = jpeg.DefaultQuality
+		}
+		if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("编码JPEG失败: %w", err)
+		}
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("编码PNG失败: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	return buf.Bytes(), nil
+}
+
+// tap 滤波采样点
+type tap struct {
+	index  int
+	weight float64
+}
+
+// resample 计算一维缩放时每个目标像素的采样点与归一化权重
+func resample(srcSize, dstSize int) [][]tap {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(scale, 1)
+
+	taps := make([][]tap, dstSize)
+	for i := range taps {
+		center := (float64(i)+0.5)*scale - 0.5
+		first := int(math.Ceil(center - support))
+		last := int(math.Floor(center + support))
+
+		var total float64
+		for j := first; j <= last; j++ {
+			weight := 1 - math.Abs(float64(j)-center)/support
+			if weight <= 0 {
+				continue
+			}
+			index := min(max(j, 0), srcSize-1)
+			taps[i] = append(taps[i], tap{index: index, weight: weight})
+			total += weight
+		}
+		for j := range taps[i] {
+			taps[i][j].weight /= total
+		}
+	}
+	return taps
+}
+
+// toNRGBA 转换为从原点开始的NRGBA图片
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+// flatten 将带透明通道的图片合成到白色背景上
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Over)
+	return dst
+}
+
+// clamp 将浮点数取整并限制在0-255
+func clamp(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
@@ -0,0 +1,52 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// encodePNG 编码一张width×height的PNG，可选改写文件头中的宽高以模拟声明超大尺寸的小文件
+func encodePNG(t *testing.T, width, height int, declared ...uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("编码PNG失败: %v", err)
+	}
+	data := buf.Bytes()
+	if len(declared) == 2 {
+		// 签名8字节后为IHDR块：长度4字节、类型4字节、宽高各4字节，块末尾为CRC
+		binary.BigEndian.PutUint32(data[16:], declared[0])
+		binary.BigEndian.PutUint32(data[20:], declared[1])
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	}
+	return data
+}
+
+func TestDecodePixelLimit(t *testing.T) {
+	img, format, err := Decode(encodePNG(t, 40, 30), 40*30)
+	if err != nil || format != "png" || img.Bounds().Dx() != 40 {
+		t.Fatalf("Decode = %v, %s, %v", img, format, err)
+	}
+
+	if _, _, err := Decode(encodePNG(t, 40, 30), 40*30-1); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("超过像素上限 错误 = %v，期望 ErrImageTooLarge", err)
+	}
+
+	// 文件很小但声明30000×30000，须在分配像素缓冲前拒绝
+	bomb := encodePNG(t, 1, 1, 30000, 30000)
+	if _, _, err := Decode(bomb, 40<<20); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("声明超大尺寸 错误 = %v，期望 ErrImageTooLarge", err)
+	}
+
+	if _, _, err := Decode(encodePNG(t, 40, 30), 0); err != nil {
+		t.Errorf("不限制像素数时 Decode失败: %v", err)
+	}
+	if _, _, err := Decode([]byte("not an image"), 40<<20); err == nil {
+		t.Error("无效数据应返回错误")
+	}
+}
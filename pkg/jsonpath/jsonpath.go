@@ -0,0 +1,206 @@
+// Package jsonpath 简化的JSON路径查询
+//
+// 支持的语法：
+//   - 点号分隔的字段：$.data.list、data.list（$前缀可省略）
+//   - 数组下标：list[0]、[0]
+//   - 数组通配：list[*].name，返回所有元素对应字段组成的数组
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment 路径片段
+type segment struct {
+	key      string // 字段名，为空表示直接作用于当前值
+	index    int    // 数组下标
+	hasIndex bool   // 是否包含下标
+	wildcard bool   // 是否为数组通配
+}
+
+// Lookup 按路径查询JSON文档，doc应为json.Unmarshal得到的值
+// 路径为空或为$时返回文档本身，路径不存在时返回ok为false
+func Lookup(doc interface{}, path string) (interface{}, bool, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	values := []interface{}{doc}
+	wildcard := false
+	for _, seg := range segments {
+		next := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if seg.key != "" {
+				obj, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if value, ok = obj[seg.key]; !ok {
+					continue
+				}
+				if !seg.hasIndex && !seg.wildcard {
+					next = append(next, value)
+					continue
+				}
+			}
+
+			arr, ok := value.([]interface{})
+			if !ok {
+				continue
+			}
+			if seg.wildcard {
+				next = append(next, arr...)
+				continue
+			}
+			index := seg.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				next = append(next, arr[index])
+			}
+		}
+		if seg.wildcard {
+			wildcard = true
+		}
+		values = next
+	}
+
+	if wildcard {
+		return values, len(values) > 0, nil
+	}
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	return values[0], true, nil
+}
+
+// String 按路径查询并转换为字符串，数组元素以", "连接
+func String(doc interface{}, path string) (string, bool, error) {
+	value, ok, err := Lookup(doc, path)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	return ToString(value), true, nil
+}
+
+// ToString 将JSON值转换为字符串
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := ToString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// ToInt64 将JSON值转换为整数，无法转换时返回0
+func ToInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return int64(f)
+		}
+	case float64:
+		return int64(v)
+	case string:
+		if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return int64(f)
+		}
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+// parse 解析路径
+func parse(path string) ([]segment, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []segment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return nil, fmt.Errorf("无效的JSON路径: %s", path)
+		}
+
+		key := part
+		var brackets []string
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				if rest[0] != '[' {
+					return nil, fmt.Errorf("无效的JSON路径片段: %s", part)
+				}
+				end := strings.Index(rest, "]")
+				if end < 0 {
+					return nil, fmt.Errorf("JSON路径缺少右括号: %s", part)
+				}
+				brackets = append(brackets, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if len(brackets) == 0 {
+			segments = append(segments, segment{key: key})
+			continue
+		}
+
+		for i, b := range brackets {
+			seg := segment{}
+			if i == 0 {
+				seg.key = key
+			}
+			if b == "*" {
+				seg.wildcard = true
+			} else {
+				index, err := strconv.Atoi(b)
+				if err != nil {
+					return nil, fmt.Errorf("无效的数组下标: %s", b)
+				}
+				seg.index = index
+				seg.hasIndex = true
+			}
+			segments = append(segments, seg)
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,150 @@
+// Package lyrics LRC歌词解析与字幕导出
+//
+// 支持一行多个时间标签、[offset:]偏移、[ar:]等元数据标签与增强LRC的<mm:ss.xx>逐字时间，
+// 无法识别的行直接忽略；翻译歌词按时间对齐到原文歌词行，可导出为SRT、WebVTT与TTML字幕。
+package lyrics
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTolerance 对齐翻译歌词时允许的最大时间差
+const DefaultTolerance = 500 * time.Millisecond
+
+// Line 时间轴歌词行
+type Line struct {
+	Time        time.Duration // 开始时间，已应用偏移
+	Text        string        // 歌词文本，可以为空（表示间奏）
+	Translation string        // 翻译
+	Words       []Word        // 逐字时间，仅增强LRC歌词有
+}
+
+// Lyrics 解析后的歌词
+type Lyrics struct {
+	Metadata map[string]string // 元数据标签，键为小写，如 ti、ar、al、by
+	Offset   time.Duration     // [offset:]声明的偏移
+	Lines    []Line            // 按时间排序的歌词行
+}
+
+// timeTag 行首时间标签，如[01:23.45]、[01:23:45]、[01:23]
+var timeTag = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+
+// metaTag 元数据标签，如[ar:歌手]
+var metaTag = regexp.MustCompile(`^\[([A-Za-z#]+):(.*)\]$`)
+
+// Parse 解析LRC歌词
+func Parse(lrc string) *Lyrics {
+	result := &Lyrics{Metadata: make(map[string]string)}
+
+	for _, raw := range strings.Split(strings.ReplaceAll(lrc, "\r\n", "\n"), "\n") {
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff"))
+		if raw == "" {
+			continue
+		}
+
+		// 一行可以有多个连续的时间标签，共用同一句歌词
+		var times []time.Duration
+		rest := raw
+		for {
+			m := timeTag.FindStringSubmatch(rest)
+			if m == nil {
+				break
+			}
+			times = append(times, parseTimeTag(m[1], m[2], m[3]))
+			rest = rest[len(m[0]):]
+		}
+
+		if len(times) == 0 {
+			if m := metaTag.FindStringSubmatch(raw); m != nil {
+				key := strings.ToLower(m[1])
+				value := strings.TrimSpace(m[2])
+				result.Metadata[key] = value
+				if key == "offset" {
+					if ms, err := strconv.Atoi(value); err == nil {
+						result.Offset = time.Duration(ms) * time.Millisecond
+					}
+				}
+			}
+			continue
+		}
+
+		// 增强LRC的逐字时间是绝对时间，重复的时间标签按与第一个标签的差值平移
+		text, words := parseWords(times[0], strings.TrimSpace(rest))
+		for _, t := range times {
+			result.Lines = append(result.Lines, Line{
+				Time:  t,
+				Text:  strings.TrimSpace(text),
+				Words: shiftWords(words, t-times[0]),
+			})
+		}
+	}
+
+	// 正偏移使歌词提前显示
+	if result.Offset != 0 {
+		for i := range result.Lines {
+			result.Lines[i].Time = max(result.Lines[i].Time-result.Offset, 0)
+			result.Lines[i].Words = shiftWords(result.Lines[i].Words, -result.Offset)
+		}
+	}
+	sort.SliceStable(result.Lines, func(i, j int) bool { return result.Lines[i].Time < result.Lines[j].Time })
+	resolveWordEnds(result)
+	return result
+}
+
+// Merge 将翻译歌词按时间对齐到原文歌词行，时间差超过tolerance的翻译行被忽略
+func Merge(original, translation *Lyrics, tolerance time.Duration) *Lyrics {
+	if original == nil || translation == nil || len(original.Lines) == 0 {
+		return original
+	}
+
+	lines := original.Lines
+	for _, tl := range translation.Lines {
+		if tl.Text == "" || tl.Text == "//" {
+			continue // 网易云以"//"表示该行无翻译
+		}
+
+		// 找到时间最接近的原文行
+		i := sort.Search(len(lines), func(i int) bool { return lines[i].Time >= tl.Time })
+		best := -1
+		for _, candidate := range []int{i - 1, i} {
+			if candidate < 0 || candidate >= len(lines) || lines[candidate].Translation != "" {
+				continue
+			}
+			if best < 0 || absDuration(lines[candidate].Time-tl.Time) < absDuration(lines[best].Time-tl.Time) {
+				best = candidate
+			}
+		}
+		if best >= 0 && absDuration(lines[best].Time-tl.Time) <= tolerance {
+			lines[best].Translation = tl.Text
+		}
+	}
+	return original
+}
+
+// parseTimeTag 解析时间标签的分、秒与小数部分
+func parseTimeTag(minutes, seconds, fraction string) time.Duration {
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	t := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if fraction != "" {
+		// 小数部分按位数换算：.5为500毫秒，.45为450毫秒，.456为456毫秒
+		ms, _ := strconv.Atoi(fraction)
+		for i := len(fraction); i < 3; i++ {
+			ms *= 10
+		}
+		t += time.Duration(ms) * time.Millisecond
+	}
+	return t
+}
+
+// absDuration 时间差的绝对值
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
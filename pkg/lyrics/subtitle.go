@@ -0,0 +1,221 @@
+package lyrics
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 字幕格式
+const (
+	FormatSRT  = "srt"  // SubRip
+	FormatVTT  = "vtt"  // WebVTT
+	FormatTTML = "ttml" // Timed Text Markup Language
+)
+
+// 字幕时长
+const (
+	LastCueDuration = 5 * time.Second  // 最后一行歌词的显示时长
+	MaxCueDuration  = 10 * time.Second // 单行歌词的最长显示时长，避免长间奏期间一直显示上一句
+)
+
+// TranslationMode 翻译在字幕中的输出方式
+type TranslationMode string
+
+const (
+	TranslationInline TranslationMode = "inline" // 翻译作为同一字幕的第二行
+	TranslationNone   TranslationMode = "none"   // 不输出翻译
+	TranslationOnly   TranslationMode = "only"   // 只输出翻译，可作为单独的字幕轨道
+)
+
+// Cue 带起止时间的一条字幕
+type Cue struct {
+	Start time.Duration // 开始时间
+	End   time.Duration // 结束时间
+	Lines []string      // 字幕文本，每项一行
+	Words []Word        // 第一行的逐字时间，WebVTT输出为行内时间标签
+}
+
+// ContentType 获取字幕格式的Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatSRT:
+		return "application/x-subrip; charset=utf-8"
+	case FormatVTT:
+		return "text/vtt; charset=utf-8"
+	case FormatTTML:
+		return "application/ttml+xml; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Shift 将所有歌词行整体平移，正值推迟显示，平移后早于0的时间取0
+func (l *Lyrics) Shift(offset time.Duration) {
+	if offset == 0 {
+		return
+	}
+	for i := range l.Lines {
+		l.Lines[i].Time = max(l.Lines[i].Time+offset, 0)
+		l.Lines[i].Words = shiftWords(l.Lines[i].Words, offset)
+	}
+}
+
+// Cues 将歌词行转换为字幕，结束时间取下一行的开始时间
+// 空行（间奏）只用于结束上一句，不生成字幕；单句时长不超过MaxCueDuration
+func Cues(l *Lyrics, mode TranslationMode) []Cue {
+	if l == nil {
+		return nil
+	}
+
+	cues := make([]Cue, 0, len(l.Lines))
+	for i, line := range l.Lines {
+		var text []string
+		var words []Word
+		switch mode {
+		case TranslationOnly:
+			text = appendNonEmpty(text, line.Translation)
+		case TranslationNone:
+			text = appendNonEmpty(text, line.Text)
+			words = line.Words
+		default:
+			text = appendNonEmpty(text, line.Text)
+			text = appendNonEmpty(text, line.Translation)
+			words = line.Words
+		}
+		if len(text) == 0 {
+			continue
+		}
+
+		end := lineEnd(l, i)
+		if len(words) > 0 {
+			end = max(end, words[len(words)-1].End)
+		}
+		cues = append(cues, Cue{
+			Start: line.Time,
+			End:   end,
+			Lines: text,
+			Words: words,
+		})
+	}
+	return cues
+}
+
+// Encode 按格式输出字幕
+func Encode(cues []Cue, format string) ([]byte, error) {
+	switch format {
+	case FormatSRT:
+		return EncodeSRT(cues), nil
+	case FormatVTT:
+		return EncodeVTT(cues), nil
+	case FormatTTML:
+		return EncodeTTML(cues), nil
+	default:
+		return nil, fmt.Errorf("不支持的字幕格式: %s", format)
+	}
+}
+
+// EncodeSRT 输出SubRip字幕
+func EncodeSRT(cues []Cue) []byte {
+	var buf bytes.Buffer
+	for i, cue := range cues {
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, ','), formatTimestamp(cue.End, ','))
+		for _, line := range cue.Lines {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// EncodeVTT 输出WebVTT字幕
+func EncodeVTT(cues []Cue) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&buf, "%s --> %s\n", formatTimestamp(cue.Start, '.'), formatTimestamp(cue.End, '.'))
+		for i, line := range cue.Lines {
+			if i == 0 && len(cue.Words) > 0 {
+				line = vttKaraoke(cue)
+			} else {
+				line = vttEscaper.Replace(line)
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// vttKaraoke 输出带行内时间标签的字幕文本，如 Hello <00:00:12.500>world
+// WebVTT要求行内时间严格位于字幕起止时间之间，范围外的标签省略
+func vttKaraoke(cue Cue) string {
+	var b strings.Builder
+	for i, w := range cue.Words {
+		text := w.Text
+		if i == 0 {
+			text = strings.TrimLeft(text, " ")
+		}
+		if i == len(cue.Words)-1 {
+			text = strings.TrimRight(text, " ")
+		}
+		if w.Time > cue.Start && w.Time < cue.End {
+			b.WriteString("<" + formatTimestamp(w.Time, '.') + ">")
+		}
+		b.WriteString(vttEscaper.Replace(text))
+	}
+	return b.String()
+}
+
+// EncodeTTML 输出TTML字幕，多行文本以<br/>分隔
+func EncodeTTML(cues []Cue) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml">` + "\n  <body>\n    <div>\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&buf, `      <p begin="%s" end="%s">`, formatTimestamp(cue.Start, '.'), formatTimestamp(cue.End, '.'))
+		for i, line := range cue.Lines {
+			if i > 0 {
+				buf.WriteString("<br/>")
+			}
+			xml.EscapeText(&buf, []byte(line))
+		}
+		buf.WriteString("</p>\n")
+	}
+	buf.WriteString("    </div>\n  </body>\n</tt>\n")
+	return buf.Bytes()
+}
+
+// lineEnd 计算第i行的结束时间：取下一行的开始时间，最后一行显示LastCueDuration，最长MaxCueDuration
+func lineEnd(l *Lyrics, i int) time.Duration {
+	start := l.Lines[i].Time
+	end := start + LastCueDuration
+	for _, next := range l.Lines[i+1:] {
+		if next.Time > start {
+			end = next.Time
+			break
+		}
+	}
+	return min(end, start+MaxCueDuration)
+}
+
+// vttEscaper 转义WebVTT字幕文本中的特殊字符
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// formatTimestamp 格式化为hh:mm:ss加毫秒，sep为毫秒分隔符（SRT为逗号，WebVTT与TTML为点）
+func formatTimestamp(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// appendNonEmpty 追加非空文本
+func appendNonEmpty(lines []string, text string) []string {
+	if text = strings.TrimSpace(text); text != "" {
+		lines = append(lines, text)
+	}
+	return lines
+}
@@ -0,0 +1,125 @@
+package lyrics
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Word 逐字歌词（增强LRC）中的一个字或词
+type Word struct {
+	Time time.Duration // 开始时间，已应用偏移
+	End  time.Duration // 结束时间
+	Text string        // 文本，保留词间空格
+}
+
+// KaraokeLine 卡拉OK模式的歌词行，每行都带逐字时间
+type KaraokeLine struct {
+	Start       time.Duration // 开始时间
+	End         time.Duration // 结束时间
+	Text        string        // 歌词文本
+	Translation string        // 翻译
+	Words       []Word        // 逐字时间，原歌词没有逐字时间时整行作为一个词
+}
+
+// wordTag 行内逐字时间标签，如<01:23.45>
+var wordTag = regexp.MustCompile(`<(\d+):(\d{1,2})(?:[.:](\d{1,3}))?>`)
+
+// parseWords 解析增强LRC行内的逐字时间标签，返回去掉标签的文本与逐字时间
+// 第一个标签之前的文本从行开始时间算起；只有结束作用的末尾标签不产生词
+func parseWords(start time.Duration, text string) (string, []Word) {
+	matches := wordTag.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text, nil
+	}
+
+	var words []Word
+	var plain strings.Builder
+	add := func(t time.Duration, segment string) {
+		plain.WriteString(segment)
+		if n := len(words); n > 0 && words[n-1].End == 0 {
+			words[n-1].End = t
+		}
+		if segment != "" {
+			words = append(words, Word{Time: t, Text: segment})
+		}
+	}
+
+	add(start, text[:matches[0][0]])
+	for i, m := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		t := parseTimeTag(text[m[2]:m[3]], text[m[4]:m[5]], submatch(text, m, 6))
+		add(t, text[m[1]:end])
+	}
+	return plain.String(), words
+}
+
+// resolveWordEnds 补全每行最后一个词的结束时间，取该行的结束时间
+func resolveWordEnds(l *Lyrics) {
+	for i := range l.Lines {
+		words := l.Lines[i].Words
+		if n := len(words); n > 0 && words[n-1].End == 0 {
+			words[n-1].End = max(lineEnd(l, i), words[n-1].Time)
+		}
+	}
+}
+
+// shiftWords 将逐字时间整体平移，早于0的时间取0
+func shiftWords(words []Word, offset time.Duration) []Word {
+	if len(words) == 0 {
+		return words
+	}
+	shifted := make([]Word, len(words))
+	for i, w := range words {
+		shifted[i] = Word{Time: max(w.Time+offset, 0), End: max(w.End+offset, 0), Text: w.Text}
+	}
+	return shifted
+}
+
+// HasWordTimings 判断歌词是否带逐字时间
+func (l *Lyrics) HasWordTimings() bool {
+	for _, line := range l.Lines {
+		if len(line.Words) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Karaoke 将歌词转换为卡拉OK模式，行结束时间的计算与字幕相同，空行不输出
+func Karaoke(l *Lyrics) []KaraokeLine {
+	if l == nil {
+		return nil
+	}
+
+	lines := make([]KaraokeLine, 0, len(l.Lines))
+	for i, line := range l.Lines {
+		if line.Text == "" {
+			continue
+		}
+		end := lineEnd(l, i)
+		words := line.Words
+		if len(words) == 0 {
+			words = []Word{{Time: line.Time, End: end, Text: line.Text}}
+		}
+		lines = append(lines, KaraokeLine{
+			Start:       line.Time,
+			End:         max(end, words[len(words)-1].End),
+			Text:        line.Text,
+			Translation: line.Translation,
+			Words:       words,
+		})
+	}
+	return lines
+}
+
+// submatch 获取可选子匹配，未匹配时为空
+func submatch(s string, m []int, i int) string {
+	if m[i] < 0 {
+		return ""
+	}
+	return s[m[i]:m[i+1]]
+}
@@ -23,6 +23,12 @@ type Metrics struct {
 	musicRequestDuration *prometheus.HistogramVec
 	musicSourcesTotal    *prometheus.CounterVec
 	musicCacheHits       *prometheus.CounterVec
+	matchAttemptsTotal   *prometheus.CounterVec
+	matchAttemptDuration *prometheus.HistogramVec
+
+	// 音源熔断器指标
+	circuitState         *prometheus.GaugeVec
+	circuitRejections    *prometheus.CounterVec
 
 	// 系统指标
 	systemInfo           *prometheus.GaugeVec
@@ -105,6 +111,37 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"type"},
 		),
+		matchAttemptsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "unm_music_match_attempts_total",
+				Help: "音源匹配尝试统计",
+			},
+			[]string{"strategy", "source", "outcome"},
+		),
+		matchAttemptDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "unm_music_match_attempt_duration_seconds",
+				Help:    "音源匹配尝试持续时间",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"strategy", "source", "outcome"},
+		),
+
+		// 音源熔断器指标
+		circuitState: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "unm_source_circuit_state",
+				Help: "音源熔断器状态（0=closed，1=half_open，2=open）",
+			},
+			[]string{"source"},
+		),
+		circuitRejections: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "unm_source_circuit_rejections_total",
+				Help: "音源熔断器拒绝请求统计",
+			},
+			[]string{"source"},
+		),
 
 		// 系统指标
 		systemInfo: promauto.NewGaugeVec(
@@ -207,6 +244,34 @@ func (m *Metrics) RecordMusicCacheHit(cacheType string) {
 	m.musicCacheHits.WithLabelValues(cacheType).Inc()
 }
 
+// RecordMatchAttempt 记录音源匹配尝试指标，outcome为won、failed、rejected、quality_mismatch、lost或cancelled
+func (m *Metrics) RecordMatchAttempt(strategy, source, outcome string, duration time.Duration) {
+	if m == nil || m.matchAttemptsTotal == nil {
+		return
+	}
+
+	m.matchAttemptsTotal.WithLabelValues(strategy, source, outcome).Inc()
+	if m.matchAttemptDuration != nil {
+		m.matchAttemptDuration.WithLabelValues(strategy, source, outcome).Observe(duration.Seconds())
+	}
+}
+
+// SetCircuitState 设置音源熔断器状态指标
+func (m *Metrics) SetCircuitState(source string, state float64) {
+	if m == nil || m.circuitState == nil {
+		return
+	}
+	m.circuitState.WithLabelValues(source).Set(state)
+}
+
+// RecordCircuitRejection 记录音源熔断器拒绝的请求
+func (m *Metrics) RecordCircuitRejection(source string) {
+	if m == nil || m.circuitRejections == nil {
+		return
+	}
+	m.circuitRejections.WithLabelValues(source).Inc()
+}
+
 // SetSystemInfo 设置系统信息指标
 func (m *Metrics) SetSystemInfo(version, goVersion, buildTime string) {
 	m.systemInfo.WithLabelValues(version, goVersion, buildTime).Set(1)
@@ -290,6 +355,18 @@ func RecordMusicCacheHit(cacheType string) {
 	GetDefault().RecordMusicCacheHit(cacheType)
 }
 
+func RecordMatchAttempt(strategy, source, outcome string, duration time.Duration) {
+	GetDefault().RecordMatchAttempt(strategy, source, outcome, duration)
+}
+
+func SetCircuitState(source string, state float64) {
+	GetDefault().SetCircuitState(source, state)
+}
+
+func RecordCircuitRejection(source string) {
+	GetDefault().RecordCircuitRejection(source)
+}
+
 func SetSystemInfo(version, goVersion, buildTime string) {
 	GetDefault().SetSystemInfo(version, goVersion, buildTime)
 }
@@ -0,0 +1,241 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed 客户端已关闭
+var ErrClosed = errors.New("RESP客户端已关闭")
+
+// Options 客户端配置
+type Options struct {
+	Addr         string        // 服务器地址，host:port
+	Username     string        // ACL用户名，为空时只使用密码认证
+	Password     string        // 密码，为空时不认证
+	DB           int           // 数据库编号
+	PoolSize     int           // 最大连接数，默认10
+	DialTimeout  time.Duration // 建立连接超时，默认5秒
+	ReadTimeout  time.Duration // 读取应答超时，默认3秒
+	WriteTimeout time.Duration // 写入命令超时，默认3秒
+}
+
+// PoolStats 连接池统计
+type PoolStats struct {
+	Open int `json:"open"` // 已建立的连接数
+	Idle int `json:"idle"` // 空闲连接数
+}
+
+// Client RESP客户端，可被多个goroutine并发使用
+type Client struct {
+	opts   Options
+	slots  chan struct{} // 已建立连接的占位，容量即连接池上限
+	idle   chan *conn    // 空闲连接
+	closed atomic.Bool
+}
+
+// conn 池中的一条连接
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+// NewClient 创建客户端，连接在首次使用时建立
+func NewClient(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = 3 * time.Second
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = 3 * time.Second
+	}
+
+	return &Client{
+		opts:  opts,
+		slots: make(chan struct{}, opts.PoolSize),
+		idle:  make(chan *conn, opts.PoolSize),
+	}
+}
+
+// Do 执行命令，服务器返回错误应答时以Error类型返回
+// 网络或协议错误会关闭所用连接，错误应答不影响连接复用；
+// 复用的空闲连接可能已被服务器按空闲超时关闭，此时在新连接上重试一次
+func (c *Client) Do(ctx context.Context, args ...string) (Value, error) {
+	cn, reused, err := c.get(ctx)
+	if err != nil {
+		return Value{}, err
+	}
+
+	v, err := cn.do(ctx, c.opts, args)
+	if err != nil && reused && ctx.Err() == nil {
+		c.discard(cn)
+		if cn, err = c.dialSlot(ctx); err != nil {
+			return Value{}, err
+		}
+		v, err = cn.do(ctx, c.opts, args)
+	}
+	if err != nil {
+		c.discard(cn)
+		return Value{}, err
+	}
+	c.put(cn)
+
+	if v.Type == TypeError {
+		return v, Error(v.Str)
+	}
+	return v, nil
+}
+
+// Ping 检查服务器是否可用
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Stats 获取连接池统计
+func (c *Client) Stats() PoolStats {
+	return PoolStats{Open: len(c.slots), Idle: len(c.idle)}
+}
+
+// Close 关闭客户端与所有空闲连接，使用中的连接归还时关闭
+func (c *Client) Close() error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+	for {
+		select {
+		case cn := <-c.idle:
+			c.discard(cn)
+		default:
+			return nil
+		}
+	}
+}
+
+// get 获取连接：优先复用空闲连接，未达上限时新建，否则等待其他请求归还，reused表示是否为复用的连接
+func (c *Client) get(ctx context.Context) (cn *conn, reused bool, err error) {
+	if c.closed.Load() {
+		return nil, false, ErrClosed
+	}
+
+	select {
+	case cn := <-c.idle:
+		return cn, true, nil
+	default:
+	}
+
+	select {
+	case cn := <-c.idle:
+		return cn, true, nil
+	case c.slots <- struct{}{}:
+		cn, err := c.dial(ctx)
+		if err != nil {
+			<-c.slots
+			return nil, false, err
+		}
+		return cn, false, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// dialSlot 等待连接占位并新建连接，不复用空闲连接
+func (c *Client) dialSlot(ctx context.Context) (*conn, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	cn, err := c.dial(ctx)
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+	return cn, nil
+}
+
+// put 归还连接
+func (c *Client) put(cn *conn) {
+	if c.closed.Load() {
+		c.discard(cn)
+		return
+	}
+	c.idle <- cn
+}
+
+// discard 关闭连接并释放占位
+func (c *Client) discard(cn *conn) {
+	cn.netConn.Close()
+	<-c.slots
+}
+
+// dial 建立连接并完成认证与选库
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.opts.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+
+	var setup [][]string
+	if c.opts.Password != "" {
+		if c.opts.Username != "" {
+			setup = append(setup, []string{"AUTH", c.opts.Username, c.opts.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.opts.Password})
+		}
+	}
+	if c.opts.DB != 0 {
+		setup = append(setup, []string{"SELECT", fmt.Sprint(c.opts.DB)})
+	}
+	for _, args := range setup {
+		v, err := cn.do(ctx, c.opts, args)
+		if err == nil && v.Type == TypeError {
+			err = Error(v.Str)
+		}
+		if err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("%s失败: %w", args[0], err)
+		}
+	}
+	return cn, nil
+}
+
+// do 在连接上执行一条命令，超时取配置与ctx截止时间中较早者
+func (cn *conn) do(ctx context.Context, opts Options, args []string) (Value, error) {
+	if err := cn.netConn.SetWriteDeadline(deadline(ctx, opts.WriteTimeout)); err != nil {
+		return Value{}, err
+	}
+	if err := writeCommand(cn.writer, args); err != nil {
+		return Value{}, err
+	}
+	if err := cn.netConn.SetReadDeadline(deadline(ctx, opts.ReadTimeout)); err != nil {
+		return Value{}, err
+	}
+	return readValue(cn.reader)
+}
+
+// deadline 计算IO截止时间
+func deadline(ctx context.Context, timeout time.Duration) time.Time {
+	d := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(d) {
+		return ctxDeadline
+	}
+	return d
+}
@@ -0,0 +1,165 @@
+// Package resp Redis协议（RESP2）客户端与内存替身服务器
+//
+// 客户端维护固定上限的连接池，每条命令按配置设置读写超时，服务器返回的错误应答以Error类型返回且不影响连接复用；
+// Server实现缓存仓库用到的命令子集，可在没有redis-server的环境中离线测试。
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// 应答类型
+const (
+	TypeSimpleString = '+'
+	TypeError        = '-'
+	TypeInteger      = ':'
+	TypeBulkString   = '$'
+	TypeArray        = '*'
+)
+
+// maxBulkLength 单个批量字符串的最大长度，与Redis的proto-max-bulk-len默认值一致
+const maxBulkLength = 512 << 20
+
+// ErrProtocol 应答不符合协议
+var ErrProtocol = errors.New("RESP协议错误")
+
+// Error 服务器返回的错误应答
+type Error string
+
+// Error 实现error接口
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Value 一个RESP应答
+type Value struct {
+	Type  byte    // 应答类型
+	Str   string  // 简单字符串、错误与批量字符串的内容
+	Int   int64   // 整数
+	Array []Value // 数组元素
+	Null  bool    // 是否为空批量字符串或空数组
+}
+
+// String 获取字符串内容，整数转换为十进制
+func (v Value) String() string {
+	if v.Type == TypeInteger {
+		return strconv.FormatInt(v.Int, 10)
+	}
+	return v.Str
+}
+
+// writeCommand 以批量字符串数组的形式写入命令
+func writeCommand(w *bufio.Writer, args []string) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(w, "$%d\r\n", len(arg))
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+	return w.Flush()
+}
+
+// writeValue 写入应答，用于替身服务器
+func writeValue(w *bufio.Writer, v Value) {
+	switch v.Type {
+	case TypeSimpleString, TypeError:
+		w.WriteByte(v.Type)
+		w.WriteString(v.Str)
+		w.WriteString("\r\n")
+	case TypeInteger:
+		fmt.Fprintf(w, ":%d\r\n", v.Int)
+	case TypeBulkString:
+		if v.Null {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v.Str), v.Str)
+	case TypeArray:
+		if v.Null {
+			w.WriteString("*-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "*%d\r\n", len(v.Array))
+		for _, item := range v.Array {
+			writeValue(w, item)
+		}
+	}
+}
+
+// readValue 读取一个应答
+func readValue(r *bufio.Reader) (Value, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, fmt.Errorf("%w: 空应答行", ErrProtocol)
+	}
+
+	v := Value{Type: line[0]}
+	body := string(line[1:])
+	switch v.Type {
+	case TypeSimpleString, TypeError:
+		v.Str = body
+	case TypeInteger:
+		if v.Int, err = strconv.ParseInt(body, 10, 64); err != nil {
+			return Value{}, fmt.Errorf("%w: 无效的整数 %q", ErrProtocol, body)
+		}
+	case TypeBulkString:
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 || n > maxBulkLength {
+			return Value{}, fmt.Errorf("%w: 无效的批量字符串长度 %q", ErrProtocol, body)
+		}
+		if n == -1 {
+			v.Null = true
+			return v, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return Value{}, err
+		}
+		if data[n] != '\r' || data[n+1] != '\n' {
+			return Value{}, fmt.Errorf("%w: 批量字符串缺少结束符", ErrProtocol)
+		}
+		v.Str = string(data[:n])
+	case TypeArray:
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return Value{}, fmt.Errorf("%w: 无效的数组长度 %q", ErrProtocol, body)
+		}
+		if n == -1 {
+			v.Null = true
+			return v, nil
+		}
+		v.Array = make([]Value, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readValue(r)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Array = append(v.Array, item)
+		}
+	default:
+		return Value{}, fmt.Errorf("%w: 未知的应答类型 %q", ErrProtocol, v.Type)
+	}
+	return v, nil
+}
+
+// readLine 读取以CRLF结尾的一行，不含结束符
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("%w: 应答行过长", ErrProtocol)
+		}
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("%w: 应答行缺少CRLF", ErrProtocol)
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,458 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server 内存RESP替身服务器，实现字符串键的读写、过期与遍历命令，不支持其他数据类型
+type Server struct {
+	password string
+	listener net.Listener
+
+	mu    sync.Mutex
+	data  map[string]serverEntry
+	conns map[net.Conn]struct{}
+
+	wg sync.WaitGroup
+}
+
+// serverEntry 替身服务器中的一个键
+type serverEntry struct {
+	value     string
+	expiresAt time.Time // 零值表示永不过期
+}
+
+// NewServer 创建替身服务器，password非空时要求客户端先认证
+func NewServer(password string) *Server {
+	return &Server{
+		password: password,
+		data:     make(map[string]serverEntry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// NewTestServer 创建并在127.0.0.1的随机端口启动不需要认证的替身服务器
+func NewTestServer() *Server {
+	s := NewServer("")
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		panic(fmt.Sprintf("resp: 启动替身服务器失败: %v", err))
+	}
+	return s
+}
+
+// Start 监听地址并在后台处理连接
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[netConn] = struct{}{}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(netConn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Addr 获取监听地址
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close 停止监听并断开所有连接
+func (s *Server) Close() error {
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	s.mu.Lock()
+	for netConn := range s.conns {
+		netConn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// serve 处理一条连接上的命令
+func (s *Server) serve(netConn net.Conn) {
+	defer func() {
+		netConn.Close()
+		s.mu.Lock()
+		delete(s.conns, netConn)
+		s.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(netConn)
+	writer := bufio.NewWriter(netConn)
+	authenticated := s.password == ""
+	for {
+		request, err := readValue(reader)
+		if err != nil {
+			return
+		}
+		args, err := commandArgs(request)
+		if err != nil {
+			writeValue(writer, errorValue("ERR "+err.Error()))
+			writer.Flush()
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		var reply Value
+		switch {
+		case name == "AUTH":
+			reply = s.auth(args[1:], &authenticated)
+		case !authenticated:
+			reply = errorValue("NOAUTH Authentication required.")
+		case name == "QUIT":
+			writeValue(writer, Value{Type: TypeSimpleString, Str: "OK"})
+			writer.Flush()
+			return
+		default:
+			reply = s.execute(name, args[1:])
+		}
+		writeValue(writer, reply)
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// auth 处理AUTH命令，兼容只带密码与带用户名两种形式
+func (s *Server) auth(args []string, authenticated *bool) Value {
+	if len(args) == 0 || len(args) > 2 {
+		return wrongArgs("auth")
+	}
+	if s.password == "" {
+		return errorValue("ERR AUTH <password> called without any password configured for the default user.")
+	}
+	if args[len(args)-1] != s.password {
+		return errorValue("WRONGPASS invalid username-password pair or user is disabled.")
+	}
+	*authenticated = true
+	return Value{Type: TypeSimpleString, Str: "OK"}
+}
+
+// execute 执行数据命令
+func (s *Server) execute(name string, args []string) Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+
+	switch name {
+	case "PING":
+		if len(args) > 0 {
+			return bulkValue(args[0])
+		}
+		return Value{Type: TypeSimpleString, Str: "PONG"}
+	case "SELECT":
+		if len(args) != 1 {
+			return wrongArgs("select")
+		}
+		return Value{Type: TypeSimpleString, Str: "OK"}
+	case "GET":
+		if len(args) != 1 {
+			return wrongArgs("get")
+		}
+		entry, ok := s.lookup(args[0], now)
+		if !ok {
+			return Value{Type: TypeBulkString, Null: true}
+		}
+		return bulkValue(entry.value)
+	case "SET":
+		return s.set(args, now)
+	case "DEL", "UNLINK":
+		if len(args) == 0 {
+			return wrongArgs(strings.ToLower(name))
+		}
+		var deleted int64
+		for _, key := range args {
+			if _, ok := s.lookup(key, now); ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return intValue(deleted)
+	case "EXISTS":
+		if len(args) == 0 {
+			return wrongArgs("exists")
+		}
+		var found int64
+		for _, key := range args {
+			if _, ok := s.lookup(key, now); ok {
+				found++
+			}
+		}
+		return intValue(found)
+	case "PTTL", "TTL":
+		if len(args) != 1 {
+			return wrongArgs(strings.ToLower(name))
+		}
+		entry, ok := s.lookup(args[0], now)
+		switch {
+		case !ok:
+			return intValue(-2)
+		case entry.expiresAt.IsZero():
+			return intValue(-1)
+		case name == "TTL":
+			return intValue(int64(entry.expiresAt.Sub(now).Round(time.Second) / time.Second))
+		default:
+			return intValue(entry.expiresAt.Sub(now).Milliseconds())
+		}
+	case "PEXPIRE", "EXPIRE":
+		if len(args) != 2 {
+			return wrongArgs(strings.ToLower(name))
+		}
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errorValue("ERR value is not an integer or out of range")
+		}
+		entry, ok := s.lookup(args[0], now)
+		if !ok {
+			return intValue(0)
+		}
+		unit := time.Millisecond
+		if name == "EXPIRE" {
+			unit = time.Second
+		}
+		if n <= 0 {
+			delete(s.data, args[0])
+			return intValue(1)
+		}
+		entry.expiresAt = now.Add(time.Duration(n) * unit)
+		s.data[args[0]] = entry
+		return intValue(1)
+	case "SCAN":
+		return s.scan(args, now)
+	case "DBSIZE":
+		var count int64
+		for key := range s.data {
+			if _, ok := s.lookup(key, now); ok {
+				count++
+			}
+		}
+		return intValue(count)
+	case "FLUSHDB", "FLUSHALL":
+		s.data = make(map[string]serverEntry)
+		return Value{Type: TypeSimpleString, Str: "OK"}
+	case "INFO":
+		return bulkValue(fmt.Sprintf("# Server\r\nredis_version:7.0.0-standin\r\n# Keyspace\r\ndb0:keys=%d\r\n", len(s.data)))
+	default:
+		return errorValue(fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+	}
+}
+
+// set 处理SET命令，支持EX、PX过期选项
+func (s *Server) set(args []string, now time.Time) Value {
+	if len(args) < 2 {
+		return wrongArgs("set")
+	}
+	entry := serverEntry{value: args[1]}
+	for i := 2; i < len(args); i++ {
+		option := strings.ToUpper(args[i])
+		if (option != "EX" && option != "PX") || i+1 >= len(args) {
+			return errorValue("ERR syntax error")
+		}
+		n, err := strconv.ParseInt(args[i+1], 10, 64)
+		if err != nil || n <= 0 {
+			return errorValue("ERR invalid expire time in 'set' command")
+		}
+		unit := time.Millisecond
+		if option == "EX" {
+			unit = time.Second
+		}
+		entry.expiresAt = now.Add(time.Duration(n) * unit)
+		i++
+	}
+	s.data[args[0]] = entry
+	return Value{Type: TypeSimpleString, Str: "OK"}
+}
+
+// scan 处理SCAN命令，游标为按键排序后的偏移量
+func (s *Server) scan(args []string, now time.Time) Value {
+	if len(args) == 0 {
+		return wrongArgs("scan")
+	}
+	cursor, err := strconv.Atoi(args[0])
+	if err != nil || cursor < 0 {
+		return errorValue("ERR invalid cursor")
+	}
+	pattern, count := "*", 10
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			if count, err = strconv.Atoi(args[i+1]); err != nil || count <= 0 {
+				return errorValue("ERR syntax error")
+			}
+		default:
+			return errorValue("ERR syntax error")
+		}
+	}
+
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		if _, ok := s.lookup(key, now); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	end := min(cursor+count, len(keys))
+	matched := []Value{}
+	for _, key := range keys[min(cursor, len(keys)):end] {
+		if Match(pattern, key) {
+			matched = append(matched, bulkValue(key))
+		}
+	}
+	next := end
+	if next >= len(keys) {
+		next = 0
+	}
+	return Value{Type: TypeArray, Array: []Value{bulkValue(strconv.Itoa(next)), {Type: TypeArray, Array: matched}}}
+}
+
+// lookup 查找未过期的键，顺带删除已过期的键，调用方需持有锁
+func (s *Server) lookup(key string, now time.Time) (serverEntry, bool) {
+	entry, ok := s.data[key]
+	if !ok {
+		return entry, false
+	}
+	if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+		delete(s.data, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+// Match 按Redis的glob规则匹配键，支持*、?、[...]与反斜杠转义
+func Match(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if Match(pattern, key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if key == "" {
+				return false
+			}
+			pattern, key = pattern[1:], key[1:]
+		case '[':
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 || key == "" {
+				return false
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			if matchClass(class, key[0]) == negate {
+				return false
+			}
+			pattern, key = pattern[end+2:], key[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if key == "" || key[0] != pattern[0] {
+				return false
+			}
+			pattern, key = pattern[1:], key[1:]
+		}
+	}
+	return key == ""
+}
+
+// matchClass 判断字符是否属于[...]字符集，支持a-z形式的范围
+func matchClass(class string, c byte) bool {
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			if class[i] <= c && c <= class[i+2] {
+				return true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// commandArgs 将请求数组转换为命令参数
+func commandArgs(request Value) ([]string, error) {
+	if request.Type != TypeArray || len(request.Array) == 0 {
+		return nil, errors.New("Protocol error: expected array of bulk strings")
+	}
+	args := make([]string, 0, len(request.Array))
+	for _, item := range request.Array {
+		if item.Type != TypeBulkString || item.Null {
+			return nil, errors.New("Protocol error: expected bulk string")
+		}
+		args = append(args, item.Str)
+	}
+	return args, nil
+}
+
+// bulkValue 构造批量字符串应答
+func bulkValue(s string) Value {
+	return Value{Type: TypeBulkString, Str: s}
+}
+
+// intValue 构造整数应答
+func intValue(n int64) Value {
+	return Value{Type: TypeInteger, Int: n}
+}
+
+// errorValue 构造错误应答
+func errorValue(msg string) Value {
+	return Value{Type: TypeError, Str: msg}
+}
+
+// wrongArgs 构造参数个数错误应答
+func wrongArgs(command string) Value {
+	return errorValue(fmt.Sprintf("ERR wrong number of arguments for '%s' command", command))
+}
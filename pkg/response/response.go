@@ -60,7 +60,7 @@ func Error(c *gin.Context, err interface{}) {
 			httpCode = http.StatusBadRequest
 		case errors.CodeUnauthorized, errors.CodeAuthFailed, errors.CodeTokenInvalid, errors.CodeTokenExpired:
 			httpCode = http.StatusUnauthorized
-		case errors.CodeForbidden, errors.CodePermissionDenied:
+		case errors.CodeForbidden, errors.CodePermissionDenied, errors.CodeLinkSignatureInvalid, errors.CodeLinkExpired:
 			httpCode = http.StatusForbidden
 		case errors.CodeNotFound, errors.CodeMusicNotFound:
 			httpCode = http.StatusNotFound
@@ -0,0 +1,120 @@
+// Package singleflight 合并相同键的并发调用
+//
+// 同一键同时只有一次调用在执行，其余调用者等待并共享其结果或错误。
+// 调用在独立的goroutine中以脱离调用方取消信号的ctx执行（保留ctx中的值），
+// 任一调用者取消只会使其自身提前返回；所有调用者都放弃等待后才取消执行中的调用。
+package singleflight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// call 执行中的调用
+type call[T any] struct {
+	done    chan struct{}
+	value   T
+	err     error
+	waiters int // 仍在等待结果的调用者数，由Group.mu保护
+	dups    int // 加入等待的后续调用者数，由Group.mu保护
+	cancel  context.CancelFunc
+}
+
+// Group 调用合并组，零值可用
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+
+	// 统计信息
+	executions atomic.Int64
+	shared     atomic.Int64
+}
+
+// Stats 调用合并统计
+type Stats struct {
+	Executions int64 `json:"executions"` // 实际执行的调用次数
+	Shared     int64 `json:"shared"`     // 通过等待其他调用获得结果的次数
+	InFlight   int   `json:"in_flight"`  // 当前执行中的调用数
+}
+
+// Do 执行fn并返回结果，key相同的调用正在执行时等待其结果；shared表示结果是否与其他调用者共享
+// 共享的结果不应被调用者修改
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (value T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		c.dups++
+		g.mu.Unlock()
+		g.shared.Add(1)
+		return g.wait(ctx, key, c, true)
+	}
+
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &call[T]{done: make(chan struct{}), waiters: 1, cancel: cancel}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.executions.Add(1)
+	go g.run(callCtx, key, c, fn)
+	return g.wait(ctx, key, c, false)
+}
+
+// Stats 获取统计
+func (g *Group[T]) Stats() Stats {
+	g.mu.Lock()
+	inFlight := len(g.calls)
+	g.mu.Unlock()
+
+	return Stats{
+		Executions: g.executions.Load(),
+		Shared:     g.shared.Load(),
+		InFlight:   inFlight,
+	}
+}
+
+// run 执行调用，完成后移除键，此后的调用重新执行
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("合并调用执行时发生panic: %v", r)
+		}
+		g.mu.Lock()
+		g.forgetLocked(key, c)
+		g.mu.Unlock()
+		c.cancel()
+		close(c.done)
+	}()
+
+	c.value, c.err = fn(ctx)
+}
+
+// wait 等待调用完成或ctx取消，最后一个调用者放弃时取消执行中的调用
+func (g *Group[T]) wait(ctx context.Context, key string, c *call[T], follower bool) (T, bool, error) {
+	select {
+	case <-c.done:
+		return c.value, follower || c.dups > 0, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			g.forgetLocked(key, c)
+			c.cancel()
+		}
+		g.mu.Unlock()
+
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
+
+// forgetLocked 移除键对应的调用，键已被新的调用占用时不处理
+func (g *Group[T]) forgetLocked(key string, c *call[T]) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
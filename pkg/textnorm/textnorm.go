@@ -0,0 +1,246 @@
+// Package textnorm 歌曲标题、艺术家等文本的归一化与相似度计算
+//
+// 归一化包括全角/半角折叠、大小写折叠、标点归并为空格，
+// 并可剥离 "(Live)"、"[Remix]"、"feat. xxx" 等括号后缀与合作者信息。
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// 括号对，括号内的内容视为版本、合作者等附加信息
+var brackets = map[rune]rune{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+	'（': '）',
+	'【': '】',
+	'「': '」',
+	'『': '』',
+	'〔': '〕',
+}
+
+// 合作者标记，出现在标题末尾时其后的内容视为附加信息
+var featMarkers = []string{" feat. ", " feat ", " ft. ", " ft ", " featuring "}
+
+// 标识特殊版本的词，命中时说明该结果不是原版
+var versionWords = []string{
+	"live", "remix", "mix", "cover", "instrumental", "inst", "karaoke", "acoustic",
+	"demo", "dj版", "伴奏", "纯音乐", "翻唱", "现场", "演唱会", "试听", "铃声",
+}
+
+// Fold 全角转半角并折叠大小写
+func Fold(s string) string {
+	return strings.ToLower(width.Fold.String(s))
+}
+
+// Normalize 归一化文本：全角转半角、折叠大小写、标点与符号归并为单个空格
+func Normalize(s string) string {
+	s = Fold(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	space := true
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		// 撇号不拆分单词，如 "don't"
+		if r == '\'' || r == '’' {
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// StripDecorations 剥离括号后缀与合作者信息，返回主体文本与剥离出的附加信息
+//
+//	"Shape of You (Live) [feat. X]" -> "Shape of You", ["Live", "feat. X"]
+func StripDecorations(s string) (string, []string) {
+	s = width.Fold.String(s)
+
+	var (
+		base   strings.Builder
+		extras []string
+		stack  []rune
+		inner  strings.Builder
+	)
+	for _, r := range s {
+		if closing, ok := brackets[r]; ok {
+			if len(stack) == 0 {
+				inner.Reset()
+			} else {
+				inner.WriteRune(r)
+			}
+			stack = append(stack, closing)
+			continue
+		}
+		if len(stack) > 0 {
+			if r == stack[len(stack)-1] {
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					if extra := strings.TrimSpace(inner.String()); extra != "" {
+						extras = append(extras, extra)
+					}
+					continue
+				}
+			}
+			inner.WriteRune(r)
+			continue
+		}
+		base.WriteRune(r)
+	}
+	// 未闭合的括号按普通文本处理
+	if len(stack) > 0 {
+		base.WriteString(inner.String())
+	}
+
+	result := base.String()
+	lower := strings.ToLower(result)
+	for _, marker := range featMarkers {
+		if i := strings.Index(lower, marker); i > 0 {
+			extras = append(extras, strings.TrimSpace(result[i:]))
+			result = result[:i]
+			lower = lower[:i]
+		}
+	}
+
+	// " - Live" 形式的版本后缀
+	if i := strings.LastIndex(result, " - "); i > 0 && IsVersionTag(result[i+3:]) {
+		extras = append(extras, strings.TrimSpace(result[i+3:]))
+		result = result[:i]
+	}
+
+	return strings.TrimSpace(result), extras
+}
+
+// Key 生成用于比较与去重的键：剥离附加信息后归一化
+func Key(s string) string {
+	base, _ := StripDecorations(s)
+	if key := Normalize(base); key != "" {
+		return key
+	}
+	// 整个标题都在括号内时退回到完整文本
+	return Normalize(s)
+}
+
+// IsVersionTag 检查附加信息是否标识特殊版本（现场、混音、伴奏等）
+func IsVersionTag(s string) bool {
+	normalized := Normalize(s)
+	words := strings.Fields(normalized)
+	for _, version := range versionWords {
+		// 中文版本词不以空格分隔，按子串匹配
+		if containsHan(version) {
+			if strings.Contains(normalized, version) {
+				return true
+			}
+			continue
+		}
+		for _, word := range words {
+			if word == version {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SplitArtists 拆分多位艺术家，返回归一化后的名称
+func SplitArtists(s string) []string {
+	parts := strings.FieldsFunc(width.Fold.String(s), func(r rune) bool {
+		switch r {
+		case ',', '、', ';', '&', '/', '，', '；':
+			return true
+		}
+		return false
+	})
+
+	artists := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if name := Normalize(part); name != "" {
+			artists = append(artists, name)
+		}
+	}
+	// "AC/DC" 这类名称整体也作为候选
+	if whole := Normalize(s); len(artists) > 1 && whole != "" {
+		artists = append(artists, whole)
+	}
+	return artists
+}
+
+// Similarity 计算两个归一化文本的相似度（0-1），基于字符二元组的Dice系数
+func Similarity(a, b string) float64 {
+	a = strings.ReplaceAll(a, " ", "")
+	b = strings.ReplaceAll(b, " ", "")
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 1 || len(rb) == 1 {
+		if strings.Contains(a, b) || strings.Contains(b, a) {
+			return 2.0 / float64(len(ra)+len(rb))
+		}
+		return 0
+	}
+
+	counts := make(map[[2]rune]int, len(ra))
+	for i := 0; i < len(ra)-1; i++ {
+		counts[[2]rune{ra[i], ra[i+1]}]++
+	}
+	matches := 0
+	for i := 0; i < len(rb)-1; i++ {
+		key := [2]rune{rb[i], rb[i+1]}
+		if counts[key] > 0 {
+			counts[key]--
+			matches++
+		}
+	}
+	return 2 * float64(matches) / float64(len(ra)+len(rb)-2)
+}
+
+// ContainsPhrase 检查归一化文本中是否包含完整的词组（按空格边界匹配，中文按字符匹配）
+func ContainsPhrase(text, phrase string) bool {
+	if phrase == "" {
+		return false
+	}
+	if !containsHan(phrase) {
+		return strings.Contains(" "+text+" ", " "+phrase+" ")
+	}
+	return strings.Contains(text, phrase)
+}
+
+// RemovePhrase 从归一化文本中移除词组并整理空格
+func RemovePhrase(text, phrase string) string {
+	if !ContainsPhrase(text, phrase) {
+		return text
+	}
+	if !containsHan(phrase) {
+		text = strings.Replace(" "+text+" ", " "+phrase+" ", " ", 1)
+	} else {
+		text = strings.Replace(text, phrase, " ", 1)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// containsHan 检查是否包含汉字、假名等不以空格分词的字符
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}